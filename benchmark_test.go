@@ -3,6 +3,7 @@ package statsig
 import (
 	"fmt"
 	"os"
+	"regexp"
 	"testing"
 	"time"
 )
@@ -45,8 +46,61 @@ func TestBenchmarkUAParser(t *testing.T) {
 	}
 }
 
+func BenchmarkLogEventConcurrent(b *testing.B) {
+	testServer := getTestServer(testServerOptions{})
+	defer testServer.Close()
+	c := NewClientWithOptions("secret-key", &Options{
+		API:                  testServer.URL,
+		LoggingMaxBufferSize: 100,
+	})
+	defer c.Shutdown()
+	event := Event{EventName: "benchmark_event", User: User{UserID: "123"}}
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			c.LogEvent(event)
+		}
+	})
+}
+
 func measureDuration(f func()) time.Duration {
 	start := time.Now()
 	f()
 	return time.Since(start)
 }
+
+func BenchmarkGetLayer(b *testing.B) {
+	specs, _ := os.ReadFile("download_config_specs.json")
+	c := NewClientWithOptions("secret-key", &Options{
+		LocalMode:       true,
+		BootstrapValues: string(specs),
+	})
+	defer c.Shutdown()
+	user := User{UserID: "123"}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.GetLayer(user, "a_layer")
+	}
+}
+
+func BenchmarkRegexCondition(b *testing.B) {
+	cond := configCondition{Type: "user_field", Operator: "str_matches", TargetValue: `^[a-z]+@statsig\.(com|io)$`}
+	value := "tore@statsig.com"
+
+	b.Run("uncached", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, _ = regexp.MatchString(castToString(cond.TargetValue), value)
+		}
+	})
+	b.Run("cached", func(b *testing.B) {
+		e := &evaluator{}
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			e.evalOperator(cond, value)
+		}
+	})
+}
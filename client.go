@@ -1,8 +1,10 @@
 package statsig
 
 import (
+	"context"
 	"errors"
 	"net/http"
+	"sort"
 	"strings"
 	"time"
 )
@@ -16,6 +18,7 @@ type Client struct {
 	errorBoundary *errorBoundary
 	options       *Options
 	diagnostics   *diagnostics
+	isSubClient   bool
 }
 
 // Initializes a Statsig Client with the given sdkKey
@@ -33,16 +36,36 @@ func NewClientWithOptions(sdkKey string, options *Options) *Client {
 // returning the initialized client and details of initialization
 func NewClientWithDetails(sdkKey string, options *Options) (*Client, InitializeDetails) {
 	client, context := newClientImpl(sdkKey, options)
-	return client, InitializeDetails{
+	return client, client.getInitializeDetails(context)
+}
+
+func (c *Client) getInitializeDetails(context *initContext) InitializeDetails {
+	return InitializeDetails{
 		Duration: time.Since(context.Start),
 		Success:  context.Success,
 		Error:    context.Error,
 		Source:   context.Source,
+		Markers:  c.diagnostics.initDiagnostics.getMarkers(),
 	}
 }
 
-func newClientImpl(sdkKey string, options *Options) (*Client, *initContext) {
+// Initializes a Statsig Client with the given sdkKey and options in the background, returning the client immediately.
+// The client serves default values until initialization completes, at which point the returned channel receives
+// the details of initialization
+func NewClientAsync(sdkKey string, options *Options) (*Client, <-chan InitializeDetails) {
 	context := newInitContext()
+	client := newUninitializedClient(sdkKey, options)
+	channel := make(chan InitializeDetails, 1)
+	go func() {
+		client.init(context)
+		client.diagnostics.initialize().overall().end().success(true).mark()
+		channel <- client.getInitializeDetails(context)
+		close(channel)
+	}()
+	return client, channel
+}
+
+func newUninitializedClient(sdkKey string, options *Options) *Client {
 	diagnostics := newDiagnostics(options)
 	diagnostics.initialize().overall().start().mark()
 	errorBoundary := newErrorBoundary(sdkKey, options, diagnostics)
@@ -53,7 +76,7 @@ func newClientImpl(sdkKey string, options *Options) (*Client, *initContext) {
 	transport := newTransport(sdkKey, options)
 	logger := newLogger(transport, options, diagnostics, errorBoundary)
 	evaluator := newEvaluator(transport, errorBoundary, options, diagnostics, sdkKey)
-	client := &Client{
+	return &Client{
 		sdkKey:        sdkKey,
 		evaluator:     evaluator,
 		logger:        logger,
@@ -62,6 +85,12 @@ func newClientImpl(sdkKey string, options *Options) (*Client, *initContext) {
 		options:       options,
 		diagnostics:   diagnostics,
 	}
+}
+
+func newClientImpl(sdkKey string, options *Options) (*Client, *initContext) {
+	context := newInitContext()
+	client := newUninitializedClient(sdkKey, options)
+	diagnostics := client.diagnostics
 
 	if options.InitTimeout > 0 {
 		channel := make(chan *Client, 1)
@@ -106,28 +135,62 @@ func (c *Client) initInBackground() {
 func (c *Client) CheckGate(user User, gate string) bool {
 	return c.errorBoundary.captureCheckGate(func(context *evalContext) FeatureGate {
 		return c.checkGateImpl(user, gate, context)
-	}, &evalContext{Caller: "checkGate", ConfigName: gate}).Value
+	}, &evalContext{Caller: "checkGate", ConfigName: gate, recordRateLimits: true}).Value
 }
 
 // Checks the value of a Feature Gate for the given user without logging an exposure event
 func (c *Client) CheckGateWithExposureLoggingDisabled(user User, gate string) bool {
 	return c.errorBoundary.captureCheckGate(func(context *evalContext) FeatureGate {
 		return c.checkGateImpl(user, gate, context)
-	}, &evalContext{Caller: "checkGateWithExposureLoggingDisabled", ConfigName: gate, DisableLogExposures: true}).Value
+	}, &evalContext{Caller: "checkGateWithExposureLoggingDisabled", ConfigName: gate, DisableLogExposures: true, recordRateLimits: true}).Value
+}
+
+// Checks the value of a Feature Gate for the given user with configurable options
+func (c *Client) CheckGateWithOptions(user User, gate string, options *GetGateOptions) bool {
+	return c.GetGateWithOptions(user, gate, options).Value
+}
+
+// Checks the value of a Feature Gate for the user derived from the given context, merged with the given user.
+// Returns false if ctx is done before the evaluation completes
+func (c *Client) CheckGateWithContext(ctx context.Context, user User, gate string) bool {
+	return c.errorBoundary.captureCheckGate(func(context *evalContext) FeatureGate {
+		return c.checkGateImpl(c.getUserFromContext(ctx, user), gate, context)
+	}, &evalContext{Caller: "checkGateWithContext", ConfigName: gate, ctx: ctx, recordRateLimits: true}).Value
 }
 
 // Get the Feature Gate for the given user
 func (c *Client) GetGate(user User, gate string) FeatureGate {
 	return c.errorBoundary.captureCheckGate(func(context *evalContext) FeatureGate {
 		return c.checkGateImpl(user, gate, context)
-	}, &evalContext{Caller: "getGate", ConfigName: gate})
+	}, &evalContext{Caller: "getGate", ConfigName: gate, recordRateLimits: true})
+}
+
+// Get the Feature Gate for the given user with configurable options
+func (c *Client) GetGateWithOptions(user User, gate string, options *GetGateOptions) FeatureGate {
+	return c.errorBoundary.captureCheckGate(func(context *evalContext) FeatureGate {
+		return c.checkGateImpl(user, gate, context)
+	}, &evalContext{
+		Caller:              "getGateWithOptions",
+		ConfigName:          gate,
+		DisableLogExposures: options.DisableLogExposures,
+		EvaluationTime:      options.EvaluationTime,
+		recordRateLimits:    true,
+	})
+}
+
+// Get the Feature Gate for the user derived from the given context, merged with the given user.
+// If ctx is done before the evaluation completes the default value is returned with ReasonCanceled
+func (c *Client) GetGateWithContext(ctx context.Context, user User, gate string) FeatureGate {
+	return c.errorBoundary.captureCheckGate(func(context *evalContext) FeatureGate {
+		return c.checkGateImpl(c.getUserFromContext(ctx, user), gate, context)
+	}, &evalContext{Caller: "getGateWithContext", ConfigName: gate, ctx: ctx, recordRateLimits: true})
 }
 
 // Checks the value of a Feature Gate for the given user without logging an exposure event
 func (c *Client) GetGateWithExposureLoggingDisabled(user User, gate string) FeatureGate {
 	return c.errorBoundary.captureCheckGate(func(context *evalContext) FeatureGate {
 		return c.checkGateImpl(user, gate, context)
-	}, &evalContext{Caller: "getGateWithExposureLoggingDisabled", ConfigName: gate, DisableLogExposures: true})
+	}, &evalContext{Caller: "getGateWithExposureLoggingDisabled", ConfigName: gate, DisableLogExposures: true, recordRateLimits: true})
 }
 
 // Logs an exposure event for the dynamic config
@@ -137,6 +200,7 @@ func (c *Client) ManuallyLogGateExposure(user User, gate string) {
 			return
 		}
 		user = normalizeUser(user, *c.options)
+		gate = c.evaluator.store.getGateName(gate)
 		res := c.evaluator.evalGate(user, gate, context)
 		c.logger.logGateExposure(user, gate, res, context)
 	}, &evalContext{Caller: "logGateExposure", ConfigName: gate, IsManualExposure: true})
@@ -146,14 +210,22 @@ func (c *Client) ManuallyLogGateExposure(user User, gate string) {
 func (c *Client) GetConfig(user User, config string) DynamicConfig {
 	return c.errorBoundary.captureGetConfig(func(context *evalContext) DynamicConfig {
 		return c.getConfigImpl(user, config, context)
-	}, &evalContext{Caller: "getConfig", ConfigName: config})
+	}, &evalContext{Caller: "getConfig", ConfigName: config, recordRateLimits: true})
 }
 
 // Gets the DynamicConfig value for the given user without logging an exposure event
 func (c *Client) GetConfigWithExposureLoggingDisabled(user User, config string) DynamicConfig {
 	return c.errorBoundary.captureGetConfig(func(context *evalContext) DynamicConfig {
 		return c.getConfigImpl(user, config, context)
-	}, &evalContext{Caller: "getConfigWithExposureLoggingDisabled", ConfigName: config, DisableLogExposures: true})
+	}, &evalContext{Caller: "getConfigWithExposureLoggingDisabled", ConfigName: config, DisableLogExposures: true, recordRateLimits: true})
+}
+
+// Gets the DynamicConfig value for the user derived from the given context, merged with the given user.
+// If ctx is done before the evaluation completes the default value is returned with ReasonCanceled
+func (c *Client) GetConfigWithContext(ctx context.Context, user User, config string) DynamicConfig {
+	return c.errorBoundary.captureGetConfig(func(context *evalContext) DynamicConfig {
+		return c.getConfigImpl(c.getUserFromContext(ctx, user), config, context)
+	}, &evalContext{Caller: "getConfigWithContext", ConfigName: config, ctx: ctx, recordRateLimits: true})
 }
 
 // Logs an exposure event for the config
@@ -163,6 +235,7 @@ func (c *Client) ManuallyLogConfigExposure(user User, config string) {
 			return
 		}
 		user = normalizeUser(user, *c.options)
+		config = c.evaluator.store.getDynamicConfigName(config)
 		res := c.evaluator.evalConfig(user, config, context)
 		c.logger.logConfigExposure(user, config, res, context)
 	}, &evalContext{Caller: "logConfigExposure", ConfigName: config, IsManualExposure: true})
@@ -175,18 +248,37 @@ func (c *Client) GetExperimentLayer(experiment string) (string, bool) {
 	}, &evalContext{Caller: "getExperimentLayer", ConfigName: experiment})
 }
 
+// Gets the layer name of an Experiment, telling an experiment that is not in any layer apart from an unknown one
+func (c *Client) GetExperimentLayerInfo(experiment string) ExperimentLayerInfo {
+	return c.errorBoundary.captureGetExperimentLayerInfo(func(context *evalContext) ExperimentLayerInfo {
+		return c.evaluator.store.getExperimentLayerInfo(experiment)
+	}, &evalContext{Caller: "getExperimentLayerInfo", ConfigName: experiment})
+}
+
+// Gets the experiment the user is allocated to in a layer, and whether they are allocated to one, without logging
+// an exposure. Meant for joining experiment assignments in analytics
+func (c *Client) GetLayerAllocatedExperiment(user User, layer string) (string, bool) {
+	return c.errorBoundary.captureGetExperimentLayer(func(context *evalContext) (string, bool) {
+		if !c.verifyUser(user) {
+			return "", false
+		}
+		res := c.evaluator.evalLayer(normalizeUser(user, *c.options), layer, context)
+		return res.ConfigDelegate, res.ConfigDelegate != ""
+	}, &evalContext{Caller: "getLayerAllocatedExperiment", ConfigName: layer, DisableLogExposures: true})
+}
+
 // Gets the DynamicConfig value of an Experiment for the given user
 func (c *Client) GetExperiment(user User, experiment string) DynamicConfig {
 	return c.errorBoundary.captureGetConfig(func(context *evalContext) DynamicConfig {
 		return c.getConfigImpl(user, experiment, context)
-	}, &evalContext{Caller: "getExperiment", ConfigName: experiment, IsExperiment: true})
+	}, &evalContext{Caller: "getExperiment", ConfigName: experiment, IsExperiment: true, recordRateLimits: true})
 }
 
 // Gets the DynamicConfig value of an Experiment for the given user without logging an exposure event
 func (c *Client) GetExperimentWithExposureLoggingDisabled(user User, experiment string) DynamicConfig {
 	return c.errorBoundary.captureGetConfig(func(context *evalContext) DynamicConfig {
 		return c.getConfigImpl(user, experiment, context)
-	}, &evalContext{Caller: "getExperimentWithExposureLoggingDisabled", ConfigName: experiment, IsExperiment: true, DisableLogExposures: true})
+	}, &evalContext{Caller: "getExperimentWithExposureLoggingDisabled", ConfigName: experiment, IsExperiment: true, DisableLogExposures: true, recordRateLimits: true})
 }
 
 // Gets the DynamicConfig value of an Experiment for the given user with configurable options
@@ -199,17 +291,123 @@ func (c *Client) GetExperimentWithOptions(user User, experiment string, options
 		IsExperiment:        true,
 		DisableLogExposures: options.DisableLogExposures,
 		PersistedValues:     options.PersistedValues,
+		EvaluationTime:      options.EvaluationTime,
+		recordRateLimits:    true,
 	})
 }
 
+// Gets the DynamicConfig value of an Experiment for the user derived from the given context, merged with the given user.
+// If ctx is done before the evaluation completes the default value is returned with ReasonCanceled
+func (c *Client) GetExperimentWithContext(ctx context.Context, user User, experiment string) DynamicConfig {
+	return c.errorBoundary.captureGetConfig(func(context *evalContext) DynamicConfig {
+		return c.getConfigImpl(c.getUserFromContext(ctx, user), experiment, context)
+	}, &evalContext{Caller: "getExperimentWithContext", ConfigName: experiment, IsExperiment: true, ctx: ctx, recordRateLimits: true})
+}
+
 // Logs an exposure event for the experiment
 func (c *Client) ManuallyLogExperimentExposure(user User, experiment string) {
-	c.ManuallyLogConfigExposure(user, experiment)
+	c.errorBoundary.captureVoid(func(context *evalContext) {
+		if !c.verifyUser(user) {
+			return
+		}
+		user = normalizeUser(user, *c.options)
+		experiment = c.evaluator.store.getDynamicConfigName(experiment)
+		res := c.evaluator.evalConfig(user, experiment, context)
+		c.logger.logConfigExposure(user, experiment, res, context)
+	}, &evalContext{Caller: "logExperimentExposure", ConfigName: experiment, IsManualExposure: true, IsExperiment: true})
+}
+
+// Reports rules in the loaded specs that reference gates, segments or configs which do not exist.
+// Purely structural: no user is evaluated and nothing is logged
+func (c *Client) ValidateSpecs() []SpecWarning {
+	return c.errorBoundary.captureValidateSpecs(func(context *evalContext) []SpecWarning {
+		return c.evaluator.store.validateSpecs()
+	}, &evalContext{Caller: "validateSpecs"})
+}
+
+// Gets a read-only summary of the rules of a loaded gate, config, experiment or layer.
+// Returns nil if no spec with the given name is loaded
+func (c *Client) GetConfigSpecSummary(name string) *SpecSummary {
+	return c.errorBoundary.captureGetConfigSpecSummary(func(context *evalContext) *SpecSummary {
+		return c.evaluator.store.getSpecSummary(name)
+	}, &evalContext{Caller: "getConfigSpecSummary", ConfigName: name})
+}
+
+// Gets every gate and config the given gate depends on, walking the loaded specs without evaluating a user.
+// Cycles are marked rather than expanded
+func (c *Client) GateDependencyTree(gate string) DependencyTree {
+	return c.errorBoundary.captureGateDependencyTree(func(context *evalContext) DependencyTree {
+		return c.evaluator.store.getGateDependencyTree(gate)
+	}, &evalContext{Caller: "gateDependencyTree", ConfigName: gate})
+}
+
+// Gets the names of the ID lists a gate's segment conditions read, including through the gates it depends on.
+// Reads the loaded specs without evaluating a user, so the lists can be checked for readiness ahead of evaluation
+func (c *Client) GateIDListDependencies(gate string) []string {
+	return c.errorBoundary.captureGateIDListDependencies(func(context *evalContext) []string {
+		return c.evaluator.store.getGateIDListDependencies(gate)
+	}, &evalContext{Caller: "gateIDListDependencies", ConfigName: gate})
+}
+
+// Gets the hashed members of every loaded ID list, keyed by list name and sorted, for debugging what segments contain.
+// Copies every ID while holding each list's lock, so with large lists this is slow, allocates heavily and delays
+// ID list syncs. Avoid calling it on a hot path
+func (c *Client) ExportIDLists() map[string][]string {
+	return c.errorBoundary.captureExportIDLists(func(context *evalContext) map[string][]string {
+		return c.evaluator.store.exportIDLists()
+	}, &evalContext{Caller: "exportIDLists"})
+}
+
+// Explains why a gate did not pass for the given user, one readable reason per failed rule.
+// Returns an empty slice when the gate passes. No exposures are logged
+func (c *Client) WhyNot(user User, gate string) []string {
+	return c.errorBoundary.captureWhyNot(func(context *evalContext) []string {
+		if !c.verifyUser(user) {
+			return []string{"user has neither a UserID nor CustomIDs"}
+		}
+		user = normalizeUser(user, *c.options)
+		if override, hasOverride := c.evaluator.getGateOverrideEval(user, gate); hasOverride {
+			if override.Value {
+				return []string{}
+			}
+			return []string{"gate is locally overridden to false"}
+		}
+		if _, hasGate := c.evaluator.store.getGate(gate); !hasGate {
+			return []string{"gate '" + gate + "' not found"}
+		}
+		res := c.evaluator.evalGate(user, gate, context)
+		switch {
+		case res.Value:
+			return []string{}
+		case res.FetchFromServer:
+			return []string{"gate uses a condition this SDK version cannot evaluate"}
+		case len(res.FailureReasons) == 0:
+			return []string{"no rule passed"}
+		}
+		return res.FailureReasons
+	}, &evalContext{Caller: "whyNot", ConfigName: gate, DisableLogExposures: true, CaptureConditionFailures: true})
+}
+
+// Checks a gate for two users, e.g. a user and a copy of them with a changed attribute, to show whether the
+// change flips targeting. Returns the value for userA, then for userB. No exposures are logged
+func (c *Client) CompareUsers(userA User, userB User, gate string) (bool, bool) {
+	check := func(user User) bool {
+		return c.errorBoundary.captureCheckGate(func(context *evalContext) FeatureGate {
+			return c.checkGateImpl(user, gate, context)
+		}, &evalContext{Caller: "compareUsers", ConfigName: gate, DisableLogExposures: true}).Value
+	}
+	return check(userA), check(userB)
 }
 
 func (c *Client) GetUserPersistedValues(user User, idType string) UserPersistedValues {
+	return c.GetUserPersistedValuesWithContext(context.Background(), user, idType)
+}
+
+// Loads the user's persisted values like GetUserPersistedValues. If ctx is done first, storage is not called
+// and the values are empty
+func (c *Client) GetUserPersistedValuesWithContext(ctx context.Context, user User, idType string) UserPersistedValues {
 	return c.errorBoundary.captureGetUserPersistedValues(func(context *errorContext) UserPersistedValues {
-		persistedValues := c.evaluator.persistentStorageUtils.load(user, idType)
+		persistedValues := c.evaluator.persistentStorageUtils.load(ctx, user, idType)
 		if persistedValues == nil {
 			return make(UserPersistedValues)
 		} else {
@@ -222,14 +420,33 @@ func (c *Client) GetUserPersistedValues(user User, idType string) UserPersistedV
 func (c *Client) GetLayer(user User, layer string) Layer {
 	return c.errorBoundary.captureGetLayer(func(context *evalContext) Layer {
 		return c.getLayerImpl(user, layer, context)
-	}, &evalContext{Caller: "getLayer", ConfigName: layer})
+	}, &evalContext{Caller: "getLayer", ConfigName: layer, recordRateLimits: true})
+}
+
+// Gets the Layer objects for the given user, normalizing the user once. Layers that do not exist are returned
+// with an Unrecognized reason. Each Layer logs its own parameter exposures
+func (c *Client) GetLayers(user User, layers []string) map[string]Layer {
+	result := make(map[string]Layer, len(layers))
+	if !c.verifyUser(user) {
+		for _, layer := range layers {
+			result[layer] = *NewLayer(layer, nil, "", "", nil, "")
+		}
+		return result
+	}
+	user = normalizeUser(user, *c.options)
+	for _, layer := range layers {
+		result[layer] = c.errorBoundary.captureGetLayer(func(context *evalContext) Layer {
+			return c.getLayerForNormalizedUser(user, layer, context)
+		}, &evalContext{Caller: "getLayers", ConfigName: layer, recordRateLimits: true})
+	}
+	return result
 }
 
 // Gets the Layer object for the given user without logging an exposure event
 func (c *Client) GetLayerWithExposureLoggingDisabled(user User, layer string) Layer {
 	return c.errorBoundary.captureGetLayer(func(context *evalContext) Layer {
 		return c.getLayerImpl(user, layer, context)
-	}, &evalContext{Caller: "getLayerWithExposureLoggingDisabled", ConfigName: layer, DisableLogExposures: true})
+	}, &evalContext{Caller: "getLayerWithExposureLoggingDisabled", ConfigName: layer, DisableLogExposures: true, recordRateLimits: true})
 }
 
 // Gets the Layer object for the given user with configurable options
@@ -241,9 +458,19 @@ func (c *Client) GetLayerWithOptions(user User, layer string, options *GetLayerO
 		ConfigName:          layer,
 		DisableLogExposures: options.DisableLogExposures,
 		PersistedValues:     options.PersistedValues,
+		EvaluationTime:      options.EvaluationTime,
+		recordRateLimits:    true,
 	})
 }
 
+// Gets the Layer object for the user derived from the given context, merged with the given user.
+// If ctx is done before the evaluation completes the default value is returned with ReasonCanceled
+func (c *Client) GetLayerWithContext(ctx context.Context, user User, layer string) Layer {
+	return c.errorBoundary.captureGetLayer(func(context *evalContext) Layer {
+		return c.getLayerImpl(c.getUserFromContext(ctx, user), layer, context)
+	}, &evalContext{Caller: "getLayerWithContext", ConfigName: layer, ctx: ctx, recordRateLimits: true})
+}
+
 // Logs an exposure event for the parameter in the given layer
 func (c *Client) ManuallyLogLayerParameterExposure(user User, layer string, parameter string) {
 	c.errorBoundary.captureVoid(func(context *evalContext) {
@@ -251,12 +478,48 @@ func (c *Client) ManuallyLogLayerParameterExposure(user User, layer string, para
 			return
 		}
 		user = normalizeUser(user, *c.options)
+		layer = c.evaluator.store.getLayerConfigName(layer)
 		res := c.evaluator.evalLayer(user, layer, context)
 		config := NewLayer(layer, res.JsonValue, res.RuleID, res.GroupName, nil, res.ConfigDelegate)
 		c.logger.logLayerExposure(user, *config, parameter, res, context)
 	}, &evalContext{Caller: "logLayerParameterExposure", ConfigName: layer, IsManualExposure: true})
 }
 
+// Logs a manual exposure for each entry, normalizing the user once. Identical entries are logged once and
+// entries of any other type are skipped
+func (c *Client) ManuallyLogExposures(user User, entries []ManualExposureEntry) {
+	c.errorBoundary.captureVoid(func(context *evalContext) {
+		if !c.verifyUser(user) {
+			return
+		}
+		user = normalizeUser(user, *c.options)
+		logged := make(map[ManualExposureEntry]bool, len(entries))
+		for _, entry := range entries {
+			if logged[entry] {
+				continue
+			}
+			logged[entry] = true
+			entryContext := &evalContext{Caller: context.Caller, ConfigName: entry.Name, IsManualExposure: true}
+			switch entry.Type {
+			case FeatureGateType:
+				entry.Name = c.evaluator.store.getGateName(entry.Name)
+				res := c.evaluator.evalGate(user, entry.Name, entryContext)
+				c.logger.logGateExposure(user, entry.Name, res, entryContext)
+			case DynamicConfigType, ExperimentType:
+				entryContext.IsExperiment = entry.Type == ExperimentType
+				entry.Name = c.evaluator.store.getDynamicConfigName(entry.Name)
+				res := c.evaluator.evalConfig(user, entry.Name, entryContext)
+				c.logger.logConfigExposure(user, entry.Name, res, entryContext)
+			case LayerType:
+				entry.Name = c.evaluator.store.getLayerConfigName(entry.Name)
+				res := c.evaluator.evalLayer(user, entry.Name, entryContext)
+				config := NewLayer(entry.Name, res.JsonValue, res.RuleID, res.GroupName, nil, res.ConfigDelegate)
+				c.logger.logLayerExposure(user, *config, entry.Parameter, res, entryContext)
+			}
+		}
+	}, &evalContext{Caller: "manuallyLogExposures", IsManualExposure: true})
+}
+
 // Logs an event to Statsig for analysis in the Statsig Console
 func (c *Client) LogEvent(event Event) {
 	c.errorBoundary.captureVoid(func(context *evalContext) {
@@ -271,6 +534,9 @@ func (c *Client) LogEvent(event Event) {
 // Override the value of a Feature Gate for the given user
 func (c *Client) OverrideGate(gate string, val bool) {
 	c.errorBoundary.captureVoid(func(context *evalContext) {
+		if c.rejectInReadOnly() {
+			return
+		}
 		c.evaluator.OverrideGate(gate, val)
 	}, &evalContext{Caller: "overrideGate", ConfigName: gate})
 }
@@ -278,6 +544,9 @@ func (c *Client) OverrideGate(gate string, val bool) {
 // Override the DynamicConfig value for the given user
 func (c *Client) OverrideConfig(config string, val map[string]interface{}) {
 	c.errorBoundary.captureVoid(func(context *evalContext) {
+		if c.rejectInReadOnly() {
+			return
+		}
 		c.evaluator.OverrideConfig(config, val)
 	}, &evalContext{Caller: "overrideConfig", ConfigName: config})
 }
@@ -285,11 +554,119 @@ func (c *Client) OverrideConfig(config string, val map[string]interface{}) {
 // Override the Layer value for the given user
 func (c *Client) OverrideLayer(layer string, val map[string]interface{}) {
 	c.errorBoundary.captureVoid(func(context *evalContext) {
+		if c.rejectInReadOnly() {
+			return
+		}
 		c.evaluator.OverrideLayer(layer, val)
 	}, &evalContext{Caller: "overrideLayer", ConfigName: layer})
 }
 
+// Override the value of a Feature Gate for the user with the given ID only. Takes precedence over OverrideGate
+func (c *Client) OverrideGateForUser(userID string, gate string, val bool) {
+	c.errorBoundary.captureVoid(func(context *evalContext) {
+		if c.rejectInReadOnly() {
+			return
+		}
+		c.evaluator.OverrideGateForUser(userID, gate, val)
+	}, &evalContext{Caller: "overrideGateForUser", ConfigName: gate})
+}
+
+// Override the DynamicConfig value for the user with the given ID only. Takes precedence over OverrideConfig
+func (c *Client) OverrideConfigForUser(userID string, config string, val map[string]interface{}) {
+	c.errorBoundary.captureVoid(func(context *evalContext) {
+		if c.rejectInReadOnly() {
+			return
+		}
+		c.evaluator.OverrideConfigForUser(userID, config, val)
+	}, &evalContext{Caller: "overrideConfigForUser", ConfigName: config})
+}
+
+// Override the Layer value for the user with the given ID only. Takes precedence over OverrideLayer
+func (c *Client) OverrideLayerForUser(userID string, layer string, val map[string]interface{}) {
+	c.errorBoundary.captureVoid(func(context *evalContext) {
+		if c.rejectInReadOnly() {
+			return
+		}
+		c.evaluator.OverrideLayerForUser(userID, layer, val)
+	}, &evalContext{Caller: "overrideLayerForUser", ConfigName: layer})
+}
+
+// Removes every override set for the user with the given ID by OverrideGateForUser, OverrideConfigForUser and OverrideLayerForUser
+func (c *Client) RemoveUserOverrides(userID string) {
+	c.errorBoundary.captureVoid(func(context *evalContext) {
+		if c.rejectInReadOnly() {
+			return
+		}
+		c.evaluator.RemoveUserOverrides(userID)
+	}, &evalContext{Caller: "removeUserOverrides"})
+}
+
+// Removes the override set by OverrideGate. Overrides set for single users are kept
+func (c *Client) RemoveGateOverride(gate string) {
+	c.errorBoundary.captureVoid(func(context *evalContext) {
+		if c.rejectInReadOnly() {
+			return
+		}
+		c.evaluator.RemoveGateOverride(gate)
+	}, &evalContext{Caller: "removeGateOverride", ConfigName: gate})
+}
+
+// Removes the override set by OverrideConfig. Overrides set for single users are kept
+func (c *Client) RemoveConfigOverride(config string) {
+	c.errorBoundary.captureVoid(func(context *evalContext) {
+		if c.rejectInReadOnly() {
+			return
+		}
+		c.evaluator.RemoveConfigOverride(config)
+	}, &evalContext{Caller: "removeConfigOverride", ConfigName: config})
+}
+
+// Removes the override set by OverrideLayer. Overrides set for single users are kept
+func (c *Client) RemoveLayerOverride(layer string) {
+	c.errorBoundary.captureVoid(func(context *evalContext) {
+		if c.rejectInReadOnly() {
+			return
+		}
+		c.evaluator.RemoveLayerOverride(layer)
+	}, &evalContext{Caller: "removeLayerOverride", ConfigName: layer})
+}
+
+// Removes every gate, config and layer override, including those set for single users
+func (c *Client) RemoveAllOverrides() {
+	c.errorBoundary.captureVoid(func(context *evalContext) {
+		if c.rejectInReadOnly() {
+			return
+		}
+		c.evaluator.RemoveAllOverrides()
+	}, &evalContext{Caller: "removeAllOverrides"})
+}
+
+// Gets a snapshot of the overrides currently set. Overrides from Options.OverrideProvider are not included
+func (c *Client) GetAllOverrides() Overrides {
+	var overrides Overrides
+	c.errorBoundary.captureVoid(func(context *evalContext) {
+		overrides = c.evaluator.GetAllOverrides()
+	}, &evalContext{Caller: "getAllOverrides"})
+	return overrides
+}
+
+// Applies a set of overrides at once and returns a function that restores the overrides set before.
+// Meant for scoping overrides to a test case, e.g. defer c.WithOverrides(overrides)()
+func (c *Client) WithOverrides(overrides Overrides) func() {
+	restore := func() {}
+	c.errorBoundary.captureVoid(func(context *evalContext) {
+		if c.rejectInReadOnly() {
+			return
+		}
+		restore = c.evaluator.applyOverrides(overrides)
+	}, &evalContext{Caller: "withOverrides"})
+	return restore
+}
+
 func (c *Client) LogImmediate(events []Event) (*http.Response, error) {
+	if c.options.ReadOnly {
+		return nil, errors.New(ReadOnlyClientError)
+	}
 	if len(events) > 500 {
 		err := errors.New(EventBatchSizeError)
 		return nil, err
@@ -315,13 +692,38 @@ func (c *Client) GetClientInitializeResponseWithOptions(user User, options *GCIR
 	return c.GetClientInitializeResponseImpl(user, options)
 }
 
+// Gets the client initialize response encoded in options.Format, e.g. GCIRFormatMessagePack for constrained clients.
+// Decode it with DecodeClientInitializeResponse, or any MessagePack decoder on the client
+func (c *Client) GetClientInitializeResponseBytes(user User, options *GCIROptions) ([]byte, error) {
+	if options == nil {
+		options = &GCIROptions{}
+	}
+	response := c.GetClientInitializeResponseImpl(user, options)
+	return encodeClientInitializeResponse(response, options.Format)
+}
+
 func (c *Client) GetClientInitializeResponseImpl(user User, options *GCIROptions) ClientInitializeResponse {
+	if options == nil {
+		options = &GCIROptions{}
+	}
 	return c.errorBoundary.captureGetClientInitializeResponse(func(context *evalContext) ClientInitializeResponse {
 		if !c.verifyUser(user) {
 			return *new(ClientInitializeResponse)
 		}
 		user = normalizeUser(user, *c.options)
-		response := c.evaluator.getClientInitializeResponse(user, context)
+		var onEvaluated gcirEvaluationCallback
+		if options.LogExposures {
+			onEvaluated = func(entity string, name string, spec configSpec, res *evalResult) {
+				c.logClientInitializeResponseExposure(user, entity, name, spec, res)
+			}
+		}
+		response := c.evaluator.getClientInitializeResponse(user, context, onEvaluated)
+		if options.RedactUserInResponse {
+			redactClientInitializeResponseUser(&response)
+		}
+		if options.LegacyValueFormat {
+			addLegacyValueFormat(&response)
+		}
 		if response.Time == 0 {
 			c.errorBoundary.logExceptionWithContext(
 				errors.New("empty response from server"),
@@ -335,9 +737,79 @@ func (c *Client) GetClientInitializeResponseImpl(user User, options *GCIROptions
 		ClientKey:             options.ClientKey,
 		TargetAppID:           options.TargetAppID,
 		Hash:                  options.HashAlgorithm,
+		DedupedExposureFormat: options.DedupedExposureFormat,
 	})
 }
 
+// Logs the exposure a client SDK would have logged for a value served through a client initialize response.
+// Layers log one exposure per parameter, as if the client read each of them
+func (c *Client) logClientInitializeResponseExposure(user User, entity string, name string, spec configSpec, res *evalResult) {
+	context := &evalContext{Caller: "getClientInitializeResponse"}
+	switch entity {
+	case gcirGateEntity:
+		c.logger.logGateExposure(user, name, res, context)
+	case gcirConfigEntity:
+		context.IsExperiment = strings.EqualFold(spec.Entity, "experiment")
+		c.logger.logConfigExposure(user, name, res, context)
+	case gcirLayerEntity:
+		layer := NewLayer(name, res.JsonValue, res.RuleID, res.GroupName, nil, res.ConfigDelegate)
+		parameters := make([]string, 0, len(res.JsonValue))
+		for parameter := range res.JsonValue {
+			parameters = append(parameters, parameter)
+		}
+		sort.Strings(parameters)
+		for _, parameter := range parameters {
+			c.logger.logLayerExposure(user, *layer, parameter, res, context)
+		}
+	}
+}
+
+func (c *Client) getUserFromContext(ctx context.Context, user User) User {
+	if ctx == nil || c.options.UserFromContext == nil {
+		return user
+	}
+	return mergeUsers(c.options.UserFromContext(ctx), user)
+}
+
+// Reports the rule a top level evaluation settled on to Options.RuleEvaluationHook. Results still to be fetched from
+// the server have no local rule and are skipped. A panicking hook is logged rather than failing the evaluation
+func (c *Client) runRuleEvaluationHook(name string, res *evalResult, user User) {
+	hook := c.options.RuleEvaluationHook
+	if hook == nil || res.FetchFromServer {
+		return
+	}
+	defer func() {
+		if err := recover(); err != nil {
+			Logger().LogError(err)
+		}
+	}()
+	hook(name, res.RuleID, res.Value, user)
+}
+
+// Passes the secondary exposures of a top level evaluation to Options.SecondaryExposureRecorder, after the store
+// and evaluator locks are released. The recorder gets its own copy. Panics are logged like the evaluation hook's
+func (c *Client) recordSecondaryExposures(name string, res *evalResult) {
+	recorder := c.options.SecondaryExposureRecorder
+	if recorder == nil || res.FetchFromServer || len(res.SecondaryExposures) == 0 {
+		return
+	}
+	defer func() {
+		if err := recover(); err != nil {
+			Logger().LogError(err)
+		}
+	}()
+	exposures := make([]SecondaryExposure, len(res.SecondaryExposures))
+	copy(exposures, res.SecondaryExposures)
+	recorder(name, exposures)
+}
+
+func (c *Client) rejectInReadOnly() bool {
+	if c.options.ReadOnly {
+		Logger().LogError(ReadOnlyClientError)
+	}
+	return c.options.ReadOnly
+}
+
 func (c *Client) verifyUser(user User) bool {
 	if user.UserID == "" && len(user.CustomIDs) == 0 {
 		err := errors.New(EmptyUserError)
@@ -347,23 +819,170 @@ func (c *Client) verifyUser(user User) bool {
 	return true
 }
 
+// Updates the sync and logging behavior of a running client without dropping buffered events or re-downloading specs
+func (c *Client) UpdateRuntimeOptions(opts RuntimeOptions) error {
+	var err error
+	c.errorBoundary.captureVoid(func(context *evalContext) {
+		if opts.ConfigSyncInterval < 0 || opts.IDListSyncInterval < 0 || opts.LoggingInterval < 0 {
+			err = errors.New(InvalidRuntimeOptionsError)
+			return
+		}
+		if c.evaluator.store.isShutdown() {
+			err = errors.New(ClientShutdownError)
+			return
+		}
+		c.evaluator.store.setSyncIntervals(opts.ConfigSyncInterval, opts.IDListSyncInterval)
+		if opts.LoggingInterval > 0 {
+			c.logger.setFlushInterval(opts.LoggingInterval)
+		}
+		if opts.DisableAllLogging != nil {
+			c.logger.setDisabled(*opts.DisableAllLogging)
+		}
+	}, &evalContext{Caller: "updateRuntimeOptions"})
+	return err
+}
+
+// Syncs config specs right away instead of waiting for the next poll, e.g. after a webhook reports a change.
+// Uses the data adapter when it is used for updates, else the network. Returns whether any spec changed
+func (c *Client) SyncNow() (bool, error) {
+	var updated bool
+	var err error
+	c.errorBoundary.captureVoid(func(context *evalContext) {
+		if c.evaluator.store.isShutdown() {
+			err = errors.New(ClientShutdownError)
+			return
+		}
+		updated, err = c.evaluator.store.syncConfigSpecs()
+	}, &evalContext{Caller: "syncNow"})
+	return updated, err
+}
+
+// Gets the most recent error from syncing config specs or ID lists with the server, or nil if the last sync succeeded.
+// Network failures are *TransportError values carrying the response status code. Config specs generated for a
+// different SDK key are reported as an *SDKKeyMismatchError until a matching response is received
+func (c *Client) LastSyncError() error {
+	var err error
+	c.errorBoundary.captureVoid(func(context *evalContext) {
+		err = c.evaluator.store.getLastSyncError()
+	}, &evalContext{Caller: "lastSyncError"})
+	return err
+}
+
+// Gets the configuration this client is running with after defaults and runtime updates are applied, e.g. for a
+// debug endpoint. The SDK key is redacted
+func (c *Client) EffectiveOptions() ResolvedOptions {
+	var resolved ResolvedOptions
+	c.errorBoundary.captureVoid(func(context *evalContext) {
+		resolved = c.resolveOptions()
+	}, &evalContext{Caller: "effectiveOptions"})
+	return resolved
+}
+
+// Creates a client that evaluates against its own copy of the given specs, for isolating the rules of one tenant.
+// It shares the logger and transport of this client and never polls, so its specs only change with a new sub client.
+// ID lists are read from this client, and shutting it down leaves this client running.
+// If the specs can't be applied, the sub client returns defaults like an uninitialized client
+func (c *Client) NewSubClient(bootstrapValues string) *Client {
+	store := newStoreInternal(
+		c.transport,
+		c.evaluator.store.getConfigSyncInterval(),
+		c.evaluator.store.getIDListSyncInterval(),
+		nil,
+		c.errorBoundary,
+		nil,
+		c.diagnostics,
+		c.sdkKey,
+		bootstrapValues,
+	)
+	store.idListsFrom = c.evaluator.store
+	subClient := &Client{
+		sdkKey:        c.sdkKey,
+		evaluator:     c.evaluator.newSubEvaluator(store),
+		logger:        c.logger,
+		transport:     c.transport,
+		errorBoundary: c.errorBoundary,
+		options:       c.options,
+		diagnostics:   c.diagnostics,
+		isSubClient:   true,
+	}
+	c.errorBoundary.captureVoid(func(context *evalContext) {
+		initContext := newInitContext()
+		store.initializeFromBootstrap(initContext)
+		if initContext.Error != nil {
+			Logger().LogError(initContext.Error)
+		}
+		store.mu.Lock()
+		store.initialSyncTime = store.lastSyncTime
+		store.mu.Unlock()
+	}, &evalContext{Caller: "newSubClient"})
+	return subClient
+}
+
 // Cleans up Statsig, persisting any Event Logs and cleanup processes
 // Using any method is undefined after Shutdown() has been called
 func (c *Client) Shutdown() {
 	c.errorBoundary.captureVoid(func(context *evalContext) {
+		if c.isSubClient {
+			c.evaluator.store.stopPolling()
+			return
+		}
 		c.logger.flush(true)
 		c.evaluator.shutdown()
 	}, &evalContext{Caller: "shutdown"})
 }
 
+// Sends the buffered events without shutting the client down. Returns once the send is dispatched in the background.
+// With Options.MinLogEventInterval set, a flush sooner than the interval is scheduled for when it elapses
+func (c *Client) FlushEvents() {
+	c.errorBoundary.captureVoid(func(context *evalContext) {
+		c.logger.flush(false)
+	}, &evalContext{Caller: "flushEvents"})
+}
+
+// Sends the buffered events and blocks until they, and any other sends still in flight, complete.
+// Returns ErrFlushTimeout if they have not completed within timeout. Meant for workers that may be frozen
+// or terminated soon after handling a request
+func (c *Client) FlushEventsAndWait(timeout time.Duration) error {
+	var err error
+	c.errorBoundary.captureVoid(func(context *evalContext) {
+		c.logger.flush(false)
+		err = c.logger.waitForSends(timeout)
+	}, &evalContext{Caller: "flushEventsAndWait"})
+	return err
+}
+
+// Gets the number of events buffered and not yet handed to a send
+func (c *Client) QueuedEventCount() int {
+	count := 0
+	c.errorBoundary.captureVoid(func(context *evalContext) {
+		count = c.logger.queuedEventCount()
+	}, &evalContext{Caller: "queuedEventCount"})
+	return count
+}
+
+// The subset of Options that can be changed after initialization. Zero values leave the current setting unchanged
+type RuntimeOptions struct {
+	ConfigSyncInterval time.Duration
+	IDListSyncInterval time.Duration
+	LoggingInterval    time.Duration
+	DisableAllLogging  *bool
+}
+
+type GetGateOptions struct {
+	DisableLogExposures bool
+	EvaluationTime      time.Time // Evaluates current_time conditions as of this time instead of now. Exposure timestamps are unaffected
+}
+
 type GetExperimentOptions struct {
 	DisableLogExposures bool
-	PersistedValues     UserPersistedValues
+	PersistedValues     UserPersistedValues // Sticky values to honor for this call. Without Options.UserPersistentStorage they are read-only and new assignments are not saved
+	EvaluationTime      time.Time           // Evaluates current_time conditions as of this time instead of now. Exposure timestamps are unaffected
 }
 
 type GetLayerOptions struct {
 	DisableLogExposures bool
-	PersistedValues     UserPersistedValues
+	PersistedValues     UserPersistedValues // Sticky values to honor for this call. Without Options.UserPersistentStorage they are read-only and new assignments are not saved
+	EvaluationTime      time.Time           // Evaluates current_time conditions as of this time instead of now. Exposure timestamps are unaffected
 }
 
 type gateResponse struct {
@@ -390,18 +1009,26 @@ type getConfigInput struct {
 	StatsigMetadata statsigMetadata `json:"statsigMetadata"`
 }
 
-func (c *Client) checkGateImpl(user User, name string, context *evalContext) FeatureGate {
+func (c *Client) checkGateImpl(user User, name string, context *evalContext) (gate FeatureGate) {
+	name = c.evaluator.store.getGateName(name)
+	if defaultValue, ok := c.defaultGateValueOnError(name); ok {
+		defer c.errorBoundary.ebRecover(func() {
+			gate = *NewGate(name, defaultValue, "", "", c.evaluator.createEvaluationDetails(ReasonError))
+		}, &errorContext{evalContext: context, Caller: context.Caller})
+	}
 	if !c.verifyUser(user) {
-		return *NewGate(name, false, "", "", nil)
+		return *NewGate(name, false, "", "", c.evaluator.createEvaluationDetails(ReasonError))
 	}
 	user = normalizeUser(user, *c.options)
 	res := c.evaluator.evalGate(user, name, context)
+	c.runRuleEvaluationHook(name, res, user)
+	c.recordSecondaryExposures(name, res)
 	if res.FetchFromServer {
 		serverRes := fetchGate(user, name, c.transport)
 		res = &evalResult{Value: serverRes.Value, RuleID: serverRes.RuleID}
 	} else {
 		exposure := c.logger.getGateExposureWithEvaluationDetails(user, name, res, context)
-		if !context.DisableLogExposures {
+		if !context.DisableLogExposures && c.logger.isExposureLoggingEnabled(exposure, context) {
 			c.logger.logExposure(*exposure)
 		}
 
@@ -421,7 +1048,23 @@ func (c *Client) checkGateImpl(user User, name string, context *evalContext) Fea
 			}
 		}
 	}
-	return *NewGate(name, res.Value, res.RuleID, res.GroupName, res.EvaluationDetails)
+	gate = *NewGate(name, res.Value, res.RuleID, res.GroupName, res.EvaluationDetails)
+	gate.publicPass = res.PublicPass
+	gate.samplingRate = res.SamplingRate
+	return gate
+}
+
+// Looks up Options.DefaultGateValuesOnError, matching names regardless of case when CaseInsensitiveConfigNames is set
+func (c *Client) defaultGateValueOnError(name string) (bool, bool) {
+	if defaultValue, ok := c.options.DefaultGateValuesOnError[name]; ok || !c.options.CaseInsensitiveConfigNames {
+		return defaultValue, ok
+	}
+	for gateName, defaultValue := range c.options.DefaultGateValuesOnError {
+		if strings.EqualFold(gateName, name) {
+			return defaultValue, true
+		}
+	}
+	return false, false
 }
 
 func (c *Client) getConfigImpl(user User, name string, context *evalContext) DynamicConfig {
@@ -429,15 +1072,28 @@ func (c *Client) getConfigImpl(user User, name string, context *evalContext) Dyn
 		return *NewConfig(name, nil, "", "", nil)
 	}
 	user = normalizeUser(user, *c.options)
+	name = c.evaluator.store.getDynamicConfigName(name)
 	res := c.evaluator.evalConfig(user, name, context)
+	c.runRuleEvaluationHook(name, res, user)
+	c.recordSecondaryExposures(name, res)
 	config := *NewConfig(name, res.JsonValue, res.RuleID, res.GroupName, res.EvaluationDetails)
+	config.matchedRuleID = res.MatchedRuleID
+	config.passPercentage = res.PassPercentage
+	config.samplingRate = res.SamplingRate
+	if spec, ok := c.evaluator.store.getDynamicConfig(name); ok {
+		config.entityType = getConfigType(spec)
+	}
+	config.clearUnresolvedPlaceholders = c.options.ClearUnresolvedPlaceholders
 	if res.FetchFromServer {
 		res = c.fetchConfigFromServer(user, name)
 		config = *NewConfig(name, res.JsonValue, res.RuleID, res.GroupName, res.EvaluationDetails)
 	} else {
 		exposure := c.logger.getConfigExposureWithEvaluationDetails(user, name, res, context)
-		if !context.DisableLogExposures {
+		if !context.DisableLogExposures && c.logger.isExposureLoggingEnabled(exposure, context) {
 			c.logger.logExposure(*exposure)
+			if context.IsExperiment {
+				c.logger.lowExposures.record(name)
+			}
 		}
 
 		if context.IsExperiment && c.options.EvaluationCallbacks.ExperimentEvaluationCallback != nil {
@@ -470,35 +1126,46 @@ func (c *Client) getLayerImpl(user User, name string, context *evalContext) Laye
 		return *NewLayer(name, nil, "", "", nil, "")
 	}
 
-	user = normalizeUser(user, *c.options)
+	return c.getLayerForNormalizedUser(normalizeUser(user, *c.options), name, context)
+}
+
+func (c *Client) getLayerForNormalizedUser(user User, name string, context *evalContext) Layer {
+	name = c.evaluator.store.getLayerConfigName(name)
 	res := c.evaluator.evalLayer(user, name, context)
+	c.runRuleEvaluationHook(name, res, user)
+	c.recordSecondaryExposures(name, res)
 
 	if res.FetchFromServer {
 		res = c.fetchConfigFromServer(user, name)
 	}
 
-	logFunc := func(layer Layer, parameterName string) {
-		exposure := c.logger.getLayerExposureWithEvaluationDetails(user, layer, parameterName, res, context)
-		if !context.DisableLogExposures {
-			c.logger.logExposure(*exposure)
-		}
-		if c.options.EvaluationCallbacks.LayerEvaluationCallback != nil {
-			if c.options.EvaluationCallbacks.IncludeDisabledExposures || !context.DisableLogExposures {
-				c.options.EvaluationCallbacks.LayerEvaluationCallback(name, parameterName, DynamicConfig{layer.configBase}, exposure)
-			} else {
-				c.options.EvaluationCallbacks.LayerEvaluationCallback(name, parameterName, DynamicConfig{layer.configBase}, nil)
-			}
+	layer := *NewLayer(name, res.JsonValue, res.RuleID, res.GroupName, nil, res.ConfigDelegate)
+	layer.EvaluationDetails = res.EvaluationDetails
+	layer.samplingRate = res.SamplingRate
+	layer.exposureLogger = layerExposureLogger{client: c, user: user, res: res, context: context}
+	return layer
+}
+
+func (c *Client) logLayerParameterExposure(user User, layer Layer, parameterName string, res *evalResult, context *evalContext) {
+	name := layer.Name
+	exposure := c.logger.getLayerExposureWithEvaluationDetails(user, layer, parameterName, res, context)
+	if !context.DisableLogExposures && c.logger.isExposureLoggingEnabled(exposure, context) {
+		c.logger.logExposure(*exposure)
+	}
+	if c.options.EvaluationCallbacks.LayerEvaluationCallback != nil {
+		if c.options.EvaluationCallbacks.IncludeDisabledExposures || !context.DisableLogExposures {
+			c.options.EvaluationCallbacks.LayerEvaluationCallback(name, parameterName, DynamicConfig{configBase: layer.configBase}, exposure)
+		} else {
+			c.options.EvaluationCallbacks.LayerEvaluationCallback(name, parameterName, DynamicConfig{configBase: layer.configBase}, nil)
 		}
-		if c.options.EvaluationCallbacks.ExposureCallback != nil {
-			if c.options.EvaluationCallbacks.IncludeDisabledExposures || !context.DisableLogExposures {
-				c.options.EvaluationCallbacks.ExposureCallback(name, exposure)
-			} else {
-				c.options.EvaluationCallbacks.ExposureCallback(name, nil)
-			}
+	}
+	if c.options.EvaluationCallbacks.ExposureCallback != nil {
+		if c.options.EvaluationCallbacks.IncludeDisabledExposures || !context.DisableLogExposures {
+			c.options.EvaluationCallbacks.ExposureCallback(name, exposure)
+		} else {
+			c.options.EvaluationCallbacks.ExposureCallback(name, nil)
 		}
 	}
-
-	return *NewLayer(name, res.JsonValue, res.RuleID, res.GroupName, &logFunc, res.ConfigDelegate)
 }
 
 func fetchGate(user User, gateName string, t *transport) gateResponse {
@@ -537,6 +1204,7 @@ func fetchConfig(user User, configName string, t *transport) configResponse {
 }
 
 func normalizeUser(user User, options Options) User {
+	user = mergeUsers(options.DefaultUser, user)
 	env := make(map[string]string)
 	// Copy to avoid data race. We modify the map below.
 	for k, v := range options.Environment.Params {
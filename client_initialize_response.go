@@ -1,6 +1,9 @@
 package statsig
 
 import (
+	"encoding/json"
+	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -16,6 +19,15 @@ type ClientInitializeResponse struct {
 	SDKInfo        SDKInfo                             `json:"sdkInfo"`
 	User           User                                `json:"user"`
 	HashUsed       string                              `json:"hash_used"`
+	Exposures      map[string]SecondaryExposure        `json:"exposures,omitempty"`
+	Gates          map[string]bool                     `json:"gates,omitempty"`
+	Configs        map[string]LegacyConfigValue        `json:"configs,omitempty"`
+}
+
+// A dynamic config or experiment in the legacy "configs" map, see GCIROptions.LegacyValueFormat
+type LegacyConfigValue struct {
+	Value map[string]interface{} `json:"value"`
+	Group string                 `json:"group"`
 }
 
 type SDKInfo struct {
@@ -24,9 +36,10 @@ type SDKInfo struct {
 }
 
 type baseSpecInitializeResponse struct {
-	Name               string              `json:"name"`
-	RuleID             string              `json:"rule_id"`
-	SecondaryExposures []SecondaryExposure `json:"secondary_exposures"`
+	Name                  string              `json:"name"`
+	RuleID                string              `json:"rule_id"`
+	SecondaryExposures    []SecondaryExposure `json:"secondary_exposures"`
+	SecondaryExposureKeys []string            `json:"-"` // Keys into ClientInitializeResponse.Exposures, set in place of SecondaryExposures with GCIROptions.DedupedExposureFormat
 }
 
 type GateInitializeResponse struct {
@@ -59,6 +72,98 @@ type LayerInitializeResponse struct {
 	AllocatedExperimentName       string                 `json:"allocated_experiment_name,omitempty"`
 	UndelegatedSecondaryExposures []SecondaryExposure    `json:"undelegated_secondary_exposures"`
 	GroupName                     string                 `json:"group_name,omitempty"`
+	// Keys into ClientInitializeResponse.Exposures, set in place of UndelegatedSecondaryExposures with GCIROptions.DedupedExposureFormat
+	UndelegatedSecondaryExposureKeys []string `json:"-"`
+}
+
+// With exposure keys set, secondary_exposures is written as the keys, the format client SDKs map back to full exposures
+func (r GateInitializeResponse) MarshalJSON() ([]byte, error) {
+	type gateResponse GateInitializeResponse
+	if r.SecondaryExposureKeys == nil {
+		return json.Marshal(gateResponse(r))
+	}
+	return json.Marshal(struct {
+		gateResponse
+		SecondaryExposures []string `json:"secondary_exposures"`
+	}{gateResponse(r), r.SecondaryExposureKeys})
+}
+
+func (r *GateInitializeResponse) UnmarshalJSON(data []byte) error {
+	type gateResponse GateInitializeResponse
+	var keyed struct {
+		gateResponse
+		SecondaryExposures json.RawMessage `json:"secondary_exposures"`
+	}
+	if err := json.Unmarshal(data, &keyed); err != nil {
+		return err
+	}
+	*r = GateInitializeResponse(keyed.gateResponse)
+	return unmarshalSecondaryExposures(keyed.SecondaryExposures, &r.SecondaryExposures, &r.SecondaryExposureKeys)
+}
+
+func (r ConfigInitializeResponse) MarshalJSON() ([]byte, error) {
+	type configResponse ConfigInitializeResponse
+	if r.SecondaryExposureKeys == nil {
+		return json.Marshal(configResponse(r))
+	}
+	return json.Marshal(struct {
+		configResponse
+		SecondaryExposures []string `json:"secondary_exposures"`
+	}{configResponse(r), r.SecondaryExposureKeys})
+}
+
+func (r *ConfigInitializeResponse) UnmarshalJSON(data []byte) error {
+	type configResponse ConfigInitializeResponse
+	var keyed struct {
+		configResponse
+		SecondaryExposures json.RawMessage `json:"secondary_exposures"`
+	}
+	if err := json.Unmarshal(data, &keyed); err != nil {
+		return err
+	}
+	*r = ConfigInitializeResponse(keyed.configResponse)
+	return unmarshalSecondaryExposures(keyed.SecondaryExposures, &r.SecondaryExposures, &r.SecondaryExposureKeys)
+}
+
+func (r LayerInitializeResponse) MarshalJSON() ([]byte, error) {
+	type layerResponse LayerInitializeResponse
+	if r.SecondaryExposureKeys == nil && r.UndelegatedSecondaryExposureKeys == nil {
+		return json.Marshal(layerResponse(r))
+	}
+	return json.Marshal(struct {
+		layerResponse
+		SecondaryExposures            []string `json:"secondary_exposures"`
+		UndelegatedSecondaryExposures []string `json:"undelegated_secondary_exposures"`
+	}{layerResponse(r), r.SecondaryExposureKeys, r.UndelegatedSecondaryExposureKeys})
+}
+
+func (r *LayerInitializeResponse) UnmarshalJSON(data []byte) error {
+	type layerResponse LayerInitializeResponse
+	var keyed struct {
+		layerResponse
+		SecondaryExposures            json.RawMessage `json:"secondary_exposures"`
+		UndelegatedSecondaryExposures json.RawMessage `json:"undelegated_secondary_exposures"`
+	}
+	if err := json.Unmarshal(data, &keyed); err != nil {
+		return err
+	}
+	*r = LayerInitializeResponse(keyed.layerResponse)
+	if err := unmarshalSecondaryExposures(keyed.SecondaryExposures, &r.SecondaryExposures, &r.SecondaryExposureKeys); err != nil {
+		return err
+	}
+	return unmarshalSecondaryExposures(keyed.UndelegatedSecondaryExposures, &r.UndelegatedSecondaryExposures, &r.UndelegatedSecondaryExposureKeys)
+}
+
+// Reads secondary_exposures as full exposures, or as keys when it holds strings
+func unmarshalSecondaryExposures(data json.RawMessage, exposures *[]SecondaryExposure, keys *[]string) error {
+	if len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, exposures); err == nil {
+		return nil
+	}
+	*exposures = nil
+	return json.Unmarshal(data, keys)
 }
 
 func mergeMaps(a map[string]interface{}, b map[string]interface{}) {
@@ -67,37 +172,58 @@ func mergeMaps(a map[string]interface{}, b map[string]interface{}) {
 	}
 }
 
+const (
+	gcirGateEntity   = "gate"
+	gcirConfigEntity = "config"
+	gcirLayerEntity  = "layer"
+)
+
+// Called with the unhashed name of each gate, config and layer evaluated into a client initialize response, along with
+// an evaluation whose secondary exposures are unhashed too
+type gcirEvaluationCallback func(entity string, name string, spec configSpec, res *evalResult)
+
 func getClientInitializeResponse(
 	user User,
 	e *evaluator,
 	context *evalContext,
+	onEvaluated gcirEvaluationCallback,
 ) ClientInitializeResponse {
 	hashAlgorithm := context.Hash
 	if hashAlgorithm != "none" && hashAlgorithm != "djb2" {
 		hashAlgorithm = "sha256"
 	}
 
-	evalResultToBaseResponse := func(name string, eval *evalResult) (string, baseSpecInitializeResponse) {
+	// Evaluations keep secondary exposure gate names unhashed for onEvaluated. They are hashed into the response below
+	unhashedContext := *context
+	unhashedContext.Hash = "none"
+	unhashed := &unhashedContext
+	notifyEvaluated := func(entity string, name string, spec configSpec, res *evalResult) {
+		if onEvaluated != nil {
+			onEvaluated(entity, name, spec, res)
+		}
+	}
+	evalResultToBaseResponse := func(name string, eval *evalResult, exposureHash string) (string, baseSpecInitializeResponse) {
 		hashedName := hashName(hashAlgorithm, name)
 		result := baseSpecInitializeResponse{
 			Name:               hashedName,
 			RuleID:             eval.RuleID,
-			SecondaryExposures: eval.SecondaryExposures,
+			SecondaryExposures: hashSecondaryExposures(exposureHash, eval.SecondaryExposures),
 		}
 		return hashedName, result
 	}
 	gateToResponse := func(gateName string, spec configSpec) (string, GateInitializeResponse) {
 		evalRes := &evalResult{}
 		if context.IncludeLocalOverrides {
-			if gateOverride, hasOverride := e.getGateOverrideEval(gateName); hasOverride {
+			if gateOverride, hasOverride := e.getGateOverrideEval(user, gateName); hasOverride {
 				evalRes = gateOverride
 			} else {
-				evalRes = e.eval(user, spec, 0, context)
+				evalRes = e.eval(user, spec, 0, unhashed)
 			}
 		} else {
-			evalRes = e.eval(user, spec, 0, context)
+			evalRes = e.eval(user, spec, 0, unhashed)
 		}
-		hashedName, base := evalResultToBaseResponse(gateName, evalRes)
+		notifyEvaluated(gcirGateEntity, gateName, spec, evalRes)
+		hashedName, base := evalResultToBaseResponse(gateName, evalRes, context.Hash)
 		result := GateInitializeResponse{
 			baseSpecInitializeResponse: base,
 			Value:                      evalRes.Value,
@@ -108,15 +234,16 @@ func getClientInitializeResponse(
 	configToResponse := func(configName string, spec configSpec) (string, ConfigInitializeResponse) {
 		evalRes := &evalResult{}
 		if context.IncludeLocalOverrides {
-			if configOverride, hasOverride := e.getConfigOverrideEval(configName); hasOverride {
+			if configOverride, hasOverride := e.getConfigOverrideEval(user, configName); hasOverride {
 				evalRes = configOverride
 			} else {
-				evalRes = e.eval(user, spec, 0, context)
+				evalRes = e.eval(user, spec, 0, unhashed)
 			}
 		} else {
-			evalRes = e.eval(user, spec, 0, context)
+			evalRes = e.eval(user, spec, 0, unhashed)
 		}
-		hashedName, base := evalResultToBaseResponse(configName, evalRes)
+		notifyEvaluated(gcirConfigEntity, configName, spec, evalRes)
+		hashedName, base := evalResultToBaseResponse(configName, evalRes, context.Hash)
 		result := ConfigInitializeResponse{
 			baseSpecInitializeResponse: base,
 			Value:                      evalRes.JsonValue,
@@ -150,14 +277,15 @@ func getClientInitializeResponse(
 		return hashedName, result
 	}
 	layerToResponse := func(layerName string, spec configSpec) (string, LayerInitializeResponse) {
-		evalResult := e.eval(user, spec, 0, &evalContext{Hash: hashAlgorithm})
-		hashedName, base := evalResultToBaseResponse(layerName, evalResult)
+		evalResult := e.eval(user, spec, 0, &evalContext{Hash: "none"})
+		notifyEvaluated(gcirLayerEntity, layerName, spec, evalResult)
+		hashedName, base := evalResultToBaseResponse(layerName, evalResult, hashAlgorithm)
 		result := LayerInitializeResponse{
 			baseSpecInitializeResponse:    base,
 			Value:                         evalResult.JsonValue,
 			Group:                         evalResult.RuleID,
 			IsDeviceBased:                 strings.EqualFold(spec.IDType, "stableid"),
-			UndelegatedSecondaryExposures: evalResult.UndelegatedSecondaryExposures,
+			UndelegatedSecondaryExposures: hashSecondaryExposures(hashAlgorithm, evalResult.UndelegatedSecondaryExposures),
 		}
 		delegate := evalResult.ConfigDelegate
 		result.ExplicitParameters = new([]string)
@@ -170,7 +298,7 @@ func getClientInitializeResponse(
 		}
 		if delegate != "" {
 			delegateSpec, exists := e.store.getDynamicConfig(delegate)
-			delegateResult := e.eval(user, delegateSpec, 0, context)
+			delegateResult := e.eval(user, delegateSpec, 0, unhashed)
 			if exists {
 				result.AllocatedExperimentName = hashName(hashAlgorithm, delegate)
 				result.IsUserInExperiment = new(bool)
@@ -260,5 +388,99 @@ func getClientInitializeResponse(
 		User:           *user.getCopyForLogging(),
 		HashUsed:       hashAlgorithm,
 	}
+	if context.DedupedExposureFormat {
+		dedupeClientInitializeResponseExposures(&response)
+	}
 	return response
 }
+
+// Copies the exposures with their gate names hashed like the entity names of the response
+func hashSecondaryExposures(hashAlgorithm string, exposures []SecondaryExposure) []SecondaryExposure {
+	if exposures == nil || (hashAlgorithm != "sha256" && hashAlgorithm != "djb2") {
+		return exposures
+	}
+	hashed := make([]SecondaryExposure, len(exposures))
+	for i, exposure := range exposures {
+		exposure.Gate = hashName(hashAlgorithm, exposure.Gate)
+		hashed[i] = exposure
+	}
+	return hashed
+}
+
+// Strips the identifying fields of the user echoed back in the response, for responses handed to untrusted clients
+func redactClientInitializeResponseUser(response *ClientInitializeResponse) {
+	response.User = User{StatsigEnvironment: response.User.StatsigEnvironment}
+	response.EvaluatedKeys = make(map[string]interface{})
+}
+
+// Adds the legacy "gates" and "configs" maps for client SDKs that predate feature_gates and dynamic_configs.
+// Both are keyed by the same hashed names, with gates reduced to their boolean value and configs to their
+// value and rule ID group. The current maps are left in place so newer clients read the same response
+func addLegacyValueFormat(response *ClientInitializeResponse) {
+	response.Gates = make(map[string]bool, len(response.FeatureGates))
+	for name, gate := range response.FeatureGates {
+		response.Gates[name] = gate.Value
+	}
+	response.Configs = make(map[string]LegacyConfigValue, len(response.DynamicConfigs))
+	for name, config := range response.DynamicConfigs {
+		response.Configs[name] = LegacyConfigValue{Value: config.Value, Group: config.RuleID}
+	}
+}
+
+// Moves secondary exposures into the shared Exposures pool and leaves only their keys, written in secondary_exposures
+// in the format client SDKs map back to full exposures, so an exposure shared across many entities is only sent once
+func dedupeClientInitializeResponseExposures(response *ClientInitializeResponse) {
+	pool := make(map[string]SecondaryExposure)
+	keys := make(map[SecondaryExposure]string)
+	toKeys := func(exposures []SecondaryExposure) []string {
+		refs := make([]string, 0, len(exposures))
+		for _, exposure := range exposures {
+			key, exists := keys[exposure]
+			if !exists {
+				key = strconv.Itoa(len(pool))
+				keys[exposure] = key
+				pool[key] = exposure
+			}
+			refs = append(refs, key)
+		}
+		return refs
+	}
+
+	// Visit entities in a stable order so the pool keys are deterministic
+	gateNames := make([]string, 0, len(response.FeatureGates))
+	for name := range response.FeatureGates {
+		gateNames = append(gateNames, name)
+	}
+	sort.Strings(gateNames)
+	for _, name := range gateNames {
+		gate := response.FeatureGates[name]
+		gate.SecondaryExposureKeys = toKeys(gate.SecondaryExposures)
+		gate.SecondaryExposures = nil
+		response.FeatureGates[name] = gate
+	}
+	configNames := make([]string, 0, len(response.DynamicConfigs))
+	for name := range response.DynamicConfigs {
+		configNames = append(configNames, name)
+	}
+	sort.Strings(configNames)
+	for _, name := range configNames {
+		config := response.DynamicConfigs[name]
+		config.SecondaryExposureKeys = toKeys(config.SecondaryExposures)
+		config.SecondaryExposures = nil
+		response.DynamicConfigs[name] = config
+	}
+	layerNames := make([]string, 0, len(response.LayerConfigs))
+	for name := range response.LayerConfigs {
+		layerNames = append(layerNames, name)
+	}
+	sort.Strings(layerNames)
+	for _, name := range layerNames {
+		layer := response.LayerConfigs[name]
+		layer.SecondaryExposureKeys = toKeys(layer.SecondaryExposures)
+		layer.UndelegatedSecondaryExposureKeys = toKeys(layer.UndelegatedSecondaryExposures)
+		layer.SecondaryExposures = nil
+		layer.UndelegatedSecondaryExposures = nil
+		response.LayerConfigs[name] = layer
+	}
+	response.Exposures = pool
+}
@@ -0,0 +1,290 @@
+package statsig
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+)
+
+// Encoding of the bytes returned by GetClientInitializeResponseBytes
+type GCIRFormat string
+
+const (
+	GCIRFormatJSON GCIRFormat = "json"
+	// MessagePack (https://msgpack.org) of the same document as the JSON format, with the same keys. Any
+	// MessagePack decoder reads it into the maps and arrays a JSON decoder would produce. Integers are sent
+	// as MessagePack integers, other numbers as float 64, and map keys are sorted so equal responses encode equally
+	GCIRFormatMessagePack GCIRFormat = "msgpack"
+)
+
+func encodeClientInitializeResponse(response ClientInitializeResponse, format GCIRFormat) ([]byte, error) {
+	encoded, err := json.Marshal(response)
+	if err != nil || format == "" || format == GCIRFormatJSON {
+		return encoded, err
+	}
+	if format != GCIRFormatMessagePack {
+		return nil, fmt.Errorf("unsupported client initialize response format %s", format)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+	var document interface{}
+	if err := decoder.Decode(&document); err != nil {
+		return nil, err
+	}
+	buf := new(bytes.Buffer)
+	if err := writeMessagePack(buf, document); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Reads bytes produced by GetClientInitializeResponseBytes in the given format back into a response
+func DecodeClientInitializeResponse(data []byte, format GCIRFormat) (ClientInitializeResponse, error) {
+	var response ClientInitializeResponse
+	if format == "" || format == GCIRFormatJSON {
+		err := json.Unmarshal(data, &response)
+		return response, err
+	}
+	if format != GCIRFormatMessagePack {
+		return response, fmt.Errorf("unsupported client initialize response format %s", format)
+	}
+	reader := bytes.NewReader(data)
+	document, err := readMessagePack(reader)
+	if err != nil {
+		return response, err
+	}
+	if reader.Len() != 0 {
+		return response, errors.New("trailing bytes after the client initialize response")
+	}
+	encoded, err := json.Marshal(document)
+	if err != nil {
+		return response, err
+	}
+	err = json.Unmarshal(encoded, &response)
+	return response, err
+}
+
+func writeMessagePack(buf *bytes.Buffer, value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		buf.WriteByte(0xc0)
+	case bool:
+		if v {
+			buf.WriteByte(0xc3)
+		} else {
+			buf.WriteByte(0xc2)
+		}
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			writeMessagePackInt(buf, i)
+			return nil
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return err
+		}
+		buf.WriteByte(0xcb)
+		_ = binary.Write(buf, binary.BigEndian, math.Float64bits(f))
+	case string:
+		writeMessagePackHeader(buf, len(v), 0xa0, 32, 0xd9, 0xda, 0xdb)
+		buf.WriteString(v)
+	case []interface{}:
+		writeMessagePackHeader(buf, len(v), 0x90, 16, 0, 0xdc, 0xdd)
+		for _, item := range v {
+			if err := writeMessagePack(buf, item); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		writeMessagePackHeader(buf, len(v), 0x80, 16, 0, 0xde, 0xdf)
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			_ = writeMessagePack(buf, key)
+			if err := writeMessagePack(buf, v[key]); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("cannot encode %T as MessagePack", value)
+	}
+	return nil
+}
+
+func writeMessagePackInt(buf *bytes.Buffer, i int64) {
+	switch {
+	case i >= 0 && i <= math.MaxInt8:
+		buf.WriteByte(byte(i))
+	case i >= -32 && i < 0:
+		buf.WriteByte(byte(int8(i)))
+	case i >= math.MinInt8 && i <= math.MaxInt8:
+		buf.WriteByte(0xd0)
+		buf.WriteByte(byte(int8(i)))
+	case i >= math.MinInt16 && i <= math.MaxInt16:
+		buf.WriteByte(0xd1)
+		_ = binary.Write(buf, binary.BigEndian, int16(i))
+	case i >= math.MinInt32 && i <= math.MaxInt32:
+		buf.WriteByte(0xd2)
+		_ = binary.Write(buf, binary.BigEndian, int32(i))
+	default:
+		buf.WriteByte(0xd3)
+		_ = binary.Write(buf, binary.BigEndian, i)
+	}
+}
+
+// Writes the fix format when length fits below fixLimit, else the 8, 16 or 32 bit length format.
+// A zero code8 means the type has no 8 bit length format
+func writeMessagePackHeader(buf *bytes.Buffer, length int, fixCode byte, fixLimit int, code8 byte, code16 byte, code32 byte) {
+	switch {
+	case length < fixLimit:
+		buf.WriteByte(fixCode | byte(length))
+	case code8 != 0 && length <= math.MaxUint8:
+		buf.WriteByte(code8)
+		buf.WriteByte(byte(length))
+	case length <= math.MaxUint16:
+		buf.WriteByte(code16)
+		_ = binary.Write(buf, binary.BigEndian, uint16(length))
+	default:
+		buf.WriteByte(code32)
+		_ = binary.Write(buf, binary.BigEndian, uint32(length))
+	}
+}
+
+func readMessagePack(reader *bytes.Reader) (interface{}, error) {
+	code, err := reader.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case code <= 0x7f:
+		return int64(code), nil
+	case code >= 0xe0:
+		return int64(int8(code)), nil
+	case code&0xe0 == 0xa0:
+		return readMessagePackString(reader, int(code&0x1f))
+	case code&0xf0 == 0x90:
+		return readMessagePackArray(reader, int(code&0x0f))
+	case code&0xf0 == 0x80:
+		return readMessagePackMap(reader, int(code&0x0f))
+	}
+	switch code {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		var value uint64
+		err := readMessagePackUint(reader, 1<<(code-0xcc), &value)
+		return value, err
+	case 0xd0:
+		var value int8
+		err := binary.Read(reader, binary.BigEndian, &value)
+		return int64(value), err
+	case 0xd1:
+		var value int16
+		err := binary.Read(reader, binary.BigEndian, &value)
+		return int64(value), err
+	case 0xd2:
+		var value int32
+		err := binary.Read(reader, binary.BigEndian, &value)
+		return int64(value), err
+	case 0xd3:
+		var value int64
+		err := binary.Read(reader, binary.BigEndian, &value)
+		return value, err
+	case 0xca:
+		var value float32
+		err := binary.Read(reader, binary.BigEndian, &value)
+		return float64(value), err
+	case 0xcb:
+		var value float64
+		err := binary.Read(reader, binary.BigEndian, &value)
+		return value, err
+	case 0xd9, 0xda, 0xdb:
+		var length uint64
+		if err := readMessagePackUint(reader, 1<<(code-0xd9), &length); err != nil {
+			return nil, err
+		}
+		return readMessagePackString(reader, int(length))
+	case 0xdc, 0xdd:
+		var length uint64
+		if err := readMessagePackUint(reader, 2<<(code-0xdc), &length); err != nil {
+			return nil, err
+		}
+		return readMessagePackArray(reader, int(length))
+	case 0xde, 0xdf:
+		var length uint64
+		if err := readMessagePackUint(reader, 2<<(code-0xde), &length); err != nil {
+			return nil, err
+		}
+		return readMessagePackMap(reader, int(length))
+	}
+	return nil, fmt.Errorf("unsupported MessagePack type 0x%x", code)
+}
+
+func readMessagePackUint(reader *bytes.Reader, size int, out *uint64) error {
+	raw := make([]byte, size)
+	if _, err := io.ReadFull(reader, raw); err != nil {
+		return errors.New("truncated MessagePack value")
+	}
+	*out = 0
+	for _, b := range raw {
+		*out = *out<<8 | uint64(b)
+	}
+	return nil
+}
+
+func readMessagePackString(reader *bytes.Reader, length int) (string, error) {
+	if length > reader.Len() {
+		return "", errors.New("truncated MessagePack string")
+	}
+	raw := make([]byte, length)
+	_, _ = reader.Read(raw)
+	return string(raw), nil
+}
+
+func readMessagePackArray(reader *bytes.Reader, length int) ([]interface{}, error) {
+	if length > reader.Len() {
+		return nil, errors.New("truncated MessagePack array")
+	}
+	items := make([]interface{}, length)
+	for i := range items {
+		item, err := readMessagePack(reader)
+		if err != nil {
+			return nil, err
+		}
+		items[i] = item
+	}
+	return items, nil
+}
+
+func readMessagePackMap(reader *bytes.Reader, length int) (map[string]interface{}, error) {
+	if length > reader.Len() {
+		return nil, errors.New("truncated MessagePack map")
+	}
+	entries := make(map[string]interface{}, length)
+	for i := 0; i < length; i++ {
+		key, err := readMessagePack(reader)
+		if err != nil {
+			return nil, err
+		}
+		keyString, ok := key.(string)
+		if !ok {
+			return nil, fmt.Errorf("unsupported MessagePack map key %T", key)
+		}
+		if entries[keyString], err = readMessagePack(reader); err != nil {
+			return nil, err
+		}
+	}
+	return entries, nil
+}
@@ -5,7 +5,9 @@ import (
 	"encoding/json"
 	"net/http"
 	"os"
+	"reflect"
 	"strconv"
+	"sync/atomic"
 	"testing"
 )
 
@@ -126,3 +128,335 @@ func filterClientInitializeResponse(clientInitializeResponse *ClientInitializeRe
 	clientInitializeResponse.SDKInfo = SDKInfo{}
 	clientInitializeResponse.User = User{}
 }
+
+func TestDedupedExposureFormat(t *testing.T) {
+	specs, _ := os.ReadFile("download_config_specs.json")
+	c := newLocalModeClientForTest(t, specs, nil)
+	defer c.Shutdown()
+	user := User{UserID: "123", Email: "testuser@statsig.com"}
+
+	expected := c.GetClientInitializeResponseWithOptions(user, &GCIROptions{HashAlgorithm: "none"})
+	deduped := c.GetClientInitializeResponseWithOptions(user, &GCIROptions{HashAlgorithm: "none", DedupedExposureFormat: true})
+
+	seen := make(map[SecondaryExposure]bool)
+	for key, exposure := range deduped.Exposures {
+		if seen[exposure] {
+			t.Errorf("Expected each exposure to be pooled once, %+v is duplicated under key %s", exposure, key)
+		}
+		seen[exposure] = true
+	}
+	resolve := func(pool map[string]SecondaryExposure, keys []string) []SecondaryExposure {
+		exposures := make([]SecondaryExposure, 0, len(keys))
+		for _, key := range keys {
+			exposure, ok := pool[key]
+			if !ok {
+				t.Errorf("Exposure key %s is missing from the pool", key)
+			}
+			exposures = append(exposures, exposure)
+		}
+		return exposures
+	}
+	assertSameExposures := func(name string, want []SecondaryExposure, got []SecondaryExposure) {
+		if len(want) == 0 && len(got) == 0 {
+			return
+		}
+		if !reflect.DeepEqual(want, got) {
+			t.Errorf("Exposures for %s did not decode back. Expected %+v, received %+v", name, want, got)
+		}
+	}
+
+	sharedExposures := 0
+	for name, gate := range expected.FeatureGates {
+		sharedExposures += len(gate.SecondaryExposures)
+		assertSameExposures(name, gate.SecondaryExposures, resolve(deduped.Exposures, deduped.FeatureGates[name].SecondaryExposureKeys))
+	}
+	for name, config := range expected.DynamicConfigs {
+		sharedExposures += len(config.SecondaryExposures)
+		assertSameExposures(name, config.SecondaryExposures, resolve(deduped.Exposures, deduped.DynamicConfigs[name].SecondaryExposureKeys))
+	}
+	for name, layer := range expected.LayerConfigs {
+		sharedExposures += len(layer.SecondaryExposures) + len(layer.UndelegatedSecondaryExposures)
+		assertSameExposures(name, layer.SecondaryExposures, resolve(deduped.Exposures, deduped.LayerConfigs[name].SecondaryExposureKeys))
+		assertSameExposures(name, layer.UndelegatedSecondaryExposures, resolve(deduped.Exposures, deduped.LayerConfigs[name].UndelegatedSecondaryExposureKeys))
+	}
+	if len(deduped.Exposures) == 0 || len(deduped.Exposures) >= sharedExposures {
+		t.Errorf("Expected the pool to hold fewer exposures than the %d sent inline, received %d", sharedExposures, len(deduped.Exposures))
+	}
+	if expected.Exposures != nil {
+		t.Error("Expected no exposure pool without DedupedExposureFormat")
+	}
+
+	raw, _ := json.Marshal(deduped)
+	var clientView struct {
+		FeatureGates map[string]struct {
+			SecondaryExposures []string `json:"secondary_exposures"`
+		} `json:"feature_gates"`
+		Exposures map[string]SecondaryExposure `json:"exposures"`
+	}
+	if err := json.Unmarshal(raw, &clientView); err != nil {
+		t.Fatalf("Expected secondary_exposures to be serialized as exposure keys: %s", err.Error())
+	}
+	for name, gate := range clientView.FeatureGates {
+		for _, key := range gate.SecondaryExposures {
+			if _, ok := clientView.Exposures[key]; !ok {
+				t.Errorf("Serialized exposure key %s for %s is missing from exposures", key, name)
+			}
+		}
+	}
+	var decoded ClientInitializeResponse
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		t.Fatalf("Expected the deduped response to decode: %s", err.Error())
+	}
+	for name, gate := range expected.FeatureGates {
+		assertSameExposures(name, gate.SecondaryExposures, resolve(decoded.Exposures, decoded.FeatureGates[name].SecondaryExposureKeys))
+	}
+	for name, layer := range expected.LayerConfigs {
+		assertSameExposures(name, layer.UndelegatedSecondaryExposures, resolve(decoded.Exposures, decoded.LayerConfigs[name].UndelegatedSecondaryExposureKeys))
+	}
+}
+
+func TestClientInitializeResponseLogExposures(t *testing.T) {
+	specs, _ := os.ReadFile("download_config_specs.json")
+	c := newLocalModeClientForTest(t, specs, nil)
+	defer c.Shutdown()
+	user := User{UserID: "123", Email: "testuser@statsig.com"}
+	countExposures := func() map[ExposureEventName]int {
+		c.logger.mu.Lock()
+		defer c.logger.mu.Unlock()
+		counts := make(map[ExposureEventName]int)
+		for _, evt := range c.logger.events {
+			if exposure, ok := evt.(ExposureEvent); ok {
+				counts[exposure.EventName]++
+			}
+		}
+		return counts
+	}
+
+	c.GetClientInitializeResponseWithOptions(user, &GCIROptions{HashAlgorithm: "none"})
+	if counts := countExposures(); len(counts) != 0 {
+		t.Errorf("Expected no exposures by default, received %v", counts)
+	}
+
+	response := c.GetClientInitializeResponseWithOptions(user, &GCIROptions{HashAlgorithm: "none", LogExposures: true})
+	counts := countExposures()
+	if counts[GateExposureEventName] != len(response.FeatureGates) {
+		t.Errorf("Expected %d gate exposures, received %d", len(response.FeatureGates), counts[GateExposureEventName])
+	}
+	if counts[ConfigExposureEventName] != len(response.DynamicConfigs) {
+		t.Errorf("Expected %d config exposures, received %d", len(response.DynamicConfigs), counts[ConfigExposureEventName])
+	}
+	parameters := 0
+	for _, layer := range response.LayerConfigs {
+		parameters += len(layer.Value)
+	}
+	if counts[LayerExposureEventName] != parameters {
+		t.Errorf("Expected one layer exposure per parameter, %d in total, received %d", parameters, counts[LayerExposureEventName])
+	}
+}
+
+func TestClientInitializeResponseLogExposuresUnhashed(t *testing.T) {
+	specs, _ := os.ReadFile("download_config_specs.json")
+	c := newLocalModeClientForTest(t, specs, nil)
+	defer c.Shutdown()
+	user := User{UserID: "123", Email: "testuser@statsig.com"}
+	takeSecondaryExposures := func() map[string]bool {
+		c.logger.mu.Lock()
+		defer c.logger.mu.Unlock()
+		gates := make(map[string]bool)
+		for _, evt := range c.logger.events {
+			exposure, ok := evt.(ExposureEvent)
+			if !ok {
+				continue
+			}
+			if exposure.EventName == LayerExposureEventName && exposure.Metadata["parameterName"] == "" {
+				t.Errorf("Expected layer exposures to name a parameter, received %v", exposure.Metadata)
+			}
+			for _, secondary := range exposure.SecondaryExposures {
+				gates[secondary.Gate] = true
+			}
+		}
+		c.logger.events = c.logger.events[:0]
+		return gates
+	}
+
+	c.GetClientInitializeResponseWithOptions(user, &GCIROptions{HashAlgorithm: "none", LogExposures: true})
+	unhashed := takeSecondaryExposures()
+	c.GetClientInitializeResponseWithOptions(user, &GCIROptions{LogExposures: true})
+	hashed := takeSecondaryExposures()
+	if len(unhashed) == 0 {
+		t.Error("Expected the logged exposures to carry secondary exposures")
+	}
+	if !reflect.DeepEqual(unhashed, hashed) {
+		t.Errorf("Expected sha256 responses to log unhashed secondary exposures %v, received %v", unhashed, hashed)
+	}
+}
+
+func TestClientInitializeResponseLogExposuresEvaluatesOnce(t *testing.T) {
+	specs, _ := os.ReadFile("download_config_specs.json")
+	hashes := int32(0)
+	c := newLocalModeClientForTest(t, specs, &Options{
+		BucketingHasher: func(input string) uint64 {
+			incrementCounter(&hashes)
+			return getHashUint64Encoding(input)
+		},
+	})
+	defer c.Shutdown()
+	user := User{UserID: "123", Email: "testuser@statsig.com"}
+	countHashes := func(options *GCIROptions) int32 {
+		atomic.StoreInt32(&hashes, 0)
+		c.GetClientInitializeResponseWithOptions(user, options)
+		return getCounter(&hashes)
+	}
+
+	baseline := countHashes(&GCIROptions{})
+	if baseline == 0 {
+		t.Fatal("Expected the response to bucket the user")
+	}
+	if logged := countHashes(&GCIROptions{LogExposures: true}); logged != baseline {
+		t.Errorf("Expected logging exposures to reuse the response's evaluations, bucketed %d times instead of %d", logged, baseline)
+	}
+	if unhashed := countHashes(&GCIROptions{HashAlgorithm: "none", LogExposures: true}); unhashed != baseline {
+		t.Errorf("Expected the hash algorithm not to change how often users are bucketed, bucketed %d times instead of %d", unhashed, baseline)
+	}
+}
+
+func TestVerifyHashCompatibility(t *testing.T) {
+	cases := map[string]string{
+		"always_on_gate": "rGc+6rvo48V4j1sXkvsGHeSfJfY7kMp1OHfQnw+3XbI=",
+		"test_config":    "RMv0YJlLOBe7cY7HgZ3Jox34R0Wrk7jLv3DZyBETA7I=",
+	}
+	if err := VerifyHashCompatibility("sha256", cases); err != nil {
+		t.Errorf("Expected sha256 hashes to match, received %s", err)
+	}
+	if err := VerifyHashCompatibility("djb2", cases); err == nil {
+		t.Error("Expected sha256 hashes not to match djb2")
+	}
+	if err := VerifyHashCompatibility("md5", cases); err == nil {
+		t.Error("Expected an unsupported algorithm to be rejected")
+	}
+}
+
+func TestRedactUserInResponse(t *testing.T) {
+	specs, _ := os.ReadFile("download_config_specs.json")
+	c := newLocalModeClientForTest(t, specs, &Options{
+		Environment: Environment{Tier: "staging"},
+	})
+	defer c.Shutdown()
+	user := User{
+		UserID:    "123",
+		Email:     "testuser@statsig.com",
+		IpAddress: "1.2.3.4",
+		CustomIDs: map[string]string{"companyID": "abc"},
+		Custom:    map[string]interface{}{"plan": "enterprise"},
+	}
+
+	expected := c.GetClientInitializeResponseWithOptions(user, &GCIROptions{HashAlgorithm: "none"})
+	redacted := c.GetClientInitializeResponseWithOptions(user, &GCIROptions{HashAlgorithm: "none", RedactUserInResponse: true})
+
+	if !reflect.DeepEqual(redacted.User, User{StatsigEnvironment: map[string]string{"tier": "staging"}}) {
+		t.Errorf("Expected only the environment to remain on the user, received %+v", redacted.User)
+	}
+	if len(redacted.EvaluatedKeys) != 0 {
+		t.Errorf("Expected evaluated keys to be redacted, received %v", redacted.EvaluatedKeys)
+	}
+	if len(redacted.FeatureGates) == 0 || !reflect.DeepEqual(redacted.FeatureGates, expected.FeatureGates) {
+		t.Error("Expected feature gate values to be unaffected by redaction")
+	}
+	if !reflect.DeepEqual(redacted.DynamicConfigs, expected.DynamicConfigs) {
+		t.Error("Expected dynamic config values to be unaffected by redaction")
+	}
+	if expected.User.UserID != "123" || expected.EvaluatedKeys["userID"] != "123" {
+		t.Error("Expected the user to be echoed back without redaction")
+	}
+}
+
+func TestLegacyValueFormat(t *testing.T) {
+	specs, _ := os.ReadFile("download_config_specs.json")
+	c := newLocalModeClientForTest(t, specs, nil)
+	defer c.Shutdown()
+	user := User{UserID: "123", Email: "testuser@statsig.com"}
+
+	decode := func(response ClientInitializeResponse) map[string]interface{} {
+		encoded, _ := json.Marshal(response)
+		decoded := make(map[string]interface{})
+		_ = json.Unmarshal(encoded, &decoded)
+		return decoded
+	}
+	current := decode(c.GetClientInitializeResponseWithOptions(user, &GCIROptions{HashAlgorithm: "none"}))
+	legacy := decode(c.GetClientInitializeResponseWithOptions(user, &GCIROptions{HashAlgorithm: "none", LegacyValueFormat: true}))
+
+	if _, ok := current["gates"]; ok {
+		t.Error("Expected the legacy maps to be omitted by default")
+	}
+	gates, _ := legacy["gates"].(map[string]interface{})
+	if gates["always_on_gate"] != true || gates["on_for_statsig_email"] != true {
+		t.Errorf("Expected legacy gates to map names to their boolean value, received %v", gates)
+	}
+	config := current["dynamic_configs"].(map[string]interface{})["test_config"].(map[string]interface{})
+	expectedConfig := map[string]interface{}{"value": config["value"], "group": config["rule_id"]}
+	configs, _ := legacy["configs"].(map[string]interface{})
+	if !reflect.DeepEqual(configs["test_config"], expectedConfig) {
+		t.Errorf("Expected legacy config %v, received %v", expectedConfig, configs["test_config"])
+	}
+	if !reflect.DeepEqual(legacy["feature_gates"], current["feature_gates"]) {
+		t.Error("Expected the current feature_gates format to be kept alongside the legacy one")
+	}
+}
+
+func TestClientInitializeResponseBytes(t *testing.T) {
+	specs, _ := os.ReadFile("download_config_specs.json")
+	c := newLocalModeClientForTest(t, specs, nil)
+	defer c.Shutdown()
+	user := User{UserID: "123", Email: "testuser@statsig.com", Custom: map[string]interface{}{"level": 3}}
+
+	jsonBytes, err := c.GetClientInitializeResponseBytes(user, &GCIROptions{HashAlgorithm: "none"})
+	if err != nil {
+		t.Fatalf("Expected JSON to be the default format, received %v", err)
+	}
+	packed, err := c.GetClientInitializeResponseBytes(user, &GCIROptions{HashAlgorithm: "none", Format: GCIRFormatMessagePack})
+	if err != nil {
+		t.Fatalf("Expected the MessagePack format to encode, received %v", err)
+	}
+	if len(packed) >= len(jsonBytes) {
+		t.Errorf("Expected the MessagePack encoding (%d bytes) to be smaller than JSON (%d bytes)", len(packed), len(jsonBytes))
+	}
+
+	expected, err := DecodeClientInitializeResponse(jsonBytes, GCIRFormatJSON)
+	if err != nil {
+		t.Fatalf("Expected the JSON response to decode, received %v", err)
+	}
+	decoded, err := DecodeClientInitializeResponse(packed, GCIRFormatMessagePack)
+	if err != nil {
+		t.Fatalf("Expected the MessagePack response to decode, received %v", err)
+	}
+	if !reflect.DeepEqual(decoded, expected) {
+		t.Errorf("Expected the MessagePack response to round trip to %+v, received %+v", expected, decoded)
+	}
+	if len(decoded.FeatureGates) == 0 || len(decoded.DynamicConfigs) == 0 {
+		t.Error("Expected the decoded response to contain gates and configs")
+	}
+
+	if defaults, err := c.GetClientInitializeResponseBytes(user, nil); err != nil || len(defaults) == 0 {
+		t.Errorf("Expected nil options to encode JSON with the defaults, received %v", err)
+	}
+
+	if _, err := DecodeClientInitializeResponse(packed[:len(packed)-1], GCIRFormatMessagePack); err == nil {
+		t.Error("Expected truncated MessagePack to fail to decode")
+	}
+	if _, err := c.GetClientInitializeResponseBytes(user, &GCIROptions{Format: "xml"}); err == nil {
+		t.Error("Expected an unsupported format to return an error")
+	}
+}
+
+func TestHashName(t *testing.T) {
+	expected := map[string]string{
+		"none":   "test_gate",
+		"djb2":   "3114454104",
+		"sha256": "AoZS0F06Ub+W2ONx+94rPTS7MRxuxa+GnXro5Q1uaGY=",
+	}
+	for algorithm, hash := range expected {
+		if actual := HashName(algorithm, "test_gate"); actual != hash {
+			t.Errorf("Expected %s hash of test_gate to be %s, received %s", algorithm, hash, actual)
+		}
+	}
+}
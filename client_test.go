@@ -1,6 +1,12 @@
 package statsig
 
 import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"runtime"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -32,3 +38,238 @@ func TestNormalizeUserDataRace(t *testing.T) {
 	}
 	wg.Wait()
 }
+
+func TestDefaultUser(t *testing.T) {
+	specs, _ := json.Marshal(downloadConfigSpecResponse{
+		HasUpdates: true,
+		Time:       1,
+		FeatureGates: []configSpec{
+			{Name: "us_west_gate", Type: "feature_gate", Enabled: true, Rules: []configRule{{
+				ID:             "us_west",
+				PassPercentage: 100,
+				Conditions: []configCondition{
+					{Type: "user_field", Operator: "any", Field: "region", TargetValue: []interface{}{"us-west"}},
+				},
+			}}},
+		},
+	})
+	c := newLocalModeClientForTest(t, specs, &Options{
+		DefaultUser: User{Custom: map[string]interface{}{"region": "us-west"}},
+	})
+	defer c.Shutdown()
+
+	if !c.CheckGate(User{UserID: "123"}, "us_west_gate") {
+		t.Error("Expected the default user's region to be used when the user omits it")
+	}
+	user := User{UserID: "123", Custom: map[string]interface{}{"region": "eu-central"}}
+	if c.CheckGate(user, "us_west_gate") {
+		t.Error("Expected the user's region to override the default user's region")
+	}
+}
+
+func TestNewSubClient(t *testing.T) {
+	tenantSpecs := func(enabled bool) string {
+		specs, _ := json.Marshal(downloadConfigSpecResponse{
+			HasUpdates: true,
+			Time:       1,
+			FeatureGates: []configSpec{
+				{Name: "tenant_gate", Type: "feature_gate", Enabled: enabled, Rules: []configRule{publicRule("public_rule")}},
+			},
+		})
+		return string(specs)
+	}
+	c := newLocalModeClientForTest(t, nil, nil)
+	defer c.Shutdown()
+
+	enabledTenant := c.NewSubClient(tenantSpecs(true))
+	disabledTenant := c.NewSubClient(tenantSpecs(false))
+	user := User{UserID: "a-user"}
+
+	if !enabledTenant.CheckGate(user, "tenant_gate") {
+		t.Error("Expected the gate to pass with the first tenant's specs")
+	}
+	if disabledTenant.CheckGate(user, "tenant_gate") {
+		t.Error("Expected the gate to fail with the second tenant's specs")
+	}
+	if gate := c.GetGate(user, "tenant_gate"); gate.Source() != SourceUninitialized {
+		t.Errorf("Expected the parent client to be unaffected by sub client specs, received %s", gate.Source())
+	}
+	enabledTenant.OverrideGate("tenant_gate", false)
+	if !c.NewSubClient(tenantSpecs(true)).CheckGate(user, "tenant_gate") {
+		t.Error("Expected overrides to be isolated between sub clients")
+	}
+
+	segmentSpecs, _ := json.Marshal(downloadConfigSpecResponse{
+		HasUpdates: true,
+		Time:       1,
+		FeatureGates: []configSpec{
+			{Name: "segment_gate", Type: "feature_gate", Enabled: true, Rules: []configRule{{
+				ID:             "in_list",
+				PassPercentage: 100,
+				Conditions:     []configCondition{{Type: "unit_id", Operator: "in_segment_list", TargetValue: "tenant_list"}},
+			}}},
+		},
+	})
+	c.evaluator.store.setIDList("tenant_list", &idList{
+		Name: "tenant_list",
+		ids:  idListMapToSyncMap(map[string]bool{getIDListKey("a-user"): true}),
+	})
+	segmentTenant := c.NewSubClient(string(segmentSpecs))
+	if !segmentTenant.CheckGate(user, "segment_gate") {
+		t.Error("Expected the sub client to read the parent's ID lists")
+	}
+	if gate := segmentTenant.GetGate(User{UserID: "b-user"}, "segment_gate"); gate.Value || gate.EvaluationDetails != nil && gate.EvaluationDetails.Reason == ReasonSegmentsNotReady {
+		t.Errorf("Expected users outside the parent's list to fail the gate, received %+v", gate)
+	}
+
+	disabledTenant.Shutdown()
+	c.logger.mu.Lock()
+	buffered := len(c.logger.events)
+	c.logger.mu.Unlock()
+	enabledTenant.CheckGate(user, "tenant_gate")
+	c.logger.mu.Lock()
+	defer c.logger.mu.Unlock()
+	if len(c.logger.events) != buffered+1 {
+		t.Error("Expected sub client exposures on the shared logger after another sub client shut down")
+	}
+}
+
+func countBackgroundFlushers() int {
+	buf := make([]byte, 1<<20)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			return strings.Count(string(buf[:n]), "(*logger).backgroundFlush")
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
+
+func TestReadOnly(t *testing.T) {
+	bytes, _ := os.ReadFile("download_config_specs.json")
+	flushers := countBackgroundFlushers()
+	c := newLocalModeClientForTest(t, bytes, &Options{
+		ReadOnly: true,
+	})
+	defer c.Shutdown()
+
+	if started := countBackgroundFlushers() - flushers; started != 0 {
+		t.Errorf("Expected a read only client not to start a background flusher, %d started", started)
+	}
+
+	user := User{UserID: "a-user"}
+	if !c.CheckGate(user, "always_on_gate") {
+		t.Error("Expected evaluation to work on a read only client")
+	}
+	c.OverrideGate("always_on_gate", false)
+	c.OverrideConfig("test_config", map[string]interface{}{"number": 1})
+	config := c.GetConfig(user, "test_config")
+	if !c.CheckGate(user, "always_on_gate") || config.IsOverridden() {
+		t.Error("Expected overrides to be rejected on a read only client")
+	}
+	c.LogEvent(Event{EventName: "an_event", User: user})
+	if _, err := c.LogImmediate([]Event{{EventName: "an_event", User: user}}); err == nil {
+		t.Error("Expected LogImmediate to be rejected on a read only client")
+	}
+	c.logger.mu.Lock()
+	buffered := len(c.logger.events)
+	c.logger.mu.Unlock()
+	if buffered != 0 {
+		t.Errorf("Expected nothing to be logged on a read only client, %d events buffered", buffered)
+	}
+}
+
+func TestEffectiveOptions(t *testing.T) {
+	specs, _ := os.ReadFile("download_config_specs.json")
+	c := NewClientWithOptions("secret-abcdefghijklmnop", &Options{
+		LocalMode:            true,
+		BootstrapValues:      string(specs),
+		API:                  "https://statsig.example.com/v1",
+		APIOverrides:         APIOverrides{LogEvent: "https://events.example.com/v1"},
+		ConfigSyncInterval:   5 * time.Second,
+		LoggingInterval:      2 * time.Second,
+		OutputLoggerOptions:  getOutputLoggerOptionsForTest(t),
+		StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+	})
+	defer c.Shutdown()
+	_ = c.UpdateRuntimeOptions(RuntimeOptions{IDListSyncInterval: 3 * time.Minute})
+
+	resolved := c.EffectiveOptions()
+	if resolved.SDKKey != "secret-****mnop" {
+		t.Errorf("Expected the SDK key to be redacted, received %s", resolved.SDKKey)
+	}
+	if resolved.ConfigSyncInterval != 5*time.Second || resolved.IDListSyncInterval != 3*time.Minute || resolved.LoggingInterval != 2*time.Second {
+		t.Errorf("Expected the configured and updated intervals, received %v %v %v",
+			resolved.ConfigSyncInterval, resolved.IDListSyncInterval, resolved.LoggingInterval)
+	}
+	if resolved.LoggingMaxBufferSize != 1000 {
+		t.Errorf("Expected the default buffer size, received %d", resolved.LoggingMaxBufferSize)
+	}
+	if resolved.DownloadConfigSpecsAPI != "https://statsig.example.com/v1" || resolved.LogEventAPI != "https://events.example.com/v1" {
+		t.Errorf("Expected the configured endpoints, received %s %s", resolved.DownloadConfigSpecsAPI, resolved.LogEventAPI)
+	}
+	if resolved.Source != SourceBootstrap || !resolved.BootstrapValuesProvided || resolved.DataAdapterEnabled {
+		t.Errorf("Expected bootstrap to be the active source, received %+v", resolved)
+	}
+}
+
+func TestReadOnlyStorageWrites(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.WriteHeader(http.StatusOK)
+		if strings.Contains(req.URL.Path, "download_config_specs") {
+			bytes, _ := os.ReadFile("download_config_specs.json")
+			_, _ = res.Write(bytes)
+		} else if strings.Contains(req.URL.Path, "get_id_lists") {
+			v, _ := json.Marshal(map[string]idList{
+				"list_1": {Name: "list_1", Size: 20, URL: "http://" + req.Host + "/list_1", CreationTime: 1, FileID: "123"},
+			})
+			_, _ = res.Write(v)
+		} else if strings.Contains(req.URL.Path, "list_1") {
+			_, _ = res.Write([]byte("+ungWv48B\n+Ngi8oeRO\n"))
+		}
+	}))
+	defer testServer.Close()
+	adapter := &countingDataAdapter{dataAdapterExample: dataAdapterExample{store: make(map[string]string)}}
+	c := NewClientWithOptions("secret-key", &Options{
+		API:                  testServer.URL,
+		ReadOnly:             true,
+		DataAdapter:          adapter,
+		OutputLoggerOptions:  getOutputLoggerOptionsForTest(t),
+		StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+	})
+	if !c.CheckGate(User{UserID: "a-user"}, "always_on_gate") {
+		t.Error("Expected a read only client to evaluate specs fetched from the network")
+	}
+	c.Shutdown()
+	if sets := getCounter(&adapter.sets); sets != 0 {
+		t.Errorf("Expected a read only client not to write to the data adapter, received %d sets", sets)
+	}
+
+	bytes, _ := os.ReadFile("download_config_specs_sticky_experiments.json")
+	storage := &userPersistentStorageExample{store: make(map[string]UserPersistedValues)}
+	c = newLocalModeClientForTest(t, bytes, &Options{
+		ReadOnly:              true,
+		UserPersistentStorage: storage,
+	})
+	defer c.Shutdown()
+	user := User{UserID: "vj"}
+	c.GetExperiment(user, "the_allocated_experiment")
+	persistedValues := c.GetUserPersistedValues(user, "userID")
+	c.GetExperimentWithOptions(user, "the_allocated_experiment", &GetExperimentOptions{PersistedValues: persistedValues})
+	if storage.loadCalled == 0 {
+		t.Error("Expected a read only client to still load persisted values")
+	}
+	if storage.saveCalled != 0 || storage.deleteCalled != 0 {
+		t.Errorf("Expected a read only client not to write persisted values, received %d saves and %d deletes", storage.saveCalled, storage.deleteCalled)
+	}
+}
+
+type countingDataAdapter struct {
+	dataAdapterExample
+	sets int32
+}
+
+func (d *countingDataAdapter) Set(key string, value string) {
+	incrementCounter(&d.sets)
+	d.dataAdapterExample.Set(key, value)
+}
@@ -18,6 +18,7 @@ import (
 
 func TestCallingAPIsConcurrently(t *testing.T) {
 	flushedEventCount := int32(0)
+	largestBatch := int32(0)
 	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
 		res.WriteHeader(http.StatusOK)
 		if strings.Contains(req.URL.Path, "download_config_specs") {
@@ -42,6 +43,12 @@ func TestCallingAPIsConcurrently(t *testing.T) {
 				_ = json.Unmarshal(buf.Bytes(), &input)
 			}
 			atomic.AddInt32(&flushedEventCount, int32(len(input.Events)))
+			for {
+				largest := atomic.LoadInt32(&largestBatch)
+				if int32(len(input.Events)) <= largest || atomic.CompareAndSwapInt32(&largestBatch, largest, int32(len(input.Events))) {
+					break
+				}
+			}
 		} else if strings.Contains(req.URL.Path, "get_id_lists") {
 			baseURL := "http://" + req.Host
 			r := map[string]idList{
@@ -123,10 +130,12 @@ func TestCallingAPIsConcurrently(t *testing.T) {
 
 	// 10 go routines x 10 loops each x 9 events (4 log event + 7 exposure events) = 1100 total events should have been logged.
 
-	// only 100 should still be in the logger now because the first 1000 would have been cut and triggered a flush
-	if len(instance.logger.events) != 100 {
-		t.Error("Incorrect number of events batched in the logger")
-	}
+	// reaching 1000 events signals the background flusher, which leaves fewer than 1000 in the logger
+	waitForConditionWithMessage(t, func() bool {
+		instance.logger.mu.Lock()
+		defer instance.logger.mu.Unlock()
+		return len(instance.logger.events) < 1000
+	}, "Incorrect number of events batched in the logger")
 
 	ShutdownAndDangerouslyClearInstance()
 
@@ -134,6 +143,11 @@ func TestCallingAPIsConcurrently(t *testing.T) {
 	waitForConditionWithMessage(t, func() bool {
 		return atomic.LoadInt32(&flushedEventCount) == 1100
 	}, "Not all events were flushed eventually")
+
+	// the buffer may grow past 1000 before the flusher runs, but each request is capped at the max buffer size
+	if largest := atomic.LoadInt32(&largestBatch); largest != 1000 {
+		t.Errorf("Expected the largest log_event batch to hold 1000 events, got %d", largest)
+	}
 }
 
 func TestUpdatingRulesAndFetchingValuesConcurrently(t *testing.T) {
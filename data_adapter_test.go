@@ -268,12 +268,17 @@ func swallow_stderr(task func()) string {
 	stderr := os.Stderr
 	r, w, _ := os.Pipe()
 	os.Stderr = w
+	// drained while the task runs, so a task writing more than the pipe buffer doesn't block
+	output := make(chan string)
+	go func() {
+		var buf bytes.Buffer
+		_, _ = io.Copy(&buf, r)
+		output <- buf.String()
+	}()
 	task()
 	w.Close()
-	var buf bytes.Buffer
-	_, _ = io.Copy(&buf, r)
 	os.Stderr = stderr
-	return buf.String()
+	return <-output
 }
 
 func contains_spec(specs []configSpec, name string, specType string) bool {
@@ -284,3 +289,43 @@ func contains_spec(specs []configSpec, name string, specType string) bool {
 	}
 	return false
 }
+
+func TestInitializationSourcePriority(t *testing.T) {
+	dcs_bytes, _ := os.ReadFile("download_config_specs.json")
+	testServer := getTestServer(testServerOptions{})
+	defer testServer.Close()
+	dataAdapter := dataAdapterExample{store: make(map[string]string)}
+	dataAdapter.Initialize()
+	defer dataAdapter.Shutdown()
+	dataAdapter.Set(CONFIG_SPECS_KEY, string(dcs_bytes))
+
+	newOptions := func(priority []DataSource) *Options {
+		return &Options{
+			DataAdapter:                  &dataAdapter,
+			BootstrapValues:              string(dcs_bytes),
+			InitializationSourcePriority: priority,
+			API:                          testServer.URL,
+			OutputLoggerOptions:          getOutputLoggerOptionsForTest(t),
+			StatsigLoggerOptions:         getStatsigLoggerOptionsForTest(t),
+		}
+	}
+
+	t.Run("defaults to the adapter over bootstrap values", func(t *testing.T) {
+		details := InitializeWithOptions("secret-key", newOptions(nil))
+		defer ShutdownAndDangerouslyClearInstance()
+		if details.Source != SourceDataAdapter {
+			t.Errorf("Expected init source to be %s, received %s", SourceDataAdapter, details.Source)
+		}
+	})
+
+	t.Run("uses bootstrap values when prioritized over the adapter", func(t *testing.T) {
+		details := InitializeWithOptions("secret-key", newOptions([]DataSource{BootstrapDataSource, AdapterDataSource, NetworkDataSource}))
+		defer ShutdownAndDangerouslyClearInstance()
+		if details.Source != SourceBootstrap {
+			t.Errorf("Expected init source to be %s, received %s", SourceBootstrap, details.Source)
+		}
+		if !CheckGate(User{UserID: "123"}, "always_on_gate") {
+			t.Errorf("Expected gate to return true")
+		}
+	})
+}
@@ -0,0 +1,103 @@
+package statsig
+
+import (
+	"sort"
+	"strings"
+)
+
+// A gate or config and everything its rules reference through pass_gate/fail_gate conditions and config delegates
+type DependencyTree struct {
+	Name         string
+	Type         string // "gate" or "config"
+	Missing      bool   // Referenced but not present in the loaded specs
+	Cycle        bool   // Already an ancestor in the tree, so its dependencies are not expanded again
+	Dependencies []DependencyTree
+}
+
+// Statically builds the dependency tree of a gate from the loaded specs, independent of any user
+func (s *store) getGateDependencyTree(gate string) DependencyTree {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.buildDependencyTree(gate, "gate", make(map[string]bool))
+}
+
+func (s *store) buildDependencyTree(name string, specType string, ancestors map[string]bool) DependencyTree {
+	tree := DependencyTree{Name: name, Type: specType, Dependencies: make([]DependencyTree, 0)}
+	key := specType + ":" + name
+	if ancestors[key] {
+		tree.Cycle = true
+		return tree
+	}
+	var spec configSpec
+	var exists bool
+	if specType == "gate" {
+		spec, exists = s.featureGates[name]
+	} else {
+		spec, exists = s.dynamicConfigs[name]
+	}
+	if !exists {
+		tree.Missing = true
+		return tree
+	}
+
+	ancestors[key] = true
+	defer delete(ancestors, key)
+	seen := make(map[string]bool)
+	addDependency := func(depName string, depType string) {
+		if seen[depType+":"+depName] {
+			return
+		}
+		seen[depType+":"+depName] = true
+		tree.Dependencies = append(tree.Dependencies, s.buildDependencyTree(depName, depType, ancestors))
+	}
+	for _, rule := range spec.Rules {
+		for _, cond := range rule.Conditions {
+			if !strings.EqualFold(cond.Type, "pass_gate") && !strings.EqualFold(cond.Type, "fail_gate") {
+				continue
+			}
+			if gateName, ok := cond.TargetValue.(string); ok {
+				addDependency(gateName, "gate")
+			}
+		}
+		if rule.ConfigDelegate != "" {
+			addDependency(rule.ConfigDelegate, "config")
+		}
+	}
+	return tree
+}
+
+// Statically collects the ID lists referenced by segment conditions of a gate and of every gate it depends on, sorted by name
+func (s *store) getGateIDListDependencies(gate string) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	lists := make(map[string]bool)
+	s.collectIDListDependencies(gate, make(map[string]bool), lists)
+	names := make([]string, 0, len(lists))
+	for name := range lists {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (s *store) collectIDListDependencies(gate string, visited map[string]bool, lists map[string]bool) {
+	spec, exists := s.featureGates[gate]
+	if !exists || visited[gate] {
+		return
+	}
+	visited[gate] = true
+	for _, rule := range spec.Rules {
+		for _, cond := range rule.Conditions {
+			if strings.EqualFold(cond.Operator, "in_segment_list") || strings.EqualFold(cond.Operator, "not_in_segment_list") {
+				if listName, ok := cond.TargetValue.(string); ok {
+					lists[listName] = true
+				}
+			}
+			if strings.EqualFold(cond.Type, "pass_gate") || strings.EqualFold(cond.Type, "fail_gate") {
+				if gateName, ok := cond.TargetValue.(string); ok {
+					s.collectIDListDependencies(gateName, visited, lists)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package statsig
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func gateWithConditions(name string, conditions ...configCondition) configSpec {
+	return configSpec{Name: name, Type: "feature_gate", Enabled: true, Rules: []configRule{{
+		ID:             name + "_rule",
+		PassPercentage: 100,
+		Conditions:     conditions,
+	}}}
+}
+
+func TestGateDependencyTree(t *testing.T) {
+	specs, _ := json.Marshal(downloadConfigSpecResponse{
+		HasUpdates: true,
+		Time:       1,
+		FeatureGates: []configSpec{
+			gateWithConditions("root_gate",
+				configCondition{Type: "pass_gate", TargetValue: "child_gate"},
+				configCondition{Type: "fail_gate", TargetValue: "leaf_gate"},
+			),
+			gateWithConditions("child_gate",
+				configCondition{Type: "pass_gate", TargetValue: "grandchild_gate"},
+			),
+			gateWithConditions("leaf_gate", configCondition{Type: "public"}),
+			gateWithConditions("grandchild_gate",
+				configCondition{Type: "pass_gate", TargetValue: "root_gate"},
+			),
+		},
+	})
+	c := newLocalModeClientForTest(t, specs, nil)
+	defer c.Shutdown()
+
+	tree := c.GateDependencyTree("root_gate")
+	if tree.Name != "root_gate" || len(tree.Dependencies) != 2 {
+		t.Fatalf("Expected root_gate with 2 dependencies, received %+v", tree)
+	}
+	child, leaf := tree.Dependencies[0], tree.Dependencies[1]
+	if child.Name != "child_gate" || len(child.Dependencies) != 1 {
+		t.Fatalf("Expected child_gate with 1 dependency, received %+v", child)
+	}
+	if leaf.Name != "leaf_gate" || len(leaf.Dependencies) != 0 {
+		t.Errorf("Expected leaf_gate with no dependencies, received %+v", leaf)
+	}
+	grandchild := child.Dependencies[0]
+	if grandchild.Name != "grandchild_gate" || len(grandchild.Dependencies) != 1 {
+		t.Fatalf("Expected grandchild_gate with 1 dependency, received %+v", grandchild)
+	}
+	if cycle := grandchild.Dependencies[0]; cycle.Name != "root_gate" || !cycle.Cycle || len(cycle.Dependencies) != 0 {
+		t.Errorf("Expected the reference back to root_gate to be marked as a cycle, received %+v", cycle)
+	}
+
+	missing := c.GateDependencyTree("not_a_gate")
+	if !missing.Missing {
+		t.Errorf("Expected an unknown gate to be marked missing, received %+v", missing)
+	}
+}
+
+func TestGateIDListDependencies(t *testing.T) {
+	specs, _ := json.Marshal(downloadConfigSpecResponse{
+		HasUpdates: true,
+		Time:       1,
+		FeatureGates: []configSpec{
+			gateWithConditions("segmented_gate",
+				configCondition{Type: "unit_id", Operator: "in_segment_list", TargetValue: "beta_users"},
+				configCondition{Type: "pass_gate", TargetValue: "blocklist_gate"},
+			),
+			gateWithConditions("blocklist_gate",
+				configCondition{Type: "unit_id", Operator: "not_in_segment_list", TargetValue: "blocked_users"},
+				configCondition{Type: "pass_gate", TargetValue: "segmented_gate"},
+			),
+			gateWithConditions("public_gate", configCondition{Type: "public"}),
+		},
+	})
+	c := newLocalModeClientForTest(t, specs, nil)
+	defer c.Shutdown()
+
+	if lists := c.GateIDListDependencies("segmented_gate"); !reflect.DeepEqual(lists, []string{"beta_users", "blocked_users"}) {
+		t.Errorf("Expected direct and dependent gate ID lists, received %v", lists)
+	}
+	if lists := c.GateIDListDependencies("public_gate"); len(lists) != 0 {
+		t.Errorf("Expected no ID lists for a public gate, received %v", lists)
+	}
+	if lists := c.GateIDListDependencies("missing_gate"); len(lists) != 0 {
+		t.Errorf("Expected no ID lists for a missing gate, received %v", lists)
+	}
+}
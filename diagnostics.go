@@ -82,6 +82,19 @@ type tags struct {
 	Reason      *string `json:"reason,omitempty"`
 }
 
+// A point in a step of initialization, as recorded for diagnostics. A start and an end marker with the same Key
+// and Step bound that step, e.g. the download_config_specs network_request, or the process step that parses it
+type DiagnosticMarker struct {
+	Key        DiagnosticsKey
+	Step       DiagnosticsStep // Empty for the overall markers
+	Action     DiagnosticsAction
+	Time       time.Time
+	Success    *bool  // Set on end markers
+	StatusCode *int   // Set on the end markers of network requests that got a response
+	Name       string // Name of the ID list, for get_id_list markers
+	Reason     string // Why the step ended, e.g. "timeout"
+}
+
 var DEFAULT_SAMPLING_RATES = map[string]int{
 	"initialize":  10000,
 	"config_sync": 0,
@@ -89,24 +102,25 @@ var DEFAULT_SAMPLING_RATES = map[string]int{
 }
 
 func newDiagnostics(options *Options) *diagnostics {
+	samplingRates := withSamplingOverride(DEFAULT_SAMPLING_RATES, options.DiagnosticsSamplingOverride)
 	return &diagnostics{
 		initDiagnostics: &diagnosticsBase{
 			context:       InitializeContext,
 			markers:       make([]marker, 0),
 			options:       options,
-			samplingRates: DEFAULT_SAMPLING_RATES,
+			samplingRates: samplingRates,
 		},
 		syncDiagnostics: &diagnosticsBase{
 			context:       ConfigSyncContext,
 			markers:       make([]marker, 0),
 			options:       options,
-			samplingRates: DEFAULT_SAMPLING_RATES,
+			samplingRates: samplingRates,
 		},
 		apiDiagnostics: &diagnosticsBase{
 			context:       ApiCallContext,
 			markers:       make([]marker, 0),
 			options:       options,
-			samplingRates: DEFAULT_SAMPLING_RATES,
+			samplingRates: samplingRates,
 		},
 	}
 }
@@ -144,7 +158,22 @@ func (d *diagnosticsBase) serializeWithSampling() (map[string]interface{}, bool)
 func (d *diagnosticsBase) updateSamplingRates(samplingRates map[string]int) {
 	d.mu.Lock()
 	defer d.mu.Unlock()
-	d.samplingRates = samplingRates
+	d.samplingRates = withSamplingOverride(samplingRates, d.options.DiagnosticsSamplingOverride)
+}
+
+// Applies Options.DiagnosticsSamplingOverride on top of the given rates, which are left unmodified
+func withSamplingOverride(samplingRates map[string]int, override map[string]int) map[string]int {
+	if len(override) == 0 {
+		return samplingRates
+	}
+	merged := make(map[string]int, len(samplingRates)+len(override))
+	for context, rate := range samplingRates {
+		merged[context] = rate
+	}
+	for context, rate := range override {
+		merged[context] = rate
+	}
+	return merged
 }
 
 func sample(rate_over_ten_thousand int) bool {
@@ -152,6 +181,37 @@ func sample(rate_over_ten_thousand int) bool {
 	return int(math.Floor(rand.Float64()*10_000)) < rate_over_ten_thousand
 }
 
+// Gets the markers recorded so far in their public shape. Empty when the context is disabled through StatsigLoggerOptions
+func (d *diagnosticsBase) getMarkers() []DiagnosticMarker {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	markers := make([]DiagnosticMarker, 0, len(d.markers))
+	for _, m := range d.markers {
+		marker := DiagnosticMarker{
+			Time:       time.Unix(0, m.Timestamp*int64(time.Millisecond)),
+			Success:    m.Success,
+			StatusCode: m.StatusCode,
+		}
+		if m.Key != nil {
+			marker.Key = *m.Key
+		}
+		if m.Step != nil {
+			marker.Step = *m.Step
+		}
+		if m.Action != nil {
+			marker.Action = *m.Action
+		}
+		if m.Name != nil {
+			marker.Name = *m.Name
+		}
+		if m.Reason != nil {
+			marker.Reason = *m.Reason
+		}
+		markers = append(markers, marker)
+	}
+	return markers
+}
+
 func (d *diagnosticsBase) clearMarkers() {
 	d.mu.Lock()
 	defer d.mu.Unlock()
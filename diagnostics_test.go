@@ -372,6 +372,58 @@ func TestDiagnosticsSampling(t *testing.T) {
 	}
 }
 
+func TestDiagnosticsSamplingOverride(t *testing.T) {
+	var events events
+	var mu sync.RWMutex
+
+	testServer := getTestServer(testServerOptions{
+		onLogEvent: func(newEvents []map[string]interface{}) {
+			mu.Lock()
+			events = append(events, newEvents...)
+			mu.Unlock()
+		},
+		withSampling: true,
+	})
+	defer testServer.Close()
+
+	options := &Options{
+		API:                         testServer.URL,
+		Environment:                 Environment{Tier: "test"},
+		OutputLoggerOptions:         getOutputLoggerOptionsForTest(t),
+		DiagnosticsSamplingOverride: map[string]int{"config_sync": 10000},
+		ConfigSyncInterval:          time.Millisecond * 99999,
+		IDListSyncInterval:          time.Millisecond * 99999,
+		LoggingInterval:             time.Millisecond * 99999,
+	}
+	InitializeWithOptions("secret-key", options)
+	defer ShutdownAndDangerouslyClearInstance()
+
+	// Every sync applies the server's 50% config_sync rate, which the override should replace
+	for i := 1; i <= 10; i++ {
+		instance.evaluator.store.fetchConfigSpecsFromServer(nil)
+		instance.logger.flush(false)
+	}
+	countSyncEvents := func() int {
+		mu.RLock()
+		defer mu.RUnlock()
+		count := 0
+		for _, event := range events {
+			if metadata, ok := event["metadata"].(map[string]interface{}); ok && metadata["context"] == string(ConfigSyncContext) {
+				count++
+			}
+		}
+		return count
+	}
+	// Flushes send in the background, so wait for them to arrive
+	syncEvents := countSyncEvents()
+	for start := time.Now(); syncEvents < 10 && time.Since(start) < 2*time.Second; syncEvents = countSyncEvents() {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if syncEvents != 10 {
+		t.Errorf("Expected every config sync to log diagnostics, received %d of 10", syncEvents)
+	}
+}
+
 func TestDiagnosticsClearMarkers(t *testing.T) {
 	var events events
 	testServer := getTestServer(
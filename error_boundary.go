@@ -37,9 +37,12 @@ var ErrorBoundaryAPI = "https://statsigapi.net/v1"
 var ErrorBoundaryEndpoint = "/sdk_exception"
 
 const (
-	InvalidSDKKeyError  string = "Must provide a valid SDK key."
-	EmptyUserError      string = "A non-empty StatsigUser.UserID or StatsigUser.CustomIDs is required. See https://docs.statsig.com/messages/serverRequiredUserID"
-	EventBatchSizeError string = "The max number of events supported in one batch is 500. Please reduce the slice size and try again."
+	InvalidSDKKeyError         string = "Must provide a valid SDK key."
+	EmptyUserError             string = "A non-empty StatsigUser.UserID or StatsigUser.CustomIDs is required. See https://docs.statsig.com/messages/serverRequiredUserID"
+	EventBatchSizeError        string = "The max number of events supported in one batch is 500. Please reduce the slice size and try again."
+	InvalidRuntimeOptionsError string = "RuntimeOptions intervals must not be negative."
+	ClientShutdownError        string = "Cannot update a client after Shutdown() has been called."
+	ReadOnlyClientError        string = "Overrides cannot be changed and events cannot be logged on a ReadOnly client."
 )
 
 func newErrorBoundary(sdkKey string, options *Options, diagnostics *diagnostics) *errorBoundary {
@@ -146,6 +149,69 @@ func (e *errorBoundary) captureGetExperimentLayer(
 	return val, ok
 }
 
+func (e *errorBoundary) captureGetExperimentLayerInfo(
+	task func(context *evalContext) ExperimentLayerInfo,
+	context *evalContext,
+) ExperimentLayerInfo {
+	errorContext := &errorContext{evalContext: context, Caller: context.Caller}
+	defer e.ebRecover(func() {}, errorContext)
+	return task(context)
+}
+
+func (e *errorBoundary) captureValidateSpecs(
+	task func(context *evalContext) []SpecWarning,
+	context *evalContext,
+) []SpecWarning {
+	errorContext := &errorContext{evalContext: context, Caller: context.Caller}
+	defer e.ebRecover(func() {}, errorContext)
+	return task(context)
+}
+
+func (e *errorBoundary) captureGetConfigSpecSummary(
+	task func(context *evalContext) *SpecSummary,
+	context *evalContext,
+) *SpecSummary {
+	errorContext := &errorContext{evalContext: context, Caller: context.Caller}
+	defer e.ebRecover(func() {}, errorContext)
+	return task(context)
+}
+
+func (e *errorBoundary) captureWhyNot(
+	task func(context *evalContext) []string,
+	context *evalContext,
+) []string {
+	errorContext := &errorContext{evalContext: context, Caller: context.Caller}
+	defer e.ebRecover(func() {}, errorContext)
+	return task(context)
+}
+
+func (e *errorBoundary) captureGateDependencyTree(
+	task func(context *evalContext) DependencyTree,
+	context *evalContext,
+) DependencyTree {
+	errorContext := &errorContext{evalContext: context, Caller: context.Caller}
+	defer e.ebRecover(func() {}, errorContext)
+	return task(context)
+}
+
+func (e *errorBoundary) captureExportIDLists(
+	task func(context *evalContext) map[string][]string,
+	context *evalContext,
+) map[string][]string {
+	errorContext := &errorContext{evalContext: context, Caller: context.Caller}
+	defer e.ebRecover(func() {}, errorContext)
+	return task(context)
+}
+
+func (e *errorBoundary) captureGateIDListDependencies(
+	task func(context *evalContext) []string,
+	context *evalContext,
+) []string {
+	errorContext := &errorContext{evalContext: context, Caller: context.Caller}
+	defer e.ebRecover(func() {}, errorContext)
+	return task(context)
+}
+
 func (e *errorBoundary) ebRecover(recoverCallback func(), context *errorContext) {
 	if err := recover(); err != nil {
 		e.logExceptionWithContext(toError(err), *context)
@@ -155,7 +221,7 @@ func (e *errorBoundary) ebRecover(recoverCallback func(), context *errorContext)
 }
 
 func (e *errorBoundary) logExceptionWithContext(exception error, context errorContext) {
-	if e.options.StatsigLoggerOptions.DisableAllLogging || e.options.LocalMode {
+	if e.options.StatsigLoggerOptions.DisableAllLogging || e.options.LocalMode || e.options.ReadOnly {
 		return
 	}
 	var exceptionString string
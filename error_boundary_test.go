@@ -83,3 +83,67 @@ func TestRepeatedError(t *testing.T) {
 		t.Error("Expected sdk_exception endpoint to NOT be hit")
 	}
 }
+
+func TestDefaultGateValuesOnError(t *testing.T) {
+	recursiveRule := func(gate string) []configRule {
+		return []configRule{{
+			ID:             "recursive",
+			PassPercentage: 100,
+			Conditions:     []configCondition{{Type: "pass_gate", TargetValue: gate}},
+		}}
+	}
+	specs, _ := json.Marshal(downloadConfigSpecResponse{
+		HasUpdates: true,
+		Time:       1,
+		FeatureGates: []configSpec{
+			{Name: "critical_gate", Type: "feature_gate", Enabled: true, Rules: recursiveRule("critical_gate")},
+			{Name: "other_gate", Type: "feature_gate", Enabled: true, Rules: recursiveRule("other_gate")},
+		},
+	})
+	c := newLocalModeClientForTest(t, specs, &Options{
+		DefaultGateValuesOnError: map[string]bool{"critical_gate": true},
+	})
+	defer c.Shutdown()
+	user := User{UserID: "123"}
+
+	if !c.CheckGate(user, "critical_gate") {
+		t.Error("Expected critical_gate to return its configured default on evaluation error")
+	}
+	if !c.GetGate(user, "critical_gate").Value {
+		t.Error("Expected GetGate to return the configured default on evaluation error")
+	}
+	if c.CheckGate(user, "other_gate") {
+		t.Error("Expected unlisted gate to return false on evaluation error")
+	}
+}
+
+func TestDefaultGateValuesOnErrorCaseInsensitive(t *testing.T) {
+	specs, _ := json.Marshal(downloadConfigSpecResponse{
+		HasUpdates: true,
+		Time:       1,
+		FeatureGates: []configSpec{{
+			Name:    "critical_gate",
+			Type:    "feature_gate",
+			Enabled: true,
+			Rules: []configRule{{
+				ID:             "recursive",
+				PassPercentage: 100,
+				Conditions:     []configCondition{{Type: "pass_gate", TargetValue: "critical_gate"}},
+			}},
+		}},
+	})
+	c := newLocalModeClientForTest(t, specs, &Options{
+		DefaultGateValuesOnError:   map[string]bool{"Critical_Gate": true},
+		CaseInsensitiveConfigNames: true,
+	})
+	defer c.Shutdown()
+	user := User{UserID: "123"}
+
+	gate := c.GetGate(user, "CRITICAL_GATE")
+	if !gate.Value {
+		t.Error("Expected a mixed-case lookup to return the configured default on evaluation error")
+	}
+	if gate.Name != "critical_gate" {
+		t.Errorf("Expected the default gate to carry the spec name, got %s", gate.Name)
+	}
+}
@@ -12,6 +12,10 @@ var (
 	ErrNetworkRequest StatsigError = errors.New("failed network request")
 	ErrFailedLogEvent StatsigError = errors.New("failed to log events")
 	ErrDataAdapter    StatsigError = errors.New("failed data adapter")
+	ErrSDKKeyMismatch StatsigError = errors.New("sdk key mismatch")
+	ErrEmptyConfig    StatsigError = errors.New("config has no value")
+	ErrFlushTimeout   StatsigError = errors.New("timed out waiting for events to flush")
+	ErrEventQueueFull StatsigError = errors.New("event queue full, dropped an event")
 )
 
 type RequestMetadata struct {
@@ -20,9 +24,21 @@ type RequestMetadata struct {
 	Retries    int
 }
 
+// How a failed request failed, deciding whether it is retried. Set on TransportError.Class
+type NetworkErrorClass string
+
+const (
+	NetworkErrorRetryable   NetworkErrorClass = "retryable"   // Timeouts and statuses like 408, 500, 502, 503 and 504. Retried
+	NetworkErrorAuth        NetworkErrorClass = "auth"        // 401 and 403, usually an invalid SDK key. Not retried
+	NetworkErrorBadRequest  NetworkErrorClass = "bad_request" // Any other 4xx status. Not retried
+	NetworkErrorUnreachable NetworkErrorClass = "unreachable" // The request failed without a response, other than by timing out. Not retried
+	NetworkErrorOther       NetworkErrorClass = "other"       // Any other status. Not retried
+)
+
 type TransportError struct {
 	RequestMetadata *RequestMetadata
 	Err             error
+	Class           NetworkErrorClass // Empty when the request could not be built or sent
 }
 
 func (e *TransportError) Error() string {
@@ -37,6 +53,16 @@ func (e *TransportError) Unwrap() error { return e.Err }
 
 func (e *TransportError) Is(target error) bool { return target == ErrNetworkRequest }
 
+// Gets the class of a failed request from an error such as the one returned by LastSyncError.
+// Returns an empty class when the error did not come from a request
+func ClassifyNetworkError(err error) NetworkErrorClass {
+	var transportError *TransportError
+	if errors.As(err, &transportError) {
+		return transportError.Class
+	}
+	return ""
+}
+
 type LogEventError struct {
 	Err    error
 	Events int
@@ -54,6 +80,17 @@ func (e *LogEventError) Unwrap() error { return e.Err }
 
 func (e *LogEventError) Is(target error) bool { return target == ErrFailedLogEvent }
 
+// Reported in place of ErrEventQueueFull, counting the events Options.EventQueueFullPolicy dropped since the last report
+type EventsDroppedError struct {
+	Events int
+}
+
+func (e *EventsDroppedError) Error() string {
+	return fmt.Sprintf("Event queue full, dropped %d events since the last report", e.Events)
+}
+
+func (e *EventsDroppedError) Is(target error) bool { return target == ErrEventQueueFull }
+
 type DataAdapterError struct {
 	Err    error
 	Method string
@@ -70,3 +107,15 @@ func (e *DataAdapterError) Error() string {
 func (e *DataAdapterError) Unwrap() error { return e.Err }
 
 func (e *DataAdapterError) Is(target error) bool { return target == ErrDataAdapter }
+
+// Config specs generated for a different server secret key than the one the SDK was initialized with
+type SDKKeyMismatchError struct {
+	Expected string
+	Received string
+}
+
+func (e *SDKKeyMismatchError) Error() string {
+	return fmt.Sprintf("SDK key mismatch. Key used to generate response does not match key provided. Expected %s, got %s", e.Expected, e.Received)
+}
+
+func (e *SDKKeyMismatchError) Is(target error) bool { return target == ErrSDKKeyMismatch }
@@ -1,6 +1,7 @@
 package statsig
 
 import (
+	"encoding/json"
 	"testing"
 )
 
@@ -227,3 +228,64 @@ func TestExposureCallback(t *testing.T) {
 		}
 	})
 }
+
+func TestRuleEvaluationHook(t *testing.T) {
+	specs, _ := json.Marshal(downloadConfigSpecResponse{
+		HasUpdates: true,
+		Time:       1,
+		FeatureGates: []configSpec{
+			{Name: "regional_gate", Type: "feature_gate", Enabled: true, Rules: []configRule{
+				{
+					ID:             "us_rule",
+					PassPercentage: 100,
+					Conditions: []configCondition{
+						{Type: "user_field", Field: "country", Operator: "any", TargetValue: []interface{}{"US"}},
+					},
+				},
+				{
+					ID:             "ca_rule",
+					PassPercentage: 100,
+					Conditions: []configCondition{
+						{Type: "user_field", Field: "country", Operator: "any", TargetValue: []interface{}{"CA"}},
+					},
+				},
+			}},
+		},
+	})
+
+	type hookCall struct {
+		configName string
+		ruleID     string
+		passed     bool
+		userID     string
+	}
+	var calls []hookCall
+	c := newLocalModeClientForTest(t, specs, &Options{
+		RuleEvaluationHook: func(configName, ruleID string, passed bool, user User) {
+			calls = append(calls, hookCall{configName, ruleID, passed, user.UserID})
+		},
+	})
+	defer c.Shutdown()
+
+	if !c.CheckGate(User{UserID: "a-user", Country: "CA"}, "regional_gate") {
+		t.Fatal("Expected regional_gate to pass for a CA user")
+	}
+	expected := hookCall{"regional_gate", "ca_rule", true, "a-user"}
+	if len(calls) != 1 || calls[0] != expected {
+		t.Errorf("Expected a single hook call %v, received %v", expected, calls)
+	}
+
+	calls = nil
+	c.CheckGate(User{UserID: "a-user", Country: "MX"}, "regional_gate")
+	expected = hookCall{"regional_gate", "default", false, "a-user"}
+	if len(calls) != 1 || calls[0] != expected {
+		t.Errorf("Expected a single hook call %v, received %v", expected, calls)
+	}
+
+	c.options.RuleEvaluationHook = func(configName, ruleID string, passed bool, user User) {
+		panic("hook failure")
+	}
+	if !c.CheckGate(User{UserID: "a-user", Country: "US"}, "regional_gate") {
+		t.Error("Expected a panicking hook not to affect the gate result")
+	}
+}
@@ -10,15 +10,21 @@ const (
 	SourceNetworkNotModified EvaluationSource = "NetworkNotModified"
 	SourceBootstrap          EvaluationSource = "Bootstrap"
 	SourceDataAdapter        EvaluationSource = "DataAdapter"
+	SourceLocalOverride      EvaluationSource = "LocalOverride"
 )
 
 type EvaluationReason string
 
 const (
-	ReasonNone          EvaluationReason = "None"
-	ReasonLocalOverride EvaluationReason = "LocalOverride"
-	ReasonUnrecognized  EvaluationReason = "Unrecognized"
-	ReasonPersisted     EvaluationReason = "Persisted"
+	ReasonNone             EvaluationReason = "None"
+	ReasonLocalOverride    EvaluationReason = "LocalOverride"
+	ReasonUnrecognized     EvaluationReason = "Unrecognized"
+	ReasonPersisted        EvaluationReason = "Persisted"
+	ReasonSegmentsNotReady EvaluationReason = "SegmentsNotReady"
+	ReasonForcedDefault    EvaluationReason = "ForcedDefault"
+	ReasonTimeout          EvaluationReason = "Timeout"
+	ReasonCanceled         EvaluationReason = "Canceled"
+	ReasonError            EvaluationReason = "Error"
 )
 
 type EvaluationDetails struct {
@@ -37,6 +43,17 @@ func (d EvaluationDetails) detailedReason() string {
 	}
 }
 
+// Local overrides report SourceLocalOverride rather than the source of the specs they shadow
+func (d *EvaluationDetails) provenance() EvaluationSource {
+	if d == nil {
+		return SourceUninitialized
+	}
+	if d.Reason == ReasonLocalOverride {
+		return SourceLocalOverride
+	}
+	return d.Source
+}
+
 func newEvaluationDetails(
 	source EvaluationSource,
 	reason EvaluationReason,
@@ -1,6 +1,7 @@
 package statsig
 
 import (
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -220,3 +221,87 @@ func TestEvaluationDetails(t *testing.T) {
 		}, configSyncTime)
 	})
 }
+
+func TestEvaluationSource(t *testing.T) {
+	specsAt := func(time int64) []byte {
+		specs, _ := json.Marshal(downloadConfigSpecResponse{
+			HasUpdates: true,
+			Time:       time,
+			FeatureGates: []configSpec{
+				{Name: "public_gate", Type: "feature_gate", Enabled: true, Rules: []configRule{publicRule("public_rule")}},
+			},
+			DynamicConfigs: []configSpec{
+				{Name: "a_config", Type: "dynamic_config", Entity: "dynamic_config", Enabled: true, DefaultValue: json.RawMessage(`{"key":"value"}`)},
+			},
+		})
+		return specs
+	}
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.WriteHeader(http.StatusOK)
+		if strings.Contains(req.URL.Path, "download_config_specs") {
+			_, _ = res.Write(specsAt(2))
+		}
+	}))
+	defer testServer.Close()
+
+	c := NewClientWithOptions("secret-key", &Options{
+		API:                  testServer.URL,
+		BootstrapValues:      string(specsAt(1)),
+		OutputLoggerOptions:  getOutputLoggerOptionsForTest(t),
+		StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+	})
+	defer c.Shutdown()
+	user := User{UserID: "a-user"}
+
+	gate := c.GetGate(user, "public_gate")
+	config := c.GetConfig(user, "a_config")
+	if gate.Source() != SourceBootstrap || config.Source() != SourceBootstrap {
+		t.Errorf("Expected bootstrapped values, received %s and %s", gate.Source(), config.Source())
+	}
+
+	c.evaluator.store.fetchConfigSpecsFromServer(nil)
+	synced := c.GetGate(user, "public_gate")
+	if synced.Source() != SourceNetwork {
+		t.Errorf("Expected a network value after syncing, received %s", synced.Source())
+	}
+	if gate.Source() != SourceBootstrap {
+		t.Error("Expected earlier evaluations to keep the source they were served from")
+	}
+
+	c.OverrideGate("public_gate", false)
+	overridden := c.GetGate(user, "public_gate")
+	if overridden.Source() != SourceLocalOverride {
+		t.Errorf("Expected an overridden value, received %s", overridden.Source())
+	}
+	empty := FeatureGate{}
+	if empty.Source() != SourceUninitialized {
+		t.Errorf("Expected a gate without evaluation details to be uninitialized, received %s", empty.Source())
+	}
+}
+
+func TestGateEvaluationDetails(t *testing.T) {
+	specs, _ := os.ReadFile("download_config_specs.json")
+	c := newLocalModeClientForTest(t, specs, nil)
+	defer c.Shutdown()
+	user := User{UserID: "a-user", Email: "a-user@example.com"}
+
+	failing := c.GetGate(user, "on_for_statsig_email")
+	if details := failing.GetEvaluationDetails(); failing.Value || details.Source != SourceBootstrap || details.Reason != ReasonNone {
+		t.Errorf("Expected a failing gate to report a plain bootstrap evaluation, received %+v", details)
+	}
+	missing := c.GetGate(user, "not_a_gate")
+	if details := missing.GetEvaluationDetails(); details.Reason != ReasonUnrecognized {
+		t.Errorf("Expected ReasonUnrecognized for a missing gate, received %+v", details)
+	}
+	invalid := c.GetGate(User{}, "always_on_gate")
+	if details := invalid.GetEvaluationDetails(); details.Reason != ReasonError {
+		t.Errorf("Expected ReasonError for a gate that could not be evaluated, received %+v", details)
+	}
+
+	uninitialized := newLocalModeClientForTest(t, nil, nil)
+	defer uninitialized.Shutdown()
+	gate := uninitialized.GetGate(user, "always_on_gate")
+	if details := gate.GetEvaluationDetails(); details.Source != SourceUninitialized || details.Reason != ReasonUnrecognized {
+		t.Errorf("Expected an uninitialized source, received %+v", details)
+	}
+}
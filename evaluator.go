@@ -1,8 +1,6 @@
 package statsig
 
 import (
-	"crypto/sha256"
-	"encoding/base64"
 	"errors"
 	"fmt"
 	"reflect"
@@ -10,6 +8,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -26,6 +25,15 @@ type evalResult struct {
 	EvaluationDetails             *EvaluationDetails     `json:"evaluation_details,omitempty"`
 	IsExperimentGroup             *bool                  `json:"is_experiment_group,omitempty"`
 	DerivedDeviceMetadata         *DerivedDeviceMetadata `json:"derived_device_metadata,omitempty"`
+	SecondaryExposuresTruncated   bool                   `json:"secondary_exposures_truncated,omitempty"`
+	SegmentsNotReady              bool                   `json:"-"`
+	ResolvedValue                 interface{}            `json:"-"`
+	FailureReasons                []string               `json:"-"`
+	MatchedRuleID                 string                 `json:"-"`
+	PassPercentage                float64                `json:"-"`
+	PublicRule                    bool                   `json:"-"`
+	PublicPass                    bool                   `json:"-"`
+	SamplingRate                  *int                   `json:"-"`
 }
 
 type DerivedDeviceMetadata struct {
@@ -86,14 +94,29 @@ type evaluator struct {
 	gateOverrides          map[string]bool
 	configOverrides        map[string]map[string]interface{}
 	layerOverrides         map[string]map[string]interface{}
+	userGateOverrides      map[string]map[string]bool                   // Keyed by user ID, then gate name
+	userConfigOverrides    map[string]map[string]map[string]interface{} // Keyed by user ID, then config name
+	userLayerOverrides     map[string]map[string]map[string]interface{} // Keyed by user ID, then layer name
 	countryLookup          *countryLookup
 	uaParser               *uaParser
 	persistentStorageUtils *userPersistentStorageUtils
+	rateLimiter            *rateLimiter
+	options                *Options
+	forcedDefaultConfigs   map[string]bool
+	regexCache             sync.Map // Compiled str_matches patterns keyed by pattern, nil for patterns that failed to compile
+	regexCacheSize         int32
 	mu                     sync.RWMutex
 }
 
 const dynamicConfigType = "dynamic_config"
 const maxRecursiveDepth = 300
+const maxCachedRegexes = 1000
+
+// Values for Options.EmptyUnitIDBehavior
+const (
+	EmptyUnitIDHashEmpty = "hash_empty"
+	EmptyUnitIDFail      = "fail"
+)
 
 func newEvaluator(
 	transport *transport,
@@ -120,7 +143,32 @@ func newEvaluator(
 		gateOverrides:          make(map[string]bool),
 		configOverrides:        make(map[string]map[string]interface{}),
 		layerOverrides:         make(map[string]map[string]interface{}),
+		userGateOverrides:      make(map[string]map[string]bool),
+		userConfigOverrides:    make(map[string]map[string]map[string]interface{}),
+		userLayerOverrides:     make(map[string]map[string]map[string]interface{}),
 		persistentStorageUtils: persistentStorageUtils,
+		rateLimiter:            newRateLimiter(maxRateLimitedUnits),
+		options:                options,
+		forcedDefaultConfigs:   toStringSet(options.ForceDefaultConfigs),
+	}
+}
+
+// Shares the lookups and persistent storage of this evaluator but evaluates against the given store, with its own overrides
+func (e *evaluator) newSubEvaluator(store *store) *evaluator {
+	return &evaluator{
+		store:                  store,
+		countryLookup:          e.countryLookup,
+		uaParser:               e.uaParser,
+		gateOverrides:          make(map[string]bool),
+		configOverrides:        make(map[string]map[string]interface{}),
+		layerOverrides:         make(map[string]map[string]interface{}),
+		userGateOverrides:      make(map[string]map[string]bool),
+		userConfigOverrides:    make(map[string]map[string]map[string]interface{}),
+		userLayerOverrides:     make(map[string]map[string]map[string]interface{}),
+		persistentStorageUtils: e.persistentStorageUtils,
+		rateLimiter:            e.rateLimiter,
+		options:                e.options,
+		forcedDefaultConfigs:   e.forcedDefaultConfigs,
 	}
 }
 
@@ -148,7 +196,7 @@ func (e *evaluator) evalGate(user User, gateName string, context *evalContext) *
 }
 
 func (e *evaluator) evalGateImpl(user User, gateName string, depth int, context *evalContext) *evalResult {
-	if gateOverrideEval, hasOverride := e.getGateOverrideEval(gateName); hasOverride {
+	if gateOverrideEval, hasOverride := e.getGateOverrideEval(user, gateName); hasOverride {
 		return gateOverrideEval
 	}
 	if gate, hasGate := e.store.getGate(gateName); hasGate {
@@ -165,7 +213,7 @@ func (e *evaluator) evalConfig(user User, configName string, context *evalContex
 }
 
 func (e *evaluator) evalConfigImpl(user User, configName string, depth int, context *evalContext) *evalResult {
-	if configOverrideEval, hasOverride := e.getConfigOverrideEval(configName); hasOverride {
+	if configOverrideEval, hasOverride := e.getConfigOverrideEval(user, configName); hasOverride {
 		return configOverrideEval
 	}
 	config, hasConfig := e.store.getDynamicConfig(configName)
@@ -176,6 +224,11 @@ func (e *evaluator) evalConfigImpl(user User, configName string, depth int, cont
 		return emptyEvalResult
 	}
 
+	if e.forcedDefaultConfigs[configName] {
+		// evalSpec serves the default, sticky values are neither applied nor touched
+		return e.eval(user, config, depth, context)
+	}
+
 	if context.PersistedValues == nil || config.IsActive == nil || !*config.IsActive {
 		return e.evalAndDeleteFromPersistentStorage(user, config, depth, context)
 	}
@@ -193,7 +246,7 @@ func (e *evaluator) evalLayer(user User, name string, context *evalContext) *eva
 }
 
 func (e *evaluator) evalLayerImpl(user User, name string, depth int, context *evalContext) *evalResult {
-	if layerOverrideEval, hasOverride := e.getLayerOverrideEval(name); hasOverride {
+	if layerOverrideEval, hasOverride := e.getLayerOverrideEval(user, name); hasOverride {
 		return layerOverrideEval
 	}
 	config, hasConfig := e.store.getLayerConfig(name)
@@ -217,6 +270,9 @@ func (e *evaluator) evalLayerImpl(user User, name string, depth int, context *ev
 		}
 	} else {
 		evaluation := e.eval(user, config, depth, context)
+		if e.persistentStorageUtils.isReadOnly() || context.isCanceled() {
+			return evaluation
+		}
 		if e.allocatedExperimentExistsAndIsActive(evaluation) {
 			if evaluation.IsExperimentGroup != nil && *evaluation.IsExperimentGroup {
 				e.persistentStorageUtils.save(user, config.IDType, name, evaluation)
@@ -235,26 +291,36 @@ func (e *evaluator) allocatedExperimentExistsAndIsActive(evaluation *evalResult)
 
 func (e *evaluator) evalAndSaveToPersistentStorage(user User, config configSpec, depth int, context *evalContext) *evalResult {
 	evaluation := e.eval(user, config, depth, context)
-	if evaluation.IsExperimentGroup != nil && *evaluation.IsExperimentGroup {
+	if !e.persistentStorageUtils.isReadOnly() && !context.isCanceled() && evaluation.IsExperimentGroup != nil && *evaluation.IsExperimentGroup {
 		e.persistentStorageUtils.save(user, config.IDType, config.Name, evaluation)
 	}
 	return evaluation
 }
 
 func (e *evaluator) evalAndDeleteFromPersistentStorage(user User, config configSpec, depth int, context *evalContext) *evalResult {
-	e.persistentStorageUtils.delete(user, config.IDType, config.Name)
+	if !e.persistentStorageUtils.isReadOnly() && !context.isCanceled() {
+		e.persistentStorageUtils.delete(user, config.IDType, config.Name)
+	}
 	return e.eval(user, config, depth, context)
 }
 
-func (e *evaluator) getGateOverride(name string) (bool, bool) {
+// Gets the override set for the user's ID, else the one set for everyone
+func (e *evaluator) getGateOverride(userID string, name string) (bool, bool) {
 	e.mu.RLock()
 	defer e.mu.RUnlock()
+	if gate, ok := e.userGateOverrides[userID][name]; ok && userID != "" {
+		return gate, ok
+	}
 	gate, ok := e.gateOverrides[name]
 	return gate, ok
 }
 
-func (e *evaluator) getGateOverrideEval(name string) (*evalResult, bool) {
-	if gateOverride, hasOverride := e.getGateOverride(name); hasOverride {
+func (e *evaluator) getGateOverrideEval(user User, name string) (*evalResult, bool) {
+	gateOverride, hasOverride := e.getProvidedGateOverride(user, name)
+	if !hasOverride {
+		gateOverride, hasOverride = e.getGateOverride(user.UserID, name)
+	}
+	if hasOverride {
 		evalDetails := e.createEvaluationDetails(ReasonLocalOverride)
 		return &evalResult{
 			Value:              gateOverride,
@@ -267,15 +333,23 @@ func (e *evaluator) getGateOverrideEval(name string) (*evalResult, bool) {
 	return &evalResult{}, false
 }
 
-func (e *evaluator) getConfigOverride(name string) (map[string]interface{}, bool) {
+// Gets the override set for the user's ID, else the one set for everyone
+func (e *evaluator) getConfigOverride(userID string, name string) (map[string]interface{}, bool) {
 	e.mu.RLock()
 	defer e.mu.RUnlock()
+	if config, ok := e.userConfigOverrides[userID][name]; ok && userID != "" {
+		return config, ok
+	}
 	config, ok := e.configOverrides[name]
 	return config, ok
 }
 
-func (e *evaluator) getConfigOverrideEval(name string) (*evalResult, bool) {
-	if configOverride, hasOverride := e.getConfigOverride(name); hasOverride {
+func (e *evaluator) getConfigOverrideEval(user User, name string) (*evalResult, bool) {
+	configOverride, hasOverride := e.getProvidedConfigOverride(user, name)
+	if !hasOverride {
+		configOverride, hasOverride = e.getConfigOverride(user.UserID, name)
+	}
+	if hasOverride {
 		evalDetails := e.createEvaluationDetails(ReasonLocalOverride)
 		return &evalResult{
 			Value:              true,
@@ -289,15 +363,23 @@ func (e *evaluator) getConfigOverrideEval(name string) (*evalResult, bool) {
 	return &evalResult{}, false
 }
 
-func (e *evaluator) getLayerOverride(name string) (map[string]interface{}, bool) {
+// Gets the override set for the user's ID, else the one set for everyone
+func (e *evaluator) getLayerOverride(userID string, name string) (map[string]interface{}, bool) {
 	e.mu.RLock()
 	defer e.mu.RUnlock()
+	if layer, ok := e.userLayerOverrides[userID][name]; ok && userID != "" {
+		return layer, ok
+	}
 	layer, ok := e.layerOverrides[name]
 	return layer, ok
 }
 
-func (e *evaluator) getLayerOverrideEval(name string) (*evalResult, bool) {
-	if layerOverride, hasOverride := e.getLayerOverride(name); hasOverride {
+func (e *evaluator) getLayerOverrideEval(user User, name string) (*evalResult, bool) {
+	layerOverride, hasOverride := e.getProvidedLayerOverride(user, name)
+	if !hasOverride {
+		layerOverride, hasOverride = e.getLayerOverride(user.UserID, name)
+	}
+	if hasOverride {
 		evalDetails := e.createEvaluationDetails(ReasonLocalOverride)
 		return &evalResult{
 			Value:              true,
@@ -311,6 +393,38 @@ func (e *evaluator) getLayerOverrideEval(name string) (*evalResult, bool) {
 	return &evalResult{}, false
 }
 
+func (e *evaluator) getProvidedGateOverride(user User, name string) (value bool, ok bool) {
+	if e.options == nil || e.options.OverrideProvider == nil {
+		return false, false
+	}
+	defer recoverOverrideProvider(&ok)
+	return e.options.OverrideProvider.GateOverride(user, name)
+}
+
+func (e *evaluator) getProvidedConfigOverride(user User, name string) (value map[string]interface{}, ok bool) {
+	if e.options == nil || e.options.OverrideProvider == nil {
+		return nil, false
+	}
+	defer recoverOverrideProvider(&ok)
+	return e.options.OverrideProvider.ConfigOverride(user, name)
+}
+
+func (e *evaluator) getProvidedLayerOverride(user User, name string) (value map[string]interface{}, ok bool) {
+	if e.options == nil || e.options.OverrideProvider == nil {
+		return nil, false
+	}
+	defer recoverOverrideProvider(&ok)
+	return e.options.OverrideProvider.LayerOverride(user, name)
+}
+
+// A panicking Options.OverrideProvider is logged and treated as having no override
+func recoverOverrideProvider(ok *bool) {
+	if err := recover(); err != nil {
+		Logger().LogError(err)
+		*ok = false
+	}
+}
+
 // Override the value of a Feature Gate for the given user
 func (e *evaluator) OverrideGate(gate string, val bool) {
 	e.mu.Lock()
@@ -332,13 +446,194 @@ func (e *evaluator) OverrideLayer(layer string, val map[string]interface{}) {
 	e.layerOverrides[layer] = val
 }
 
+// Override the value of a Feature Gate for the user with the given ID only, taking precedence over OverrideGate
+func (e *evaluator) OverrideGateForUser(userID string, gate string, val bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.setUserGateOverride(userID, gate, val)
+}
+
+// Override the DynamicConfig value for the user with the given ID only, taking precedence over OverrideConfig
+func (e *evaluator) OverrideConfigForUser(userID string, config string, val map[string]interface{}) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.setUserConfigOverride(userID, config, val)
+}
+
+// Override the Layer value for the user with the given ID only, taking precedence over OverrideLayer
+func (e *evaluator) OverrideLayerForUser(userID string, layer string, val map[string]interface{}) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.setUserLayerOverride(userID, layer, val)
+}
+
+// Must be called with e.mu held
+func (e *evaluator) setUserGateOverride(userID string, gate string, val bool) {
+	if e.userGateOverrides[userID] == nil {
+		e.userGateOverrides[userID] = make(map[string]bool)
+	}
+	e.userGateOverrides[userID][gate] = val
+}
+
+// Must be called with e.mu held
+func (e *evaluator) setUserConfigOverride(userID string, config string, val map[string]interface{}) {
+	if e.userConfigOverrides[userID] == nil {
+		e.userConfigOverrides[userID] = make(map[string]map[string]interface{})
+	}
+	e.userConfigOverrides[userID][config] = val
+}
+
+// Must be called with e.mu held
+func (e *evaluator) setUserLayerOverride(userID string, layer string, val map[string]interface{}) {
+	if e.userLayerOverrides[userID] == nil {
+		e.userLayerOverrides[userID] = make(map[string]map[string]interface{})
+	}
+	e.userLayerOverrides[userID][layer] = val
+}
+
+// Removes every gate, config and layer override set for the user with the given ID
+func (e *evaluator) RemoveUserOverrides(userID string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.userGateOverrides, userID)
+	delete(e.userConfigOverrides, userID)
+	delete(e.userLayerOverrides, userID)
+}
+
+// Removes the override of a Feature Gate set for all users
+func (e *evaluator) RemoveGateOverride(gate string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.gateOverrides, gate)
+}
+
+// Removes the override of a DynamicConfig set for all users
+func (e *evaluator) RemoveConfigOverride(config string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.configOverrides, config)
+}
+
+// Removes the override of a Layer set for all users
+func (e *evaluator) RemoveLayerOverride(layer string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.layerOverrides, layer)
+}
+
+// Removes every override, including those set per user
+func (e *evaluator) RemoveAllOverrides() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.clearOverrides()
+}
+
+// Gets a copy of every override currently set
+func (e *evaluator) GetAllOverrides() Overrides {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.copyOverrides()
+}
+
+// Applies all of the given overrides under one lock and returns a function that puts back the overrides
+// that were set before, undoing any override set in between as well
+func (e *evaluator) applyOverrides(overrides Overrides) func() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	previous := e.copyOverrides()
+	e.mergeOverrides(overrides)
+	return func() {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		e.clearOverrides()
+		e.mergeOverrides(previous)
+	}
+}
+
+// Must be called with e.mu held
+func (e *evaluator) clearOverrides() {
+	e.gateOverrides = make(map[string]bool)
+	e.configOverrides = make(map[string]map[string]interface{})
+	e.layerOverrides = make(map[string]map[string]interface{})
+	e.userGateOverrides = make(map[string]map[string]bool)
+	e.userConfigOverrides = make(map[string]map[string]map[string]interface{})
+	e.userLayerOverrides = make(map[string]map[string]map[string]interface{})
+}
+
+// Must be called with e.mu held
+func (e *evaluator) mergeOverrides(overrides Overrides) {
+	for gate, val := range overrides.Gates {
+		e.gateOverrides[gate] = val
+	}
+	for config, val := range overrides.Configs {
+		e.configOverrides[config] = val
+	}
+	for layer, val := range overrides.Layers {
+		e.layerOverrides[layer] = val
+	}
+	for userID, userOverrides := range overrides.Users {
+		for gate, val := range userOverrides.Gates {
+			e.setUserGateOverride(userID, gate, val)
+		}
+		for config, val := range userOverrides.Configs {
+			e.setUserConfigOverride(userID, config, val)
+		}
+		for layer, val := range userOverrides.Layers {
+			e.setUserLayerOverride(userID, layer, val)
+		}
+	}
+}
+
+// Must be called with e.mu held
+func (e *evaluator) copyOverrides() Overrides {
+	overrides := Overrides{
+		Gates:   copyGateOverrides(e.gateOverrides),
+		Configs: copyValueOverrides(e.configOverrides),
+		Layers:  copyValueOverrides(e.layerOverrides),
+		Users:   make(map[string]Overrides),
+	}
+	for userID, gates := range e.userGateOverrides {
+		userOverrides := overrides.Users[userID]
+		userOverrides.Gates = copyGateOverrides(gates)
+		overrides.Users[userID] = userOverrides
+	}
+	for userID, configs := range e.userConfigOverrides {
+		userOverrides := overrides.Users[userID]
+		userOverrides.Configs = copyValueOverrides(configs)
+		overrides.Users[userID] = userOverrides
+	}
+	for userID, layers := range e.userLayerOverrides {
+		userOverrides := overrides.Users[userID]
+		userOverrides.Layers = copyValueOverrides(layers)
+		overrides.Users[userID] = userOverrides
+	}
+	return overrides
+}
+
+func copyGateOverrides(overrides map[string]bool) map[string]bool {
+	copied := make(map[string]bool, len(overrides))
+	for name, val := range overrides {
+		copied[name] = val
+	}
+	return copied
+}
+
+func copyValueOverrides(overrides map[string]map[string]interface{}) map[string]map[string]interface{} {
+	copied := make(map[string]map[string]interface{}, len(overrides))
+	for name, val := range overrides {
+		copied[name] = val
+	}
+	return copied
+}
+
 // Gets all evaluated values for the given user.
 // These values can then be given to a Statsig Client SDK via bootstrapping.
 func (e *evaluator) getClientInitializeResponse(
 	user User,
 	context *evalContext,
+	onEvaluated gcirEvaluationCallback,
 ) ClientInitializeResponse {
-	return getClientInitializeResponse(user, e, context)
+	return getClientInitializeResponse(user, e, context, onEvaluated)
 }
 
 func (e *evaluator) cleanExposures(exposures []SecondaryExposure) []SecondaryExposure {
@@ -355,6 +650,81 @@ func (e *evaluator) cleanExposures(exposures []SecondaryExposure) []SecondaryExp
 }
 
 func (e *evaluator) eval(user User, spec configSpec, depth int, context *evalContext) *evalResult {
+	if depth == 0 {
+		if e.options != nil && e.options.MaxEvaluationDuration > 0 {
+			context.evaluationDeadline = time.Now().Add(e.options.MaxEvaluationDuration)
+		}
+		context.abortReason = ""
+		if context.shouldAbortEvaluation() {
+			return e.abortedResult(spec, context)
+		}
+	}
+	result := e.evalSpec(user, spec, depth, context)
+	if depth == 0 && context.abortReason != "" {
+		return e.abortedResult(spec, context)
+	}
+	if depth == 0 && e.options != nil && e.options.MaxSecondaryExposures > 0 {
+		e.capSecondaryExposures(result, spec, context)
+	}
+	return result
+}
+
+// Replaces a top level evaluation that ran past Options.MaxEvaluationDuration, or whose context was canceled,
+// with the spec's default value. Dependent gates memoized during the aborted evaluation may be incomplete, so they are discarded
+func (e *evaluator) abortedResult(spec configSpec, context *evalContext) *evalResult {
+	context.dependentGateResults = nil
+	result := &evalResult{
+		Value:              false,
+		RuleID:             "default",
+		SecondaryExposures: make([]SecondaryExposure, 0),
+		EvaluationDetails:  e.createEvaluationDetails(context.abortReason),
+	}
+	if strings.EqualFold(spec.Type, dynamicConfigType) {
+		result.JsonValue = spec.DefaultValueJSON
+	}
+	return result
+}
+
+// Truncates the secondary exposures of a top level evaluation to Options.MaxSecondaryExposures,
+// keeping exposures for gates directly referenced by the spec ahead of transitive ones
+func (e *evaluator) capSecondaryExposures(result *evalResult, spec configSpec, context *evalContext) {
+	limit := e.options.MaxSecondaryExposures
+	if len(result.SecondaryExposures) <= limit && len(result.UndelegatedSecondaryExposures) <= limit {
+		return
+	}
+	direct := make(map[string]bool)
+	for _, rule := range spec.Rules {
+		for _, cond := range rule.Conditions {
+			if strings.EqualFold(cond.Type, "pass_gate") || strings.EqualFold(cond.Type, "fail_gate") {
+				if gateName, ok := cond.TargetValue.(string); ok {
+					direct[hashName(context.Hash, gateName)] = true
+				}
+			}
+		}
+	}
+	capExposures := func(exposures []SecondaryExposure) []SecondaryExposure {
+		if len(exposures) <= limit {
+			return exposures
+		}
+		capped := make([]SecondaryExposure, 0, limit)
+		for _, exposure := range exposures {
+			if len(capped) < limit && direct[exposure.Gate] {
+				capped = append(capped, exposure)
+			}
+		}
+		for _, exposure := range exposures {
+			if len(capped) < limit && !direct[exposure.Gate] {
+				capped = append(capped, exposure)
+			}
+		}
+		result.SecondaryExposuresTruncated = true
+		return capped
+	}
+	result.SecondaryExposures = capExposures(result.SecondaryExposures)
+	result.UndelegatedSecondaryExposures = capExposures(result.UndelegatedSecondaryExposures)
+}
+
+func (e *evaluator) evalSpec(user User, spec configSpec, depth int, context *evalContext) *evalResult {
 	if depth > maxRecursiveDepth {
 		panic(errors.New("Statsig Evaluation Depth Exceeded"))
 	}
@@ -363,27 +733,58 @@ func (e *evaluator) eval(user User, spec configSpec, depth int, context *evalCon
 	isDynamicConfig := strings.EqualFold(spec.Type, dynamicConfigType)
 	if isDynamicConfig {
 		configValue = spec.DefaultValueJSON
+		if e.forcedDefaultConfigs[spec.Name] {
+			return &evalResult{
+				JsonValue:          configValue,
+				RuleID:             "default",
+				EvaluationDetails:  e.createEvaluationDetails(ReasonForcedDefault),
+				SecondaryExposures: make([]SecondaryExposure, 0),
+			}
+		}
 	}
 
 	var exposures = make([]SecondaryExposure, 0)
 	defaultRuleID := "default"
 	var deviceMetadata *DerivedDeviceMetadata
+	segmentsNotReady := false
+	var failureReasons []string
 
 	if spec.Enabled {
 		for _, rule := range spec.Rules {
+			if context.shouldAbortEvaluation() {
+				return &evalResult{Value: false, RuleID: defaultRuleID, SecondaryExposures: exposures}
+			}
 			r := e.evalRule(user, rule, depth+1, context)
 			if r.FetchFromServer {
 				return r
 			}
+			if r.Value && e.isEmptyUnitIDExcluded(user, rule) {
+				r.Value = false
+				r.FailureReasons = append(r.FailureReasons, fmt.Sprintf("missing unit ID '%s'", rule.IDType))
+			}
+			if context.CaptureConditionFailures && !r.Value {
+				failureReasons = append(failureReasons, fmt.Sprintf("rule '%s' failed: %s", getRuleName(rule), strings.Join(r.FailureReasons, ", ")))
+			}
 			exposures = e.cleanExposures(append(exposures, r.SecondaryExposures...))
 			deviceMetadata = assignDerivedDeviceMetadata(r, deviceMetadata)
+			if r.SegmentsNotReady && !segmentsNotReady {
+				segmentsNotReady = true
+				evalDetails.Reason = ReasonSegmentsNotReady
+			}
 			if r.Value {
 				delegatedResult := e.evalDelegate(user, rule, exposures, depth+1, context)
 				if delegatedResult != nil {
+					if segmentsNotReady && delegatedResult.EvaluationDetails != nil {
+						delegatedResult.SegmentsNotReady = true
+						delegatedResult.EvaluationDetails.Reason = ReasonSegmentsNotReady
+					}
 					return delegatedResult
 				}
 
-				pass := evalPassPercent(user, rule, spec)
+				pass := e.evalPassPercent(user, rule, spec)
+				if context.CaptureConditionFailures && !pass {
+					failureReasons = append(failureReasons, fmt.Sprintf("rule '%s' matched but the user is outside its %v%% pass percentage", getRuleName(rule), rule.PassPercentage))
+				}
 				if isDynamicConfig {
 					if pass {
 						configValue = rule.ReturnValueJSON
@@ -397,6 +798,10 @@ func (e *evaluator) eval(user User, spec configSpec, depth int, context *evalCon
 						UndelegatedSecondaryExposures: exposures,
 						EvaluationDetails:             evalDetails,
 						DerivedDeviceMetadata:         deviceMetadata,
+						SegmentsNotReady:              segmentsNotReady,
+						MatchedRuleID:                 rule.ID,
+						PassPercentage:                rule.PassPercentage,
+						SamplingRate:                  rule.SamplingRate,
 					}
 					if rule.IsExperimentGroup != nil {
 						result.IsExperimentGroup = rule.IsExperimentGroup
@@ -410,12 +815,19 @@ func (e *evaluator) eval(user User, spec configSpec, depth int, context *evalCon
 						SecondaryExposures:    exposures,
 						EvaluationDetails:     evalDetails,
 						DerivedDeviceMetadata: deviceMetadata,
+						SegmentsNotReady:      segmentsNotReady,
+						FailureReasons:        failureReasons,
+						PublicPass:            pass && r.PublicRule,
+						SamplingRate:          rule.SamplingRate,
 					}
 				}
 			}
 		}
 	} else {
 		defaultRuleID = "disabled"
+		if context.CaptureConditionFailures {
+			failureReasons = append(failureReasons, "gate disabled")
+		}
 	}
 
 	if isDynamicConfig {
@@ -427,9 +839,18 @@ func (e *evaluator) eval(user User, spec configSpec, depth int, context *evalCon
 			UndelegatedSecondaryExposures: exposures,
 			EvaluationDetails:             evalDetails,
 			DerivedDeviceMetadata:         deviceMetadata,
+			SegmentsNotReady:              segmentsNotReady,
 		}
 	}
-	return &evalResult{Value: false, RuleID: defaultRuleID, SecondaryExposures: exposures, DerivedDeviceMetadata: deviceMetadata}
+	return &evalResult{
+		Value:                 false,
+		RuleID:                defaultRuleID,
+		SecondaryExposures:    exposures,
+		EvaluationDetails:     evalDetails,
+		DerivedDeviceMetadata: deviceMetadata,
+		SegmentsNotReady:      segmentsNotReady,
+		FailureReasons:        failureReasons,
+	}
 }
 
 func (e *evaluator) evalDelegate(user User, rule configRule, exposures []SecondaryExposure, depth int, context *evalContext) *evalResult {
@@ -446,7 +867,7 @@ func (e *evaluator) evalDelegate(user User, rule configRule, exposures []Seconda
 	return result
 }
 
-func evalPassPercent(user User, rule configRule, spec configSpec) bool {
+func (e *evaluator) evalPassPercent(user User, rule configRule, spec configSpec) bool {
 	ruleSalt := rule.Salt
 	if ruleSalt == "" {
 		ruleSalt = rule.ID
@@ -458,10 +879,32 @@ func evalPassPercent(user User, rule configRule, spec configSpec) bool {
 		return true
 	}
 
-	hash := getHashUint64Encoding(spec.Salt + "." + ruleSalt + "." + getUnitID(user, rule.IDType))
+	hash := e.getBucketingHash(spec.Salt + "." + ruleSalt + "." + getUnitID(user, rule.IDType))
 	return float64(hash%10000) < (rule.PassPercentage * 100)
 }
 
+func (e *evaluator) getBucketingHash(key string) uint64 {
+	if e.options != nil && e.options.BucketingHasher != nil {
+		return e.options.BucketingHasher(key)
+	}
+	return getHashUint64Encoding(key)
+}
+
+func (e *evaluator) getIDListKey(id string) string {
+	if e.options != nil && e.options.IDListKeyHasher != nil {
+		return e.options.IDListKeyHasher(id)
+	}
+	return getIDListKey(id)
+}
+
+// Without EmptyUnitIDFail users lacking the rule's ID type all hash the same empty unit ID into its pass percentage
+func (e *evaluator) isEmptyUnitIDExcluded(user User, rule configRule) bool {
+	if e.options == nil || e.options.EmptyUnitIDBehavior != EmptyUnitIDFail {
+		return false
+	}
+	return getUnitID(user, rule.IDType) == ""
+}
+
 func getUnitID(user User, idType string) string {
 	if idType != "" && !strings.EqualFold(idType, "userid") {
 		if val, ok := user.CustomIDs[idType]; ok {
@@ -487,14 +930,30 @@ func (e *evaluator) evalRule(user User, rule configRule, depth int, context *eva
 		if res.FetchFromServer {
 			finalResult.FetchFromServer = true
 		}
+		if res.SegmentsNotReady {
+			finalResult.SegmentsNotReady = true
+		}
+		if context.CaptureConditionFailures && !res.Value && !res.FetchFromServer {
+			finalResult.FailureReasons = append(finalResult.FailureReasons, describeConditionFailure(cond, res.ResolvedValue))
+		}
 		deviceMetadata = assignDerivedDeviceMetadata(res, deviceMetadata)
 		exposures = append(exposures, res.SecondaryExposures...)
 	}
 	finalResult.SecondaryExposures = exposures
 	finalResult.DerivedDeviceMetadata = deviceMetadata
+	finalResult.PublicRule = isPublicRule(rule)
 	return finalResult
 }
 
+func isPublicRule(rule configRule) bool {
+	for _, cond := range rule.Conditions {
+		if !strings.EqualFold(cond.Type, "public") {
+			return false
+		}
+	}
+	return len(rule.Conditions) > 0
+}
+
 func (e *evaluator) evalCondition(user User, cond configCondition, depth int, context *evalContext) *evalResult {
 	var value interface{}
 	condType := cond.Type
@@ -510,7 +969,7 @@ func (e *evaluator) evalCondition(user User, cond configCondition, depth int, co
 		if !ok {
 			return &evalResult{Value: false}
 		}
-		result := e.evalGateImpl(user, dependentGateName, depth+1, context)
+		result := e.evalDependentGate(user, dependentGateName, depth+1, context)
 		if result.FetchFromServer {
 			return &evalResult{FetchFromServer: true}
 		}
@@ -521,13 +980,16 @@ func (e *evaluator) evalCondition(user User, cond configCondition, depth int, co
 				GateValue: strconv.FormatBool(result.Value),
 				RuleID:    result.RuleID,
 			}
-			allExposures = append(result.SecondaryExposures, newExposure)
+			// Copy rather than append, the memoized result's exposures are shared between conditions
+			allExposures = make([]SecondaryExposure, len(result.SecondaryExposures), len(result.SecondaryExposures)+1)
+			copy(allExposures, result.SecondaryExposures)
+			allExposures = append(allExposures, newExposure)
 		}
 
 		if strings.EqualFold(condType, "pass_gate") {
-			return &evalResult{Value: result.Value, SecondaryExposures: allExposures, DerivedDeviceMetadata: result.DerivedDeviceMetadata}
+			return &evalResult{Value: result.Value, SecondaryExposures: allExposures, DerivedDeviceMetadata: result.DerivedDeviceMetadata, SegmentsNotReady: result.SegmentsNotReady}
 		} else {
-			return &evalResult{Value: !result.Value, SecondaryExposures: allExposures, DerivedDeviceMetadata: result.DerivedDeviceMetadata}
+			return &evalResult{Value: !result.Value, SecondaryExposures: allExposures, DerivedDeviceMetadata: result.DerivedDeviceMetadata, SegmentsNotReady: result.SegmentsNotReady}
 		}
 	case strings.EqualFold(condType, "ip_based"):
 		value = getFromUser(user, cond.Field)
@@ -541,14 +1003,33 @@ func (e *evaluator) evalCondition(user User, cond configCondition, depth int, co
 			value = getFromUserAgent(user, cond.Field, e.uaParser, deviceMetadata)
 		}
 	case strings.EqualFold(condType, "user_field"):
-		value = getFromUser(user, cond.Field)
+		value = e.getFromUserField(user, cond.Field)
 	case strings.EqualFold(condType, "environment_field"):
 		value = getFromEnvironment(user, cond.Field)
 	case strings.EqualFold(condType, "current_time"):
-		value = time.Now().Unix() // time in seconds
+		now := time.Now()
+		if !context.EvaluationTime.IsZero() {
+			now = context.EvaluationTime
+		}
+		value = getCurrentTimeInTargetUnit(now, cond.TargetValue)
+	case strings.EqualFold(condType, "rate_limited"):
+		// Each check counts as an event for the unit. Passes once the unit exceeds
+		// additionalValues.limit events within additionalValues.window_ms
+		key, hasKey := cond.AdditionalValues["key"].(string)
+		limit, hasLimit := getNumericValue(cond.AdditionalValues["limit"])
+		windowMs, hasWindow := getNumericValue(cond.AdditionalValues["window_ms"])
+		unitID := getUnitID(user, cond.IDType)
+		if !hasKey || !hasLimit || !hasWindow || unitID == "" {
+			return &evalResult{Value: false}
+		}
+		window := time.Duration(windowMs) * time.Millisecond
+		if !context.recordRateLimits {
+			return &evalResult{Value: e.rateLimiter.check(key, unitID, time.Now(), window, int(limit))}
+		}
+		return &evalResult{Value: e.rateLimiter.recordAndCheck(key, unitID, time.Now(), window, int(limit))}
 	case strings.EqualFold(condType, "user_bucket"):
 		if salt, ok := cond.AdditionalValues["salt"]; ok {
-			value = int64(getHashUint64Encoding(fmt.Sprintf("%s.%s", salt, getUnitID(user, cond.IDType))) % 1000)
+			value = int64(e.getBucketingHash(fmt.Sprintf("%s.%s", salt, getUnitID(user, cond.IDType))) % 1000)
 		}
 	case strings.EqualFold(condType, "unit_id"):
 		value = getUnitID(user, cond.IDType)
@@ -556,8 +1037,28 @@ func (e *evaluator) evalCondition(user User, cond configCondition, depth int, co
 		return &evalResult{FetchFromServer: true}
 	}
 
+	pass, server, segmentsNotReady := e.evalOperator(cond, value)
+	if aliases := e.getTierAliases(cond, value); len(aliases) > 0 && !server {
+		// A negated operator has to hold for every alias of the tier, any other operator for at least one
+		negated := isNegatedOperator(op)
+		for _, alias := range aliases {
+			aliasPass, _, _ := e.evalOperator(cond, alias)
+			if negated {
+				pass = pass && aliasPass
+			} else {
+				pass = pass || aliasPass
+			}
+		}
+	}
+	return &evalResult{Value: pass, FetchFromServer: server, DerivedDeviceMetadata: deviceMetadata, SegmentsNotReady: segmentsNotReady, ResolvedValue: value}
+}
+
+// Returns whether the value 1. passes the condition's operator, 2. needs a server evaluation, 3. was checked against ID lists still loading
+func (e *evaluator) evalOperator(cond configCondition, value interface{}) (bool, bool, bool) {
+	op := cond.Operator
 	pass := false
 	server := false
+	segmentsNotReady := false
 	switch {
 	case strings.EqualFold(op, "gt"):
 		pass = compareNumbers(value, cond.TargetValue, func(x, y float64) bool { return x > y })
@@ -663,8 +1164,9 @@ func (e *evaluator) evalCondition(user User, cond configCondition, depth int, co
 		if cond.TargetValue == nil || value == nil {
 			pass = cond.TargetValue == nil && value == nil
 		} else {
-			matched, _ := regexp.MatchString(castToString(cond.TargetValue), castToString(value))
-			pass = matched
+			if pattern := e.getRegex(castToString(cond.TargetValue)); pattern != nil {
+				pass = pattern.MatchString(castToString(value))
+			}
 		}
 
 	// strict equality
@@ -693,11 +1195,11 @@ func (e *evaluator) evalCondition(user User, cond configCondition, depth int, co
 		pass = (y1 == y2 && m1 == m2 && d1 == d2)
 	case strings.EqualFold(op, "in_segment_list") || strings.EqualFold(op, "not_in_segment_list"):
 		inlist := false
+		segmentsNotReady = !e.store.areIDListsInitialized()
 		if reflect.TypeOf(cond.TargetValue).String() == "string" && reflect.TypeOf(value).String() == "string" {
 			list := e.store.getIDList(castToString(cond.TargetValue))
 			if list != nil {
-				h := sha256.Sum256([]byte(castToString(value)))
-				_, inlist = list.ids.Load(base64.StdEncoding.EncodeToString(h[:])[:8])
+				_, inlist = list.ids.Load(e.getIDListKey(castToString(value)))
 			}
 		}
 		if strings.EqualFold(op, "in_segment_list") {
@@ -705,11 +1207,99 @@ func (e *evaluator) evalCondition(user User, cond configCondition, depth int, co
 		} else {
 			pass = !inlist
 		}
+	case strings.EqualFold(op, "is_null"):
+		pass = isNullConditionValue(cond, value)
+	case strings.EqualFold(op, "is_not_null"):
+		pass = !isNullConditionValue(cond, value)
 	default:
 		pass = false
 		server = true
 	}
-	return &evalResult{Value: pass, FetchFromServer: server, DerivedDeviceMetadata: deviceMetadata}
+	return pass, server, segmentsNotReady
+}
+
+// Compiles str_matches patterns once. Invalid patterns are cached as nil so they are not compiled again.
+// Patterns only come from specs, so a full cache mostly holds ones that earlier syncs dropped and is cleared
+func (e *evaluator) getRegex(pattern string) *regexp.Regexp {
+	if cached, ok := e.regexCache.Load(pattern); ok {
+		return cached.(*regexp.Regexp)
+	}
+	if atomic.LoadInt32(&e.regexCacheSize) >= maxCachedRegexes {
+		e.regexCache.Range(func(key, _ interface{}) bool {
+			e.regexCache.Delete(key)
+			return true
+		})
+		atomic.StoreInt32(&e.regexCacheSize, 0)
+	}
+	compiled, _ := regexp.Compile(pattern)
+	if _, loaded := e.regexCache.LoadOrStore(pattern, compiled); !loaded {
+		atomic.AddInt32(&e.regexCacheSize, 1)
+	}
+	return compiled
+}
+
+// Custom fields are null only when they are not set, so a custom field set to "" is not null. Top level user
+// fields and environment fields are strings that cannot be nil, so for them an empty value counts as null
+func isNullConditionValue(cond configCondition, value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	if value != "" {
+		return false
+	}
+	return !strings.EqualFold(cond.Type, "user_field") || isTopLevelUserField(cond.Field)
+}
+
+func isTopLevelUserField(field string) bool {
+	switch strings.ToLower(field) {
+	case "userid", "user_id", "email", "ip", "ipaddress", "ip_address", "useragent", "user_agent", "country", "locale", "appversion", "app_version":
+		return true
+	}
+	return false
+}
+
+// Gets every tier reachable from the user's tier through Options.EnvironmentTierAliases, for environment_field tier conditions
+func (e *evaluator) getTierAliases(cond configCondition, value interface{}) []string {
+	tier, ok := value.(string)
+	if e.options == nil || len(e.options.EnvironmentTierAliases) == 0 || !ok || tier == "" ||
+		!strings.EqualFold(cond.Type, "environment_field") || !strings.EqualFold(cond.Field, "tier") {
+		return nil
+	}
+	visited := map[string]bool{tier: true}
+	pending := []string{tier}
+	var aliases []string
+	for len(pending) > 0 {
+		current := pending[0]
+		pending = pending[1:]
+		for _, alias := range e.options.EnvironmentTierAliases[current] {
+			if !visited[alias] {
+				visited[alias] = true
+				aliases = append(aliases, alias)
+				pending = append(pending, alias)
+			}
+		}
+	}
+	return aliases
+}
+
+func isNegatedOperator(op string) bool {
+	switch strings.ToLower(op) {
+	case "none", "none_case_sensitive", "neq", "version_neq", "str_contains_none", "array_contains_none", "not_array_contains_all", "not_in_segment_list":
+		return true
+	}
+	return false
+}
+
+func (e *evaluator) evalDependentGate(user User, gateName string, depth int, context *evalContext) *evalResult {
+	if result, ok := context.dependentGateResults[gateName]; ok {
+		return result
+	}
+	result := e.evalGateImpl(user, gateName, depth, context)
+	if context.dependentGateResults == nil {
+		context.dependentGateResults = make(map[string]*evalResult)
+	}
+	context.dependentGateResults[gateName] = result
+	return result
 }
 
 func getFromUser(user User, field string) interface{} {
@@ -723,7 +1313,7 @@ func getFromUser(user User, field string) interface{} {
 	case strings.EqualFold(field, "ip") || strings.EqualFold(field, "ipaddress") || strings.EqualFold(field, "ip_address"):
 		value = user.IpAddress
 	case strings.EqualFold(field, "useragent") || strings.EqualFold(field, "user_agent"):
-		if user.UserAgent != "" { // UserAgent cannot be empty string
+		if user.UserAgent != "" { // UserAgent cannot be empty string, see Options.TreatEmptyUserAgentAsUnset
 			value = user.UserAgent
 		}
 	case strings.EqualFold(field, "country"):
@@ -747,6 +1337,58 @@ func getFromUser(user User, field string) interface{} {
 		}
 	}
 
+	// 3. Resolve dot-notation paths, e.g. subscription.tier, into nested custom and then private attributes
+	if value == nil && strings.Contains(field, ".") {
+		if nestedValue, ok := getFromNestedMap(user.Custom, field); ok {
+			value = nestedValue
+		} else if nestedValue, ok := getFromNestedMap(user.PrivateAttributes, field); ok {
+			value = nestedValue
+		}
+	}
+
+	return value
+}
+
+const maxFieldPathDepth = 10
+
+func getFromNestedMap(attributes map[string]interface{}, path string) (interface{}, bool) {
+	keys := strings.Split(path, ".")
+	if attributes == nil || len(keys) > maxFieldPathDepth {
+		return nil, false
+	}
+	current := attributes
+	for i, key := range keys {
+		value, ok := current[key]
+		if !ok {
+			value, ok = current[strings.ToLower(key)]
+		}
+		if !ok {
+			return nil, false
+		}
+		if i == len(keys)-1 {
+			return value, true
+		}
+		if current, ok = value.(map[string]interface{}); !ok {
+			return nil, false
+		}
+	}
+	return nil, false
+}
+
+// Applies the Options that change how user fields are read on top of getFromUser
+func (e *evaluator) getFromUserField(user User, field string) interface{} {
+	value := getFromUser(user, field)
+	if value != "" && value != nil || e.options == nil {
+		return value
+	}
+	isAppVersion := strings.EqualFold(field, "appversion") || strings.EqualFold(field, "app_version")
+	if isAppVersion && e.options.AppVersionField != "" {
+		value = getFromUser(user, e.options.AppVersionField)
+	}
+	isUserAgent := strings.EqualFold(field, "useragent") || strings.EqualFold(field, "user_agent")
+	if isUserAgent && value == nil && e.options.TreatEmptyUserAgentAsUnset != nil && !*e.options.TreatEmptyUserAgentAsUnset {
+		value = user.UserAgent
+	}
 	return value
 }
 
@@ -1053,6 +1695,14 @@ func getTime(a interface{}) time.Time {
 	return time.Time{}
 }
 
+// Returns now in milliseconds when the target value is a millisecond timestamp, otherwise in seconds
+func getCurrentTimeInTargetUnit(now time.Time, target interface{}) int64 {
+	if targetNum, ok := getNumericValue(target); ok && time.Unix(int64(targetNum), 0).Year() > now.Year()+100 {
+		return now.UnixNano() / int64(time.Millisecond)
+	}
+	return now.Unix()
+}
+
 func getUnixTimestamp(v interface{}) int64 {
 	switch v := v.(type) {
 	case float64:
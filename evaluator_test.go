@@ -1,6 +1,16 @@
 package statsig
 
-import "testing"
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+)
 
 func TestStringComparsigon(t *testing.T) {
 	eq := func(s1, s2 string) bool { return s1 == s2 }
@@ -72,3 +82,571 @@ func TestNumericComparsigon(t *testing.T) {
 		t.Error("Expected int alias equality check to pass")
 	}
 }
+
+func TestCurrentTimeTargetPrecision(t *testing.T) {
+	e := &evaluator{}
+	user := User{UserID: "a-user"}
+	context := &evalContext{}
+	hourAgo := time.Now().Add(-time.Hour)
+	hourAhead := time.Now().Add(time.Hour)
+
+	for _, target := range []int64{hourAgo.Unix(), hourAgo.UnixNano() / int64(time.Millisecond)} {
+		cond := configCondition{Type: "current_time", Operator: "gt", TargetValue: float64(target)}
+		if !e.evalCondition(user, cond, 0, context).Value {
+			t.Errorf("Expected current_time to be after %d", target)
+		}
+	}
+	for _, target := range []int64{hourAhead.Unix(), hourAhead.UnixNano() / int64(time.Millisecond)} {
+		cond := configCondition{Type: "current_time", Operator: "lt", TargetValue: float64(target)}
+		if !e.evalCondition(user, cond, 0, context).Value {
+			t.Errorf("Expected current_time to be before %d", target)
+		}
+	}
+}
+
+func TestEvaluationTimeOverride(t *testing.T) {
+	windowStart := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	windowEnd := time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC)
+	specs, _ := json.Marshal(downloadConfigSpecResponse{
+		HasUpdates: true,
+		Time:       1,
+		FeatureGates: []configSpec{
+			{Name: "january_gate", Type: "feature_gate", Enabled: true, Rules: []configRule{{
+				ID:             "january",
+				PassPercentage: 100,
+				Conditions: []configCondition{
+					{Type: "current_time", Operator: "after", TargetValue: windowStart.UnixNano() / int64(time.Millisecond)},
+					{Type: "current_time", Operator: "before", TargetValue: windowEnd.UnixNano() / int64(time.Millisecond)},
+				},
+			}}},
+		},
+	})
+	c := newLocalModeClientForTest(t, specs, nil)
+	defer c.Shutdown()
+	user := User{UserID: "123"}
+
+	inWindow := &GetGateOptions{EvaluationTime: time.Date(2023, 1, 15, 0, 0, 0, 0, time.UTC)}
+	if !c.CheckGateWithOptions(user, "january_gate", inWindow) {
+		t.Error("Expected january_gate to pass when evaluated inside the window")
+	}
+	outOfWindow := &GetGateOptions{EvaluationTime: time.Date(2023, 3, 15, 0, 0, 0, 0, time.UTC)}
+	if c.CheckGateWithOptions(user, "january_gate", outOfWindow) {
+		t.Error("Expected january_gate to fail when evaluated after the window")
+	}
+	if c.CheckGate(user, "january_gate") {
+		t.Error("Expected january_gate to fail when evaluated now")
+	}
+}
+
+func TestDependentGateEvaluatedOnce(t *testing.T) {
+	dependsOnCounter := configCondition{Type: "pass_gate", TargetValue: "counting_gate"}
+	specs, _ := json.Marshal(downloadConfigSpecResponse{
+		HasUpdates: true,
+		Time:       1,
+		FeatureGates: []configSpec{
+			// Every evaluation of counting_gate records a rate limiter event, so the event count is the evaluation count
+			{Name: "counting_gate", Type: "feature_gate", Enabled: true, Rules: []configRule{{
+				ID:             "counting_rule",
+				PassPercentage: 100,
+				Conditions: []configCondition{{
+					Type:             "rate_limited",
+					IDType:           "userID",
+					AdditionalValues: map[string]interface{}{"key": "counting", "limit": 100, "window_ms": 60000},
+				}},
+			}}},
+			{Name: "parent_gate", Type: "feature_gate", Enabled: true, Rules: []configRule{
+				{ID: "rule_1", PassPercentage: 100, Conditions: []configCondition{dependsOnCounter, dependsOnCounter}},
+				{ID: "rule_2", PassPercentage: 100, Conditions: []configCondition{dependsOnCounter}},
+			}},
+		},
+	})
+	c := newLocalModeClientForTest(t, specs, nil)
+	defer c.Shutdown()
+
+	var exposure *ExposureEvent
+	c.options.EvaluationCallbacks.GateEvaluationCallback = func(name string, result bool, e *ExposureEvent) {
+		exposure = e
+	}
+	if c.CheckGate(User{UserID: "123"}, "parent_gate") {
+		t.Error("Expected parent_gate to fail while counting_gate fails")
+	}
+
+	limiter := c.evaluator.rateLimiter
+	limiter.mu.Lock()
+	events := len(limiter.counters["counting|123"].Value.(*rateLimitCounter).events)
+	limiter.mu.Unlock()
+	if events != 1 {
+		t.Errorf("Expected counting_gate to be evaluated once, evaluated %d times", events)
+	}
+	if exposure == nil || len(exposure.SecondaryExposures) != 1 || exposure.SecondaryExposures[0].Gate != "counting_gate" {
+		t.Errorf("Expected the memoized result to still report its secondary exposure once, received %+v", exposure)
+	}
+}
+
+func TestAppVersionField(t *testing.T) {
+	specs, _ := json.Marshal(downloadConfigSpecResponse{
+		HasUpdates: true,
+		Time:       1,
+		FeatureGates: []configSpec{
+			{Name: "new_app", Type: "feature_gate", Enabled: true, Rules: []configRule{{
+				ID:             "new_app_rule",
+				PassPercentage: 100,
+				Conditions: []configCondition{
+					{Type: "user_field", Field: "app_version", Operator: "version_gte", TargetValue: "2.1.0"},
+				},
+			}}},
+		},
+	})
+	newClient := func(appVersionField string) *Client {
+		return newLocalModeClientForTest(t, specs, &Options{
+			AppVersionField: appVersionField,
+		})
+	}
+	user := User{UserID: "a-user", Custom: map[string]interface{}{"build_version": "2.3.1"}}
+
+	c := newClient("build_version")
+	defer c.Shutdown()
+	if !c.CheckGate(user, "new_app") {
+		t.Error("Expected app_version to be read from the configured custom field")
+	}
+	if c.CheckGate(User{UserID: "a-user", AppVersion: "1.0.0", Custom: user.Custom}, "new_app") {
+		t.Error("Expected User.AppVersion to take precedence over the configured field")
+	}
+
+	unconfigured := newClient("")
+	defer unconfigured.Shutdown()
+	if unconfigured.CheckGate(user, "new_app") {
+		t.Error("Expected the custom field to be ignored without AppVersionField")
+	}
+}
+
+func TestTreatEmptyUserAgentAsUnset(t *testing.T) {
+	specs, _ := json.Marshal(downloadConfigSpecResponse{
+		HasUpdates: true,
+		Time:       1,
+		FeatureGates: []configSpec{
+			{Name: "empty_ua", Type: "feature_gate", Enabled: true, Rules: []configRule{{
+				ID:             "empty_ua_rule",
+				PassPercentage: 100,
+				Conditions: []configCondition{
+					{Type: "user_field", Field: "user_agent", Operator: "eq", TargetValue: ""},
+				},
+			}}},
+		},
+	})
+	newClient := func(treatAsUnset *bool) *Client {
+		return newLocalModeClientForTest(t, specs, &Options{
+			TreatEmptyUserAgentAsUnset: treatAsUnset,
+		})
+	}
+	user := User{UserID: "a-user", UserAgent: ""}
+	unset, set := true, false
+
+	for _, treatAsUnset := range []*bool{nil, &unset} {
+		c := newClient(treatAsUnset)
+		if c.CheckGate(user, "empty_ua") {
+			t.Error("Expected an empty user agent to be unset and not match eq \"\" by default")
+		}
+		c.Shutdown()
+	}
+
+	c := newClient(&set)
+	defer c.Shutdown()
+	if !c.CheckGate(user, "empty_ua") {
+		t.Error("Expected an empty user agent to match eq \"\" when not treated as unset")
+	}
+	withCustom := User{UserID: "a-user", Custom: map[string]interface{}{"user_agent": "Mozilla/5.0"}}
+	if c.CheckGate(withCustom, "empty_ua") {
+		t.Error("Expected a custom user agent to still be consulted for an empty User.UserAgent")
+	}
+}
+
+func TestBucketingHasher(t *testing.T) {
+	specs, _ := json.Marshal(downloadConfigSpecResponse{
+		HasUpdates: true,
+		Time:       1,
+		FeatureGates: []configSpec{
+			{Name: "half_gate", Type: "feature_gate", Salt: "gate_salt", Enabled: true, Rules: []configRule{{
+				ID:             "half_rule",
+				Salt:           "rule_salt",
+				PassPercentage: 50,
+				Conditions:     []configCondition{{Type: "public"}},
+			}}},
+		},
+	})
+	fnvHasher := func(input string) uint64 {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(input))
+		return h.Sum64()
+	}
+	newClient := func(hasher func(input string) uint64) *Client {
+		return newLocalModeClientForTest(t, specs, &Options{
+			BucketingHasher: hasher,
+		})
+	}
+	assignments := func(c *Client) []bool {
+		defer c.Shutdown()
+		values := make([]bool, 100)
+		for i := range values {
+			values[i] = c.CheckGate(User{UserID: fmt.Sprintf("user_%d", i)}, "half_gate")
+		}
+		return values
+	}
+
+	defaults := assignments(newClient(nil))
+	custom := assignments(newClient(fnvHasher))
+	if !reflect.DeepEqual(custom, assignments(newClient(fnvHasher))) {
+		t.Error("Expected a custom hasher to assign users reproducibly")
+	}
+	if reflect.DeepEqual(custom, defaults) {
+		t.Error("Expected a custom hasher to change bucket assignment")
+	}
+	for i, value := range custom {
+		expected := fnvHasher(fmt.Sprintf("gate_salt.rule_salt.user_%d", i))%10000 < 5000
+		if value != expected {
+			t.Errorf("Expected user_%d to be bucketed by the custom hasher", i)
+		}
+	}
+}
+
+func TestMaxEvaluationDuration(t *testing.T) {
+	slowRules := make([]configRule, 10)
+	for i := range slowRules {
+		slowRules[i] = configRule{
+			ID:             fmt.Sprintf("bucket_rule_%d", i),
+			PassPercentage: 100,
+			Conditions: []configCondition{{
+				Type:             "user_bucket",
+				Operator:         "lt",
+				TargetValue:      float64(0),
+				AdditionalValues: map[string]interface{}{"salt": "bucket_salt"},
+			}},
+		}
+	}
+	specs, _ := json.Marshal(downloadConfigSpecResponse{
+		HasUpdates: true,
+		Time:       1,
+		FeatureGates: []configSpec{
+			{Name: "slow_gate", Type: "feature_gate", Enabled: true, Rules: slowRules},
+		},
+	})
+
+	hashDelay := 20 * time.Millisecond
+	c := newLocalModeClientForTest(t, specs, &Options{
+		MaxEvaluationDuration: 30 * time.Millisecond,
+		BucketingHasher: func(input string) uint64 {
+			time.Sleep(hashDelay)
+			return getHashUint64Encoding(input)
+		},
+	})
+	defer c.Shutdown()
+
+	start := time.Now()
+	gate := c.GetGate(User{UserID: "a-user"}, "slow_gate")
+	elapsed := time.Since(start)
+
+	if gate.Value || gate.RuleID != "default" {
+		t.Errorf("Expected the timed out gate to fail with the default rule, received %v %s", gate.Value, gate.RuleID)
+	}
+	if gate.EvaluationDetails == nil || gate.EvaluationDetails.Reason != ReasonTimeout {
+		t.Errorf("Expected ReasonTimeout, received %+v", gate.EvaluationDetails)
+	}
+	if elapsed >= time.Duration(len(slowRules))*hashDelay {
+		t.Errorf("Expected the evaluation to abort near its budget, took %v", elapsed)
+	}
+}
+
+func TestEvalWithoutOptions(t *testing.T) {
+	bytes, _ := os.ReadFile("download_config_specs.json")
+	c := newLocalModeClientForTest(t, bytes, nil)
+	defer c.Shutdown()
+	c.evaluator.options = nil
+	if !c.CheckGate(User{UserID: "a-user"}, "always_on_gate") {
+		t.Errorf("Expected always_on_gate to pass on an evaluator without options")
+	}
+}
+
+func TestEvaluationContextCanceled(t *testing.T) {
+	specs, _ := json.Marshal(downloadConfigSpecResponse{
+		HasUpdates: true,
+		Time:       1,
+		FeatureGates: []configSpec{
+			{Name: "bucketed_gate", Type: "feature_gate", Enabled: true, Rules: []configRule{
+				{ID: "bucket_rule", PassPercentage: 100, Conditions: []configCondition{{
+					Type:             "user_bucket",
+					Operator:         "lt",
+					TargetValue:      float64(0),
+					AdditionalValues: map[string]interface{}{"salt": "bucket_salt"},
+				}}},
+				publicRule("public_rule"),
+			}},
+		},
+		DynamicConfigs: []configSpec{
+			{Name: "a_config", Type: "dynamic_config", Enabled: true, DefaultValue: json.RawMessage(`{"fallback":true}`), Rules: []configRule{
+				{ID: "public_rule", PassPercentage: 100, ReturnValue: json.RawMessage(`{"fallback":false}`), Conditions: []configCondition{{Type: "public"}}},
+			}},
+		},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c := newLocalModeClientForTest(t, specs, &Options{
+		BucketingHasher: func(input string) uint64 {
+			cancel()
+			return getHashUint64Encoding(input)
+		},
+	})
+	defer c.Shutdown()
+	user := User{UserID: "a-user"}
+
+	if !c.CheckGate(user, "bucketed_gate") {
+		t.Error("Expected the gate to pass through its public rule without a context")
+	}
+
+	gate := c.GetGateWithContext(ctx, user, "bucketed_gate")
+	if gate.Value || gate.RuleID != "default" {
+		t.Errorf("Expected the gate canceled mid evaluation to fail with the default rule, received %v %s", gate.Value, gate.RuleID)
+	}
+	if gate.EvaluationDetails == nil || gate.EvaluationDetails.Reason != ReasonCanceled {
+		t.Errorf("Expected ReasonCanceled, received %+v", gate.EvaluationDetails)
+	}
+
+	config := c.GetConfigWithContext(ctx, user, "a_config")
+	if config.GetBool("fallback", false) != true || config.RuleID != "default" {
+		t.Errorf("Expected the already canceled config to serve its default value, received %v %s", config.Value, config.RuleID)
+	}
+	if config.EvaluationDetails == nil || config.EvaluationDetails.Reason != ReasonCanceled {
+		t.Errorf("Expected ReasonCanceled, received %+v", config.EvaluationDetails)
+	}
+}
+
+func TestEmptyUnitIDBehavior(t *testing.T) {
+	specs, _ := json.Marshal(downloadConfigSpecResponse{
+		HasUpdates: true,
+		Time:       1,
+		FeatureGates: []configSpec{
+			{Name: "company_gate", Type: "feature_gate", Enabled: true, IDType: "companyID", Rules: []configRule{
+				{ID: "company_rule", IDType: "companyID", PassPercentage: 50, Conditions: []configCondition{{Type: "public"}}},
+			}},
+		},
+	})
+	newClient := func(behavior string) *Client {
+		return newLocalModeClientForTest(t, specs, &Options{
+			EmptyUnitIDBehavior: behavior,
+			// Every unit ID lands in the pass bucket, including the empty one
+			BucketingHasher: func(input string) uint64 { return 0 },
+		})
+	}
+	withCompany := User{UserID: "a-user", CustomIDs: map[string]string{"companyID": "a-company"}}
+	withoutCompany := User{UserID: "a-user"}
+
+	hashEmpty := newClient(EmptyUnitIDHashEmpty)
+	defer hashEmpty.Shutdown()
+	if gate := hashEmpty.GetGate(withoutCompany, "company_gate"); !gate.Value || gate.RuleID != "company_rule" {
+		t.Errorf("Expected a user without a companyID to be bucketed on the empty ID, received %v %s", gate.Value, gate.RuleID)
+	}
+
+	fail := newClient(EmptyUnitIDFail)
+	defer fail.Shutdown()
+	if gate := fail.GetGate(withoutCompany, "company_gate"); gate.Value || gate.RuleID != "default" {
+		t.Errorf("Expected a user without a companyID to skip the rule, received %v %s", gate.Value, gate.RuleID)
+	}
+	if gate := fail.GetGate(withCompany, "company_gate"); !gate.Value || gate.RuleID != "company_rule" {
+		t.Errorf("Expected a user with a companyID to still match the rule, received %v %s", gate.Value, gate.RuleID)
+	}
+}
+
+func TestEnvironmentTierAliases(t *testing.T) {
+	tierRule := func(id string, operator string) configRule {
+		return configRule{ID: id, PassPercentage: 100, Conditions: []configCondition{
+			{Type: "environment_field", Field: "tier", Operator: operator, TargetValue: []interface{}{"non-production"}},
+		}}
+	}
+	specs, _ := json.Marshal(downloadConfigSpecResponse{
+		HasUpdates: true,
+		Time:       1,
+		FeatureGates: []configSpec{
+			{Name: "non_production_gate", Type: "feature_gate", Enabled: true, Rules: []configRule{tierRule("in_non_production", "any")}},
+			{Name: "production_gate", Type: "feature_gate", Enabled: true, Rules: []configRule{tierRule("outside_non_production", "none")}},
+		},
+	})
+	newClient := func(aliases map[string][]string) *Client {
+		return newLocalModeClientForTest(t, specs, &Options{
+			EnvironmentTierAliases: aliases,
+		})
+	}
+	staging := User{UserID: "a-user", StatsigEnvironment: map[string]string{"tier": "staging"}}
+
+	c := newClient(nil)
+	if c.CheckGate(staging, "non_production_gate") || !c.CheckGate(staging, "production_gate") {
+		t.Error("Expected tiers to match exactly without aliases")
+	}
+	c.Shutdown()
+
+	c = newClient(map[string][]string{"staging": {"non-production"}})
+	defer c.Shutdown()
+	if !c.CheckGate(staging, "non_production_gate") {
+		t.Error("Expected staging to match a rule targeting its non-production alias")
+	}
+	if c.CheckGate(staging, "production_gate") {
+		t.Error("Expected staging to be excluded by a none rule targeting its alias")
+	}
+	production := User{UserID: "a-user", StatsigEnvironment: map[string]string{"tier": "production"}}
+	if c.CheckGate(production, "non_production_gate") || !c.CheckGate(production, "production_gate") {
+		t.Error("Expected a tier without aliases to match exactly")
+	}
+}
+
+func TestIDListKeyHasher(t *testing.T) {
+	specs, _ := json.Marshal(downloadConfigSpecResponse{
+		HasUpdates: true,
+		Time:       1,
+		FeatureGates: []configSpec{
+			{Name: "custom_list_gate", Type: "feature_gate", Enabled: true, Rules: []configRule{{
+				ID:             "in_custom_list",
+				PassPercentage: 100,
+				Conditions:     []configCondition{{Type: "unit_id", Operator: "in_segment_list", TargetValue: "custom_list"}},
+			}}},
+		},
+	})
+	newClient := func(hasher func(id string) string) *Client {
+		c := newLocalModeClientForTest(t, specs, &Options{
+			IDListKeyHasher: hasher,
+		})
+		c.evaluator.store.mu.Lock()
+		c.evaluator.store.idLists["custom_list"] = &idList{
+			Name: "custom_list",
+			ids:  idListMapToSyncMap(map[string]bool{"md5:user-1": true, getIDListKey("user-2"): true}),
+		}
+		c.evaluator.store.mu.Unlock()
+		return c
+	}
+
+	custom := newClient(func(id string) string { return "md5:" + id })
+	defer custom.Shutdown()
+	if !custom.CheckGate(User{UserID: "user-1"}, "custom_list_gate") {
+		t.Error("Expected an ID keyed by the custom hasher to be found in the list")
+	}
+	if custom.CheckGate(User{UserID: "user-2"}, "custom_list_gate") {
+		t.Error("Expected the default key to be unused with a custom hasher")
+	}
+
+	defaults := newClient(nil)
+	defer defaults.Shutdown()
+	if defaults.CheckGate(User{UserID: "user-1"}, "custom_list_gate") || !defaults.CheckGate(User{UserID: "user-2"}, "custom_list_gate") {
+		t.Error("Expected the sha256 based key by default")
+	}
+}
+
+func TestNullOperators(t *testing.T) {
+	fieldRule := func(field string, operator string) []configRule {
+		return []configRule{{ID: field + "_" + operator, PassPercentage: 100, Conditions: []configCondition{
+			{Type: "user_field", Field: field, Operator: operator},
+		}}}
+	}
+	specs, _ := json.Marshal(downloadConfigSpecResponse{
+		HasUpdates: true,
+		Time:       1,
+		FeatureGates: []configSpec{
+			{Name: "plan_is_null", Type: "feature_gate", Enabled: true, Rules: fieldRule("plan", "is_null")},
+			{Name: "plan_is_not_null", Type: "feature_gate", Enabled: true, Rules: fieldRule("plan", "is_not_null")},
+			{Name: "email_is_null", Type: "feature_gate", Enabled: true, Rules: fieldRule("email", "is_null")},
+			{Name: "unknown_operator", Type: "feature_gate", Enabled: true, Rules: fieldRule("plan", "is_blank")},
+		},
+	})
+	c := newLocalModeClientForTest(t, specs, nil)
+	defer c.Shutdown()
+	unset := User{UserID: "a-user"}
+	empty := User{UserID: "a-user", Custom: map[string]interface{}{"plan": ""}}
+	set := User{UserID: "a-user", Email: "a@statsig.com", Custom: map[string]interface{}{"plan": "pro"}}
+
+	if !c.CheckGate(unset, "plan_is_null") || c.CheckGate(unset, "plan_is_not_null") {
+		t.Error("Expected a custom field that is not set to be null")
+	}
+	if c.CheckGate(empty, "plan_is_null") || !c.CheckGate(empty, "plan_is_not_null") {
+		t.Error("Expected a custom field set to an empty string not to be null")
+	}
+	if c.CheckGate(set, "plan_is_null") || !c.CheckGate(set, "plan_is_not_null") {
+		t.Error("Expected a custom field with a value not to be null")
+	}
+	if !c.CheckGate(unset, "email_is_null") || c.CheckGate(set, "email_is_null") {
+		t.Error("Expected an empty top level field to be null")
+	}
+	if c.CheckGate(unset, "unknown_operator") || c.CheckGate(set, "unknown_operator") {
+		t.Error("Expected unknown operators to remain unsupported")
+	}
+}
+
+func TestRegexCache(t *testing.T) {
+	e := &evaluator{}
+	cond := configCondition{Type: "user_field", Operator: "str_matches", TargetValue: "^a+$"}
+	if pass, _, _ := e.evalOperator(cond, "aaa"); !pass {
+		t.Errorf("Expected aaa to match ^a+$")
+	}
+	if pass, _, _ := e.evalOperator(cond, "b"); pass {
+		t.Errorf("Expected b not to match ^a+$")
+	}
+	if cached, ok := e.regexCache.Load("^a+$"); !ok || cached.(*regexp.Regexp) == nil {
+		t.Errorf("Expected the compiled pattern to be cached")
+	}
+
+	invalid := configCondition{Type: "user_field", Operator: "str_matches", TargetValue: "(unclosed"}
+	if pass, _, _ := e.evalOperator(invalid, "(unclosed"); pass {
+		t.Errorf("Expected an invalid pattern not to match")
+	}
+	if cached, ok := e.regexCache.Load("(unclosed"); !ok || cached.(*regexp.Regexp) != nil {
+		t.Errorf("Expected the invalid pattern to be cached as nil")
+	}
+
+	for i := 0; i < maxCachedRegexes*2; i++ {
+		e.getRegex(fmt.Sprintf("^pattern_%d$", i))
+	}
+	cached := 0
+	e.regexCache.Range(func(_, _ interface{}) bool {
+		cached++
+		return true
+	})
+	if cached > maxCachedRegexes {
+		t.Errorf("Expected at most %d cached patterns, received %d", maxCachedRegexes, cached)
+	}
+}
+
+func TestNestedCustomFields(t *testing.T) {
+	user := User{
+		UserID: "a-user",
+		Custom: map[string]interface{}{
+			"subscription": map[string]interface{}{"tier": "pro", "billing": map[string]interface{}{"cycle": "annual"}},
+			"dotted.key":   "flat",
+		},
+		PrivateAttributes: map[string]interface{}{"account": map[string]interface{}{"plan": "team"}},
+	}
+
+	if value := getFromUser(user, "subscription.tier"); value != "pro" {
+		t.Errorf("Expected subscription.tier to be pro, received %v", value)
+	}
+	if value := getFromUser(user, "subscription.billing.cycle"); value != "annual" {
+		t.Errorf("Expected subscription.billing.cycle to be annual, received %v", value)
+	}
+	if value := getFromUser(user, "dotted.key"); value != "flat" {
+		t.Errorf("Expected keys containing dots to be looked up as is first, received %v", value)
+	}
+	if value := getFromUser(user, "account.plan"); value != "team" {
+		t.Errorf("Expected private attributes to be traversed, received %v", value)
+	}
+	if value := getFromUser(user, "subscription.tier.name"); value != nil {
+		t.Errorf("Expected a path through a non map value to be unset, received %v", value)
+	}
+	if value := getFromUser(user, "subscription.missing"); value != nil {
+		t.Errorf("Expected a missing nested key to be unset, received %v", value)
+	}
+
+	deep := map[string]interface{}{"leaf": true}
+	path := "leaf"
+	for i := 0; i < maxFieldPathDepth; i++ {
+		deep = map[string]interface{}{"level": deep}
+		path = "level." + path
+	}
+	if value := getFromUser(User{Custom: deep}, path); value != nil {
+		t.Errorf("Expected paths deeper than %d keys to be unset, received %v", maxFieldPathDepth, value)
+	}
+}
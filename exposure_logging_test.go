@@ -148,6 +148,100 @@ func TestExposureLogging(t *testing.T) {
 		}
 	})
 
+	t.Run("logs a batch of manual exposures once each", func(t *testing.T) {
+		start()
+		ManuallyLogExposures(user, []ManualExposureEntry{
+			{Type: FeatureGateType, Name: "always_on_gate"},
+			{Type: DynamicConfigType, Name: "test_config"},
+			{Type: FeatureGateType, Name: "always_on_gate"},
+			{Type: ExperimentType, Name: "sample_experiment"},
+			{Type: LayerType, Name: "a_layer", Parameter: "experiment_param"},
+			{Type: SegmentType, Name: "a_segment"},
+		})
+		ShutdownAndDangerouslyClearInstance()
+
+		expected := []struct {
+			eventName string
+			key       string
+			name      string
+		}{
+			{"statsig::gate_exposure", "gate", "always_on_gate"},
+			{"statsig::config_exposure", "config", "test_config"},
+			{"statsig::config_exposure", "config", "sample_experiment"},
+			{"statsig::layer_exposure", "config", "a_layer"},
+		}
+		if len(events) != len(expected) {
+			t.Fatalf("Expected %d exposures, received %d", len(expected), len(events))
+		}
+		for i, exposure := range expected {
+			event := events[i]
+			if event.EventName != exposure.eventName || event.Metadata[exposure.key] != exposure.name {
+				t.Errorf("Expected a %s for %s, received %+v", exposure.eventName, exposure.name, event)
+			}
+			if event.Metadata["isManualExposure"] != "true" {
+				t.Errorf("Expected %s to be marked as a manual exposure", exposure.name)
+			}
+		}
+	})
+
+	defer testServer.Close()
+
+}
+
+func TestExposureLoggingByEntityType(t *testing.T) {
+	events := []Event{}
+	testServer := getTestServer(testServerOptions{
+		onLogEvent: func(newEvents []map[string]interface{}) {
+			for _, newEvent := range newEvents {
+				events = append(events, convertToExposureEvent(newEvent))
+			}
+		},
+	})
 	defer testServer.Close()
 
+	InitializeWithOptions("secret-key", &Options{
+		API:                  testServer.URL,
+		Environment:          Environment{Tier: "test"},
+		ExposureLogging:      &ExposureLoggingOptions{DisableConfigs: true},
+		OutputLoggerOptions:  getOutputLoggerOptionsForTest(t),
+		StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+	})
+	user := User{UserID: "some_user_id", Email: "someuser@statsig.com"}
+	gateValue := CheckGate(user, "always_on_gate")
+	config := GetConfig(user, "test_config")
+	ManuallyLogConfigExposure(user, "test_config")
+	experiment := GetExperiment(user, "sample_experiment")
+	ManuallyLogExperimentExposure(user, "sample_experiment")
+	ShutdownAndDangerouslyClearInstance()
+
+	if !gateValue || config.GetNumber("number", 0) != 7 || experiment.GroupName != "Control" {
+		t.Errorf("Expected evaluation to be unaffected by exposure logging options")
+	}
+	if len(events) != 3 {
+		t.Fatalf("Expected exactly 3 exposures, received %d", len(events))
+	}
+	if events[0].EventName != string(GateExposureEventName) {
+		t.Errorf("Expected a gate exposure, received %s", events[0].EventName)
+	}
+	if events[1].EventName != string(ConfigExposureEventName) || events[1].Metadata["config"] != "sample_experiment" {
+		t.Errorf("Expected only the experiment exposure to be logged for configs, received %+v", events[1])
+	}
+	if events[2].Metadata["config"] != "sample_experiment" {
+		t.Errorf("Expected the manual experiment exposure to follow the experiments setting, received %+v", events[2])
+	}
+
+	events = []Event{}
+	InitializeWithOptions("secret-key", &Options{
+		API:                  testServer.URL,
+		Environment:          Environment{Tier: "test"},
+		ExposureLogging:      &ExposureLoggingOptions{DisableExperiments: true},
+		OutputLoggerOptions:  getOutputLoggerOptionsForTest(t),
+		StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+	})
+	ManuallyLogExperimentExposure(user, "sample_experiment")
+	ManuallyLogConfigExposure(user, "test_config")
+	ShutdownAndDangerouslyClearInstance()
+	if len(events) != 1 || events[0].Metadata["config"] != "test_config" {
+		t.Errorf("Expected only the config exposure with experiments disabled, received %+v", events)
+	}
 }
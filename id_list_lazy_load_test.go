@@ -0,0 +1,50 @@
+package statsig
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLazyLoadIDLists(t *testing.T) {
+	idListsRequested := make(chan bool, 1)
+	testServer := getTestServer(testServerOptions{
+		onGetIDLists: func() {
+			idListsRequested <- true
+			time.Sleep(time.Second)
+		},
+	})
+	defer testServer.Close()
+
+	start := time.Now()
+	c := NewClientWithOptions("secret-key", &Options{
+		API:                  testServer.URL,
+		LazyLoadIDLists:      true,
+		OutputLoggerOptions:  getOutputLoggerOptionsForTest(t),
+		StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+	})
+	defer c.Shutdown()
+	if time.Since(start) >= time.Second {
+		t.Error("Expected initialize to return without waiting on ID lists")
+	}
+	<-idListsRequested
+
+	user := User{UserID: "a-user"}
+	gate := c.GetGate(user, "always_on_gate")
+	if !gate.Value {
+		t.Error("Expected non-segment gate to evaluate before ID lists load")
+	}
+	if gate.EvaluationDetails.Reason != ReasonNone {
+		t.Errorf("Expected reason %s for non-segment gate, received %s", ReasonNone, gate.EvaluationDetails.Reason)
+	}
+
+	segmentGate := c.GetGate(user, "on_for_id_list")
+	if segmentGate.EvaluationDetails == nil || segmentGate.EvaluationDetails.Reason != ReasonSegmentsNotReady {
+		t.Errorf("Expected reason %s for segment gate, received %+v", ReasonSegmentsNotReady, segmentGate.EvaluationDetails)
+	}
+
+	time.Sleep(1500 * time.Millisecond)
+	segmentGate = c.GetGate(user, "on_for_id_list")
+	if segmentGate.EvaluationDetails != nil && segmentGate.EvaluationDetails.Reason == ReasonSegmentsNotReady {
+		t.Error("Expected segment gate to stop reporting not ready once ID lists load")
+	}
+}
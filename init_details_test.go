@@ -292,3 +292,111 @@ func TestInitDetails(t *testing.T) {
 		ShutdownAndDangerouslyClearInstance()
 	})
 }
+
+func TestInitializeAsync(t *testing.T) {
+	configSpecBytes, _ := os.ReadFile("download_config_specs.json")
+	release := make(chan struct{})
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		defer req.Body.Close()
+		if strings.Contains(req.URL.Path, "download_config_specs") {
+			<-release
+			res.WriteHeader(http.StatusOK)
+			_, _ = res.Write(configSpecBytes)
+			return
+		}
+		res.WriteHeader(http.StatusOK)
+	}))
+	defer testServer.Close()
+
+	ready := InitializeAsync("secret-key", &Options{
+		API:                  testServer.URL,
+		OutputLoggerOptions:  getOutputLoggerOptionsForTest(t),
+		StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+	})
+	defer ShutdownAndDangerouslyClearInstance()
+
+	user := User{UserID: "123"}
+	if CheckGate(user, "always_on_gate") {
+		t.Errorf("Expected default gate value before initialization completes")
+	}
+	close(release)
+
+	select {
+	case details := <-ready:
+		if !details.Success || details.Error != nil {
+			t.Errorf("Expected initialize to succeed, received %+v", details)
+		}
+		if details.Source != SourceNetwork {
+			t.Errorf("Expected initialize source to be Network, received %s", details.Source)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected initialize details to be delivered")
+	}
+	if !CheckGate(user, "always_on_gate") {
+		t.Errorf("Expected evaluated gate value once initialization completes")
+	}
+}
+
+func TestInitDetailsMarkers(t *testing.T) {
+	configSpecBytes, _ := os.ReadFile("download_config_specs.json")
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if strings.Contains(req.URL.Path, "download_config_specs") {
+			time.Sleep(50 * time.Millisecond)
+			res.WriteHeader(http.StatusOK)
+			_, _ = res.Write(configSpecBytes)
+			return
+		}
+		res.WriteHeader(http.StatusOK)
+	}))
+	defer testServer.Close()
+
+	start := time.Now()
+	c, details := NewClientWithDetails("secret-key", &Options{
+		API:                 testServer.URL,
+		OutputLoggerOptions: getOutputLoggerOptionsForTest(t),
+		StatsigLoggerOptions: StatsigLoggerOptions{
+			DisableSyncDiagnostics: true,
+			DisableApiDiagnostics:  true,
+		},
+	})
+	defer c.Shutdown()
+	end := time.Now()
+
+	find := func(key DiagnosticsKey, step DiagnosticsStep, action DiagnosticsAction) *DiagnosticMarker {
+		for i, marker := range details.Markers {
+			if marker.Key == key && marker.Step == step && marker.Action == action {
+				return &details.Markers[i]
+			}
+		}
+		t.Fatalf("Expected a %s %s %s marker, received %+v", key, step, action, details.Markers)
+		return nil
+	}
+	networkStart := find(DownloadConfigSpecsKey, NetworkRequestStep, StartAction)
+	networkEnd := find(DownloadConfigSpecsKey, NetworkRequestStep, EndAction)
+	processStart := find(DownloadConfigSpecsKey, ProcessStep, StartAction)
+	processEnd := find(DownloadConfigSpecsKey, ProcessStep, EndAction)
+	find(OverallKey, "", EndAction)
+
+	if networkTime := networkEnd.Time.Sub(networkStart.Time); networkTime < 40*time.Millisecond || networkTime > end.Sub(start) {
+		t.Errorf("Expected the network request to take about 50ms, received %v", networkTime)
+	}
+	if networkEnd.StatusCode == nil || *networkEnd.StatusCode != 200 || networkEnd.Success == nil || !*networkEnd.Success {
+		t.Errorf("Expected a successful network request marker, received %+v", networkEnd)
+	}
+	if processStart.Time.Before(networkEnd.Time) || processEnd.Time.Before(processStart.Time) {
+		t.Error("Expected processing to follow the network request")
+	}
+	if networkStart.Time.Before(start.Truncate(time.Millisecond)) || processEnd.Time.After(end) {
+		t.Error("Expected marker times to fall within initialization")
+	}
+
+	disabledClient, disabled := NewClientWithDetails("secret-key", &Options{
+		API:                  testServer.URL,
+		OutputLoggerOptions:  getOutputLoggerOptionsForTest(t),
+		StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+	})
+	defer disabledClient.Shutdown()
+	if len(disabled.Markers) != 0 {
+		t.Errorf("Expected no markers with init diagnostics disabled, received %d", len(disabled.Markers))
+	}
+}
@@ -1,6 +1,8 @@
 package statsig
 
 import (
+	"os"
+	"reflect"
 	"testing"
 )
 
@@ -154,3 +156,38 @@ func TestLayerExposure(t *testing.T) {
 	defer testServer.Close()
 
 }
+
+func TestGetLayers(t *testing.T) {
+	specs, _ := os.ReadFile("download_config_specs.json")
+	c := newLocalModeClientForTest(t, specs, nil)
+	defer c.Shutdown()
+	user := User{UserID: "123", Email: "testuser@statsig.com"}
+	names := []string{"a_layer", "b_layer_no_alloc", "c_layer_with_holdout", "not_a_layer"}
+
+	layers := c.GetLayers(user, names)
+	if len(layers) != len(names) {
+		t.Fatalf("Expected %d layers, received %d", len(names), len(layers))
+	}
+	for _, name := range names[:3] {
+		expected := c.GetLayerWithExposureLoggingDisabled(user, name)
+		layer := layers[name]
+		if layer.Name != name || layer.RuleID != expected.RuleID || !reflect.DeepEqual(layer.Value, expected.Value) {
+			t.Errorf("Expected %s to match GetLayer, received %+v", name, layer)
+		}
+	}
+	if missing := layers["not_a_layer"]; missing.EvaluationDetails == nil || missing.EvaluationDetails.Reason != ReasonUnrecognized {
+		t.Errorf("Expected not_a_layer with reason %s, received %+v", ReasonUnrecognized, missing.EvaluationDetails)
+	}
+
+	var exposedLayers []string
+	c.options.EvaluationCallbacks.LayerEvaluationCallback = func(name string, param string, config DynamicConfig, exposure *ExposureEvent) {
+		exposedLayers = append(exposedLayers, name)
+	}
+	a := layers["a_layer"]
+	a.GetString("experiment_param", "")
+	b := layers["b_layer_no_alloc"]
+	b.GetString("b_param", "")
+	if !reflect.DeepEqual(exposedLayers, []string{"a_layer", "b_layer_no_alloc"}) {
+		t.Errorf("Expected each layer to log its own parameter exposure, received %v", exposedLayers)
+	}
+}
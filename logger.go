@@ -1,6 +1,7 @@
 package statsig
 
 import (
+	"encoding/json"
 	"fmt"
 	"strconv"
 	"sync"
@@ -22,6 +23,7 @@ type ExposureEvent struct {
 	Metadata           map[string]string   `json:"metadata"`
 	SecondaryExposures []SecondaryExposure `json:"secondaryExposures"`
 	Time               int64               `json:"time"`
+	reason             EvaluationReason
 }
 
 const diagnosticsEventName = "statsig::diagnostics"
@@ -40,17 +42,49 @@ type logEventInput struct {
 type logEventResponse struct{}
 
 type logger struct {
-	events        []interface{}
-	transport     *transport
-	tick          *time.Ticker
-	mu            sync.Mutex
-	maxEvents     int
-	disabled      bool
-	diagnostics   *diagnostics
-	options       *Options
-	errorBoundary *errorBoundary
+	events          []interface{}
+	transport       *transport
+	tick            *time.Ticker
+	flushInterval   time.Duration
+	flushSignal     chan struct{}
+	mu              sync.Mutex
+	maxEvents       int
+	maxBufferBytes  int
+	bufferBytes     int
+	eventSizes      []int // Serialized size of each buffered event, kept only when maxBufferBytes is set
+	disabled        bool
+	diagnostics     *diagnostics
+	options         *Options
+	errorBoundary   *errorBoundary
+	debugWriterMu   sync.Mutex
+	minSendGap      time.Duration
+	lastSendTime    time.Time
+	sendScheduled   bool
+	lowExposures    *lowExposureMonitor
+	warmupUntil     time.Time
+	sendMu          sync.Mutex
+	pendingSends    int
+	sendsDone       chan struct{}
+	queueFullPolicy string
+	spaceAvailable  *sync.Cond
+	droppedEvents   int
+	lastDropReport  time.Time
 }
 
+// Values for Options.EventQueueFullPolicy
+const (
+	EventQueueDropNewest = "drop_newest"
+	EventQueueDropOldest = "drop_oldest"
+	EventQueueBlock      = "block"
+)
+
+// With an EventQueueFullPolicy, at most this many sends are in flight. Further flushes leave the buffer full so the
+// policy applies, instead of piling up send goroutines while log_event is unreachable
+const maxPendingEventSends = 3
+
+// Dropped events are reported at most this often, with the number dropped since the last report
+const eventDropReportInterval = time.Minute
+
 func newLogger(transport *transport, options *Options, diagnostics *diagnostics, errorBoundary *errorBoundary) *logger {
 	loggingInterval := time.Minute
 	maxEvents := 1000
@@ -60,30 +94,94 @@ func newLogger(transport *transport, options *Options, diagnostics *diagnostics,
 	if options.LoggingMaxBufferSize > 0 {
 		maxEvents = options.LoggingMaxBufferSize
 	}
-	disabled := options.StatsigLoggerOptions.DisableAllLogging
+	disabled := options.StatsigLoggerOptions.DisableAllLogging || options.ReadOnly
 	log := &logger{
-		events:        make([]interface{}, 0),
-		transport:     transport,
-		tick:          time.NewTicker(loggingInterval),
-		maxEvents:     maxEvents,
-		disabled:      disabled,
-		diagnostics:   diagnostics,
-		options:       options,
-		errorBoundary: errorBoundary,
+		events:          make([]interface{}, 0),
+		transport:       transport,
+		tick:            time.NewTicker(loggingInterval),
+		flushInterval:   loggingInterval,
+		flushSignal:     make(chan struct{}, 1),
+		maxEvents:       maxEvents,
+		maxBufferBytes:  options.LoggingMaxBufferBytes,
+		disabled:        disabled,
+		diagnostics:     diagnostics,
+		options:         options,
+		errorBoundary:   errorBoundary,
+		minSendGap:      options.MinLogEventInterval,
+		lowExposures:    newLowExposureMonitor(options),
+		queueFullPolicy: options.EventQueueFullPolicy,
+	}
+	log.spaceAvailable = sync.NewCond(&log.mu)
+
+	if options.ExposureWarmupDuration > 0 {
+		log.warmupUntil = time.Now().Add(options.ExposureWarmupDuration)
 	}
 
-	go log.backgroundFlush()
+	if !options.ReadOnly {
+		go log.backgroundFlush()
+	}
 
 	return log
 }
 
 func (l *logger) backgroundFlush() {
-	for range l.tick.C {
-		l.flush(false)
+	for {
+		select {
+		case <-l.tick.C:
+			l.flush(false)
+		case <-l.flushSignal:
+			l.flushIfFull()
+		}
+	}
+}
+
+// Wakes the background flusher without blocking. A pending signal already covers any new events
+func (l *logger) signalFlush() {
+	select {
+	case l.flushSignal <- struct{}{}:
+	default:
 	}
 }
 
+func (l *logger) flushIfFull() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.isFull() {
+		l.flushInternal(false)
+	}
+}
+
+func (l *logger) isFull() bool {
+	return len(l.events) >= l.maxEvents || (l.maxBufferBytes > 0 && l.bufferBytes >= l.maxBufferBytes)
+}
+
+func (l *logger) setFlushInterval(interval time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.tick.Reset(interval)
+	l.flushInterval = interval
+}
+
+func (l *logger) setDisabled(disabled bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.disabled = disabled || l.options.ReadOnly
+	l.signalSpaceAvailable()
+}
+
 func (l *logger) logCustom(evt Event) {
+	if rate, ok := l.options.CustomEventSamplingRate[evt.EventName]; ok {
+		if !sample(rate) {
+			return
+		}
+		// Copied so the caller's metadata map is not mutated
+		metadata := make(map[string]string, len(evt.Metadata)+1)
+		for key, value := range evt.Metadata {
+			metadata[key] = value
+		}
+		metadata["samplingRate"] = strconv.Itoa(rate)
+		evt.Metadata = metadata
+	}
 	evt.User.PrivateAttributes = nil
 	if evt.Time == 0 {
 		evt.Time = getUnixMilli()
@@ -96,21 +194,138 @@ func (l *logger) logExposure(evt ExposureEvent) {
 	if evt.Time == 0 {
 		evt.Time = getUnixMilli()
 	}
+	if time.Now().Before(l.warmupUntil) {
+		if l.options.SuppressWarmupExposures {
+			return
+		}
+		evt.Metadata = withWarmupFlag(evt.Metadata)
+	}
+	l.writeExposureForDebugging(evt)
 	l.logInternal(evt)
 }
 
+// Copies the metadata so the event returned to the caller is left untagged
+func withWarmupFlag(metadata map[string]string) map[string]string {
+	tagged := make(map[string]string, len(metadata)+1)
+	for key, value := range metadata {
+		tagged[key] = value
+	}
+	tagged["warmup"] = "true"
+	return tagged
+}
+
+func (l *logger) writeExposureForDebugging(evt ExposureEvent) {
+	writer := l.options.ExposureDebugWriter
+	if writer == nil {
+		return
+	}
+	line, err := json.Marshal(evt)
+	if err != nil {
+		return
+	}
+	l.debugWriterMu.Lock()
+	defer l.debugWriterMu.Unlock()
+	if _, err := writer.Write(append(line, '\n')); err != nil {
+		Logger().LogError(fmt.Sprintf("Failed to write exposure to ExposureDebugWriter: %s", err.Error()))
+	}
+}
+
 func (l *logger) logInternal(evt interface{}) {
+	// Sized before taking l.mu so concurrent callers don't wait on each other's serialization
+	if dropped := l.enqueue(evt, l.eventBytes(evt)); dropped > 0 {
+		// Reported outside of l.mu since the error boundary sends the exception inline
+		l.errorBoundary.logExceptionWithContext(&EventsDroppedError{Events: dropped}, errorContext{
+			Caller:      "statsig::log_event_dropped",
+			EventCount:  dropped,
+			LogToOutput: true,
+		})
+	}
+}
+
+// Buffers the event, applying Options.EventQueueFullPolicy when the buffer is still full from before, i.e. its flush
+// has not taken the events yet or maxPendingEventSends are already in flight. Returns the number of dropped events
+// due to be reported
+func (l *logger) enqueue(evt interface{}, size int) int {
 	l.mu.Lock()
 	defer l.mu.Unlock()
 
 	if l.disabled {
-		return
+		return 0
+	}
+
+	report := 0
+	if l.isFull() {
+		switch l.queueFullPolicy {
+		case EventQueueDropNewest:
+			return l.countDroppedEvent()
+		case EventQueueDropOldest:
+			if len(l.eventSizes) > 0 {
+				l.bufferBytes -= l.eventSizes[0]
+				l.eventSizes = l.eventSizes[1:]
+			}
+			l.events[0] = nil
+			l.events = l.events[1:]
+			report = l.countDroppedEvent()
+		case EventQueueBlock:
+			if !l.sendScheduled {
+				l.flushInternal(false)
+			}
+			for l.isFull() && !l.disabled {
+				l.spaceAvailable.Wait()
+			}
+			if l.disabled {
+				return 0
+			}
+		}
 	}
 
 	l.events = append(l.events, evt)
-	if len(l.events) >= l.maxEvents {
-		l.flushInternal(false)
+	if l.maxBufferBytes > 0 {
+		l.eventSizes = append(l.eventSizes, size)
+		l.bufferBytes += size
+	}
+	if l.isFull() {
+		l.signalFlush()
+	}
+	return report
+}
+
+// Returns the events dropped since the last report once eventDropReportInterval has passed, else 0.
+// Must be called with l.mu held
+func (l *logger) countDroppedEvent() int {
+	l.droppedEvents++
+	if !l.lastDropReport.IsZero() && time.Since(l.lastDropReport) < eventDropReportInterval {
+		return 0
+	}
+	dropped := l.droppedEvents
+	l.droppedEvents = 0
+	l.lastDropReport = time.Now()
+	return dropped
+}
+
+// Serialized size of an event, counted only when Options.LoggingMaxBufferBytes is set
+func (l *logger) eventBytes(evt interface{}) int {
+	if l.maxBufferBytes <= 0 {
+		return 0
+	}
+	serialized, err := json.Marshal(evt)
+	if err != nil {
+		return 0
 	}
+	return len(serialized)
+}
+
+// Wakes callers blocked by EventQueueBlock. Must be called with l.mu held
+func (l *logger) signalSpaceAvailable() {
+	if l.spaceAvailable != nil {
+		l.spaceAvailable.Broadcast()
+	}
+}
+
+func (l *logger) queuedEventCount() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.events)
 }
 
 func (l *logger) logGateExposure(
@@ -120,10 +335,66 @@ func (l *logger) logGateExposure(
 	context *evalContext,
 ) *ExposureEvent {
 	evt := l.getGateExposureWithEvaluationDetails(user, gateName, res, context)
-	l.logExposure(*evt)
+	if l.isExposureLoggingEnabled(evt, context) {
+		l.logExposure(*evt)
+	}
 	return evt
 }
 
+func (l *logger) filterSecondaryExposures(exposures []SecondaryExposure) []SecondaryExposure {
+	filter := l.options.SecondaryExposureFilter
+	if filter == nil {
+		return exposures
+	}
+	filtered := make([]SecondaryExposure, 0, len(exposures))
+	for _, exposure := range exposures {
+		if filter(exposure) {
+			filtered = append(filtered, exposure)
+		}
+	}
+	return filtered
+}
+
+func (l *logger) isExposureLoggingEnabled(evt *ExposureEvent, context *evalContext) bool {
+	if !l.isExposureReasonAllowed(evt) {
+		return false
+	}
+	opts := l.options.ExposureLogging
+	if opts == nil {
+		return true
+	}
+	switch evt.EventName {
+	case GateExposureEventName:
+		return !opts.DisableGates
+	case ConfigExposureEventName:
+		if context != nil && context.IsExperiment {
+			return !opts.DisableExperiments
+		}
+		return !opts.DisableConfigs
+	case LayerExposureEventName:
+		return !opts.DisableLayers
+	}
+	return true
+}
+
+// Exposures without evaluation details, such as failing gates, count as ReasonNone
+func (l *logger) isExposureReasonAllowed(evt *ExposureEvent) bool {
+	allowlist := l.options.ExposureReasonAllowlist
+	if len(allowlist) == 0 {
+		return true
+	}
+	reason := evt.reason
+	if reason == "" {
+		reason = ReasonNone
+	}
+	for _, allowed := range allowlist {
+		if allowed == reason {
+			return true
+		}
+	}
+	return false
+}
+
 func (l *logger) getGateExposureWithEvaluationDetails(
 	user User,
 	gateName string,
@@ -143,10 +414,11 @@ func (l *logger) getGateExposureWithEvaluationDetails(
 		User:               user,
 		EventName:          GateExposureEventName,
 		Metadata:           metadata,
-		SecondaryExposures: res.SecondaryExposures,
+		SecondaryExposures: l.filterSecondaryExposures(res.SecondaryExposures),
 	}
 	l.addEvaluationDetailsToExposureEvent(evt, res.EvaluationDetails)
 	l.addDeviceMetadataToExposureEvent(evt, res.DerivedDeviceMetadata)
+	l.addTruncationToExposureEvent(evt, res)
 	return evt
 }
 
@@ -155,6 +427,7 @@ func (l *logger) addEvaluationDetailsToExposureEvent(
 	evalDetails *EvaluationDetails,
 ) {
 	if evalDetails != nil {
+		evt.reason = evalDetails.Reason
 		evt.Metadata["reason"] = string(evalDetails.detailedReason())
 		evt.Metadata["configSyncTime"] = fmt.Sprint(evalDetails.ConfigSyncTime)
 		evt.Metadata["initTime"] = fmt.Sprint(evalDetails.InitTime)
@@ -174,6 +447,15 @@ func (l *logger) addDeviceMetadataToExposureEvent(
 	}
 }
 
+func (l *logger) addTruncationToExposureEvent(
+	evt *ExposureEvent,
+	res *evalResult,
+) {
+	if res.SecondaryExposuresTruncated {
+		evt.Metadata["secondaryExposuresTruncated"] = "true"
+	}
+}
+
 func (l *logger) logConfigExposure(
 	user User,
 	configName string,
@@ -181,7 +463,9 @@ func (l *logger) logConfigExposure(
 	context *evalContext,
 ) *ExposureEvent {
 	evt := l.getConfigExposureWithEvaluationDetails(user, configName, res, context)
-	l.logExposure(*evt)
+	if l.isExposureLoggingEnabled(evt, context) {
+		l.logExposure(*evt)
+	}
 	return evt
 }
 
@@ -202,10 +486,11 @@ func (l *logger) getConfigExposureWithEvaluationDetails(
 		User:               user,
 		EventName:          ConfigExposureEventName,
 		Metadata:           metadata,
-		SecondaryExposures: res.SecondaryExposures,
+		SecondaryExposures: l.filterSecondaryExposures(res.SecondaryExposures),
 	}
 	l.addEvaluationDetailsToExposureEvent(evt, res.EvaluationDetails)
 	l.addDeviceMetadataToExposureEvent(evt, res.DerivedDeviceMetadata)
+	l.addTruncationToExposureEvent(evt, res)
 	return evt
 }
 
@@ -217,7 +502,9 @@ func (l *logger) logLayerExposure(
 	context *evalContext,
 ) *ExposureEvent {
 	evt := l.getLayerExposureWithEvaluationDetails(user, config, parameterName, evalResult, context)
-	l.logExposure(*evt)
+	if l.isExposureLoggingEnabled(evt, context) {
+		l.logExposure(*evt)
+	}
 	return evt
 }
 
@@ -256,15 +543,19 @@ func (l *logger) getLayerExposureWithEvaluationDetails(
 		User:               user,
 		EventName:          LayerExposureEventName,
 		Metadata:           metadata,
-		SecondaryExposures: exposures,
+		SecondaryExposures: l.filterSecondaryExposures(exposures),
 	}
 	l.addEvaluationDetailsToExposureEvent(evt, evalResult.EvaluationDetails)
 	l.addDeviceMetadataToExposureEvent(evt, evalResult.DerivedDeviceMetadata)
+	l.addTruncationToExposureEvent(evt, evalResult)
 	return evt
 }
 
 func (l *logger) flush(closing bool) {
 	l.logDiagnosticsEvents(l.diagnostics)
+	for _, warning := range l.lowExposures.collectWarnings(time.Now()) {
+		l.logInternal(warning)
+	}
 	l.mu.Lock()
 	defer l.mu.Unlock()
 
@@ -278,19 +569,122 @@ func (l *logger) flushInternal(closing bool) {
 	if len(l.events) == 0 {
 		return
 	}
+	if !closing && l.deferSend() {
+		return
+	}
+	if !closing && l.queueFullPolicy != "" && l.sendsInFlight() >= maxPendingEventSends {
+		// finishSend wakes the flusher once a send completes
+		return
+	}
+	l.lastSendTime = time.Now()
 
+	batches := splitEventBatches(l.events, l.maxEvents)
 	if closing {
-		l.sendEvents(l.events)
+		for _, batch := range batches {
+			l.sendEvents(batch)
+		}
 	} else {
-		go l.sendEvents(l.events)
+		l.startSend()
+		go func() {
+			defer l.finishSend()
+			for _, batch := range batches {
+				l.sendEvents(batch)
+			}
+		}()
 	}
 
 	l.events = make([]interface{}, 0)
+	l.eventSizes = nil
+	l.bufferBytes = 0
+	l.signalSpaceAvailable()
+}
+
+// Events keep buffering until the background flusher takes them, so the buffer can hold more than maxEvents
+// by then. Each log_event request still carries at most maxEvents
+func splitEventBatches(events []interface{}, maxEvents int) [][]interface{} {
+	batches := make([][]interface{}, 0, len(events)/maxEvents+1)
+	for len(events) > maxEvents {
+		batches = append(batches, events[:maxEvents])
+		events = events[maxEvents:]
+	}
+	return append(batches, events)
+}
+
+// Holds back sends closer together than Options.MinLogEventInterval, scheduling a single send once the interval has elapsed.
+// Events keep buffering in the meantime. Must be called with l.mu held
+func (l *logger) deferSend() bool {
+	if l.minSendGap <= 0 || l.lastSendTime.IsZero() {
+		return false
+	}
+	remaining := l.minSendGap - time.Since(l.lastSendTime)
+	if remaining <= 0 {
+		return false
+	}
+	if !l.sendScheduled {
+		l.sendScheduled = true
+		l.startSend()
+		time.AfterFunc(remaining, func() {
+			defer l.finishSend()
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			l.sendScheduled = false
+			l.flushInternal(false)
+		})
+	}
+	return true
+}
+
+// Counts a background or scheduled send as pending until finishSend, so waitForSends can tell when all have completed
+func (l *logger) startSend() {
+	l.sendMu.Lock()
+	defer l.sendMu.Unlock()
+	if l.pendingSends == 0 {
+		l.sendsDone = make(chan struct{})
+	}
+	l.pendingSends++
+}
+
+func (l *logger) finishSend() {
+	l.sendMu.Lock()
+	l.pendingSends--
+	if l.pendingSends == 0 {
+		close(l.sendsDone)
+	}
+	l.sendMu.Unlock()
+	if l.queueFullPolicy != "" {
+		l.signalFlush()
+	}
+}
+
+func (l *logger) sendsInFlight() int {
+	l.sendMu.Lock()
+	defer l.sendMu.Unlock()
+	return l.pendingSends
+}
+
+// Waits until no sends are pending, or returns ErrFlushTimeout once timeout elapses
+func (l *logger) waitForSends(timeout time.Duration) error {
+	l.sendMu.Lock()
+	if l.pendingSends == 0 {
+		l.sendMu.Unlock()
+		return nil
+	}
+	done := l.sendsDone
+	l.sendMu.Unlock()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+		return nil
+	case <-timer.C:
+		return ErrFlushTimeout
+	}
 }
 
 func (l *logger) sendEvents(events []interface{}) {
 	var res logEventResponse
-	_, err := l.transport.log_event(events, &res, RequestOptions{retries: maxRetries})
+	_, err := l.transport.log_event(events, &res, l.transport.retryOptions(maxRetries))
 	if err != nil {
 		context := errorContext{
 			Caller:       "statsig::log_event_failed",
@@ -1,10 +1,16 @@
 package statsig
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"reflect"
 	"strconv"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -18,6 +24,7 @@ func TestLog(t *testing.T) {
 	transport := newTransport("secret", opt)
 	errorBoundary := newErrorBoundary("secret", opt, nil)
 	logger := newLogger(transport, opt, nil, errorBoundary)
+	defer logger.tick.Stop()
 
 	user := User{
 		UserID:            "123",
@@ -96,3 +103,540 @@ func TestLog(t *testing.T) {
 		t.Errorf("Config exposure event time not set correctly.")
 	}
 }
+
+func TestLogFlushesOnBufferBytes(t *testing.T) {
+	flushed := make(chan int, 10)
+	testServer := getTestServer(testServerOptions{
+		onLogEvent: func(events []map[string]interface{}) {
+			flushed <- len(events)
+		},
+	})
+	defer testServer.Close()
+	opt := &Options{
+		API:                   testServer.URL,
+		LoggingMaxBufferSize:  1000,
+		LoggingMaxBufferBytes: 4000,
+	}
+	transport := newTransport("secret", opt)
+	errorBoundary := newErrorBoundary("secret", opt, nil)
+	logger := newLogger(transport, opt, nil, errorBoundary)
+	defer logger.tick.Stop()
+
+	largeMetadata := map[string]string{"payload": strings.Repeat("x", 1000)}
+	for i := 0; i < 4; i++ {
+		logger.logCustom(Event{EventName: "large_event", User: User{UserID: "123"}, Metadata: largeMetadata})
+	}
+
+	select {
+	case count := <-flushed:
+		if count != 4 {
+			t.Errorf("Expected flush of 4 events on byte threshold, received %d", count)
+		}
+	case <-time.After(time.Second):
+		t.Error("Expected a flush once the buffered bytes exceeded LoggingMaxBufferBytes")
+	}
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	if len(logger.events) != 0 || logger.bufferBytes != 0 {
+		t.Errorf("Expected buffer to be reset after flush, %d events and %d bytes remain", len(logger.events), logger.bufferBytes)
+	}
+}
+
+func TestMinLogEventInterval(t *testing.T) {
+	type send struct {
+		at     time.Time
+		events int
+	}
+	sends := make(chan send, 20)
+	testServer := getTestServer(testServerOptions{
+		onLogEvent: func(events []map[string]interface{}) {
+			sends <- send{at: time.Now(), events: len(events)}
+		},
+	})
+	defer testServer.Close()
+	opt := &Options{
+		API:                  testServer.URL,
+		LoggingMaxBufferSize: 2,
+		MinLogEventInterval:  300 * time.Millisecond,
+	}
+	transport := newTransport("secret", opt)
+	errorBoundary := newErrorBoundary("secret", opt, nil)
+	logger := newLogger(transport, opt, nil, errorBoundary)
+	defer logger.tick.Stop()
+
+	for burst := 0; burst < 3; burst++ {
+		for i := 0; i < 4; i++ {
+			logger.logCustom(Event{EventName: "burst_event", User: User{UserID: "123"}})
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	received := make([]send, 0)
+	timeout := time.After(time.Second)
+	for total := 0; total < 12; {
+		select {
+		case s := <-sends:
+			received = append(received, s)
+			total += s.events
+		case <-timeout:
+			t.Fatalf("Expected all 12 events to be sent, received %+v", received)
+		}
+	}
+	// a flush splits into requests of at most LoggingMaxBufferSize events, sent back to back
+	flushes := [][]send{{received[0]}}
+	for _, s := range received[1:] {
+		last := flushes[len(flushes)-1]
+		if s.at.Sub(last[len(last)-1].at) > 150*time.Millisecond {
+			flushes = append(flushes, []send{s})
+		} else {
+			flushes[len(flushes)-1] = append(last, s)
+		}
+	}
+	for _, s := range received {
+		if s.events > 2 {
+			t.Errorf("Expected each request to hold at most 2 events, received %d", s.events)
+		}
+	}
+	if len(flushes) != 2 {
+		t.Errorf("Expected the buffer-full triggers to be coalesced into 2 flushes, received %d", len(flushes))
+	}
+	if len(flushes) >= 2 && flushes[1][0].at.Sub(flushes[0][0].at) < 250*time.Millisecond {
+		t.Errorf("Expected flushes to be at least the minimum interval apart, received %s", flushes[1][0].at.Sub(flushes[0][0].at))
+	}
+}
+
+func TestUpdateRuntimeLoggingInterval(t *testing.T) {
+	flushed := make(chan int, 10)
+	testServer := getTestServer(testServerOptions{
+		onLogEvent: func(events []map[string]interface{}) {
+			flushed <- len(events)
+		},
+	})
+	defer testServer.Close()
+	c := NewClientWithOptions("secret-key", &Options{
+		API:                  testServer.URL,
+		LoggingInterval:      time.Hour,
+		OutputLoggerOptions:  getOutputLoggerOptionsForTest(t),
+		StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+	})
+	defer c.Shutdown()
+	c.LogEvent(Event{EventName: "before_update", User: User{UserID: "123"}})
+
+	select {
+	case <-flushed:
+		t.Error("Expected no flush before the logging interval was updated")
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	err := c.UpdateRuntimeOptions(RuntimeOptions{LoggingInterval: 50 * time.Millisecond})
+	if err != nil {
+		t.Errorf("Expected runtime options update to succeed, received %s", err)
+	}
+	select {
+	case <-flushed:
+	case <-time.After(time.Second):
+		t.Error("Expected a flush at the updated logging interval")
+	}
+
+	err = c.UpdateRuntimeOptions(RuntimeOptions{LoggingInterval: -time.Second})
+	if err == nil || err.Error() != InvalidRuntimeOptionsError {
+		t.Errorf("Expected invalid runtime options to be rejected, received %v", err)
+	}
+}
+
+func TestExposureDebugWriter(t *testing.T) {
+	specs, _ := json.Marshal(downloadConfigSpecResponse{
+		HasUpdates: true,
+		Time:       1,
+		FeatureGates: []configSpec{
+			{Name: "public_gate", Type: "feature_gate", Enabled: true, Rules: []configRule{publicRule("public_rule")}},
+		},
+	})
+	var buffer bytes.Buffer
+	c := newLocalModeClientForTest(t, specs, &Options{
+		ExposureDebugWriter: &buffer,
+	})
+	defer c.Shutdown()
+
+	c.CheckGate(User{UserID: "a-user"}, "public_gate")
+	lines := strings.Split(strings.TrimSuffix(buffer.String(), "\n"), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("Expected one JSON line per exposure, received %q", buffer.String())
+	}
+	var exposure ExposureEvent
+	if err := json.Unmarshal([]byte(lines[0]), &exposure); err != nil {
+		t.Fatalf("Expected a parseable JSON line, received %s", err.Error())
+	}
+	if exposure.EventName != GateExposureEventName || exposure.Metadata["gate"] != "public_gate" || exposure.Metadata["ruleID"] != "public_rule" {
+		t.Errorf("Expected the gate exposure to be written, received %+v", exposure)
+	}
+	c.logger.mu.Lock()
+	buffered := len(c.logger.events)
+	c.logger.mu.Unlock()
+	if buffered != 1 {
+		t.Errorf("Expected the exposure to still be logged normally, received %d buffered events", buffered)
+	}
+}
+
+func TestLowExposureWarning(t *testing.T) {
+	var mu sync.Mutex
+	var warnings []map[string]interface{}
+	testServer := getTestServer(testServerOptions{
+		onLogEvent: func(events []map[string]interface{}) {
+			mu.Lock()
+			defer mu.Unlock()
+			for _, event := range events {
+				if event["eventName"] == lowExposureWarningEventName {
+					warnings = append(warnings, event["metadata"].(map[string]interface{}))
+				}
+			}
+		},
+	})
+	defer testServer.Close()
+	c := NewClientWithOptions("secret-key", &Options{
+		API:                         testServer.URL,
+		LoggingInterval:             50 * time.Millisecond,
+		LowExposureWarningThreshold: 5,
+		LowExposureWarningWindow:    100 * time.Millisecond,
+		OutputLoggerOptions:         getOutputLoggerOptionsForTest(t),
+		StatsigLoggerOptions:        getStatsigLoggerOptionsForTest(t),
+	})
+	defer c.Shutdown()
+
+	for i := 0; i < 2; i++ {
+		c.GetExperiment(User{UserID: strconv.Itoa(i)}, "sample_experiment")
+	}
+	for i := 0; i < 5; i++ {
+		c.GetExperiment(User{UserID: strconv.Itoa(i)}, "experiment_with_holdout_and_gate")
+	}
+	time.Sleep(300 * time.Millisecond)
+	c.GetExperiment(User{UserID: "late_user"}, "sample_experiment")
+	time.Sleep(300 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(warnings) != 1 {
+		t.Fatalf("Expected a single low exposure warning, received %+v", warnings)
+	}
+	if warnings[0]["experiment"] != "sample_experiment" || warnings[0]["exposures"] != float64(2) || warnings[0]["threshold"] != float64(5) {
+		t.Errorf("Expected a warning for 2 of 5 exposures of sample_experiment, received %+v", warnings[0])
+	}
+}
+
+func TestExposureReasonAllowlist(t *testing.T) {
+	testServer := getTestServer(testServerOptions{})
+	defer testServer.Close()
+
+	c := NewClientWithOptions("secret-key", &Options{
+		API:                     testServer.URL,
+		OutputLoggerOptions:     getOutputLoggerOptionsForTest(t),
+		StatsigLoggerOptions:    getStatsigLoggerOptionsForTest(t),
+		ExposureReasonAllowlist: []EvaluationReason{ReasonNone},
+	})
+	defer c.Shutdown()
+
+	user := User{UserID: "a-user"}
+	c.CheckGate(user, "not_a_real_gate")
+	if gate := c.GetGate(user, "always_on_gate"); gate.EvaluationDetails == nil || gate.EvaluationDetails.Source != SourceNetwork {
+		t.Fatalf("Expected always_on_gate to be evaluated from the network, received %+v", gate.EvaluationDetails)
+	}
+
+	c.logger.mu.Lock()
+	var gates []string
+	for _, event := range c.logger.events {
+		if exposure, ok := event.(ExposureEvent); ok {
+			gates = append(gates, exposure.Metadata["gate"])
+		}
+	}
+	c.logger.mu.Unlock()
+	if len(gates) != 1 || gates[0] != "always_on_gate" {
+		t.Errorf("Expected only the network evaluated exposure to be logged, received %v", gates)
+	}
+}
+
+func TestCustomEventSamplingRate(t *testing.T) {
+	c := newLocalModeClientForTest(t, nil, &Options{
+		LoggingMaxBufferSize:    10000,
+		CustomEventSamplingRate: map[string]int{"noisy_event": 2500},
+	})
+	defer c.Shutdown()
+
+	user := User{UserID: "a-user"}
+	metadata := map[string]string{"page": "home"}
+	for i := 0; i < 2000; i++ {
+		c.LogEvent(Event{EventName: "noisy_event", User: user, Metadata: metadata})
+	}
+	c.LogEvent(Event{EventName: "rare_event", User: user})
+
+	c.logger.mu.Lock()
+	sampled, rare := 0, 0
+	for _, event := range c.logger.events {
+		custom, ok := event.(Event)
+		if !ok {
+			continue
+		}
+		switch custom.EventName {
+		case "noisy_event":
+			sampled++
+			if custom.Metadata["samplingRate"] != "2500" || custom.Metadata["page"] != "home" {
+				t.Errorf("Expected sampled events to keep their metadata and carry the sampling rate, received %v", custom.Metadata)
+			}
+		case "rare_event":
+			rare++
+		}
+	}
+	c.logger.mu.Unlock()
+
+	if sampled < 350 || sampled > 650 {
+		t.Errorf("Expected roughly a quarter of 2000 events to be kept, received %d", sampled)
+	}
+	if rare != 1 {
+		t.Errorf("Expected events without a sampling rate to always be logged, received %d", rare)
+	}
+	if _, ok := metadata["samplingRate"]; ok {
+		t.Error("Expected the caller's metadata not to be modified")
+	}
+}
+
+func TestFlushEventsAndWait(t *testing.T) {
+	var mu sync.Mutex
+	received := 0
+	delay := time.Duration(0)
+	testServer := getTestServer(testServerOptions{
+		onLogEvent: func(events []map[string]interface{}) {
+			mu.Lock()
+			sleep := delay
+			mu.Unlock()
+			time.Sleep(sleep)
+			mu.Lock()
+			defer mu.Unlock()
+			for _, event := range events {
+				if event["eventName"] == "flush_event" {
+					received++
+				}
+			}
+		},
+	})
+	defer testServer.Close()
+	c := NewClientWithOptions("secret-key", &Options{
+		API:                  testServer.URL,
+		LoggingInterval:      time.Hour,
+		OutputLoggerOptions:  getOutputLoggerOptionsForTest(t),
+		StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+	})
+	defer c.Shutdown()
+	getReceived := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return received
+	}
+
+	for i := 0; i < 3; i++ {
+		c.LogEvent(Event{EventName: "flush_event", User: User{UserID: "123"}})
+	}
+	if err := c.FlushEventsAndWait(2 * time.Second); err != nil {
+		t.Errorf("Expected the flush to complete, received %v", err)
+	}
+	if getReceived() != 3 {
+		t.Errorf("Expected all 3 events to be sent once FlushEventsAndWait returned, received %d", getReceived())
+	}
+
+	mu.Lock()
+	delay = 300 * time.Millisecond
+	mu.Unlock()
+	c.LogEvent(Event{EventName: "flush_event", User: User{UserID: "123"}})
+	if err := c.FlushEventsAndWait(50 * time.Millisecond); err != ErrFlushTimeout {
+		t.Errorf("Expected ErrFlushTimeout for a slow send, received %v", err)
+	}
+	if err := c.FlushEventsAndWait(2 * time.Second); err != nil || getReceived() != 4 {
+		t.Errorf("Expected waiting again to cover the send still in flight, received %v with %d events", err, getReceived())
+	}
+
+	c.LogEvent(Event{EventName: "flush_event", User: User{UserID: "123"}})
+	start := time.Now()
+	c.FlushEvents()
+	if time.Since(start) > 200*time.Millisecond {
+		t.Error("Expected FlushEvents to return without waiting for the send")
+	}
+	if err := c.logger.waitForSends(2 * time.Second); err != nil || getReceived() != 5 {
+		t.Errorf("Expected FlushEvents to send the buffered event, received %d", getReceived())
+	}
+}
+
+func TestExposureWarmupDuration(t *testing.T) {
+	specs, _ := os.ReadFile("download_config_specs.json")
+	newClient := func(suppress bool) *Client {
+		return newLocalModeClientForTest(t, specs, &Options{
+			ExposureWarmupDuration:  200 * time.Millisecond,
+			SuppressWarmupExposures: suppress,
+		})
+	}
+	bufferedExposures := func(c *Client) []ExposureEvent {
+		c.logger.mu.Lock()
+		defer c.logger.mu.Unlock()
+		exposures := make([]ExposureEvent, 0)
+		for _, event := range c.logger.events {
+			if exposure, ok := event.(ExposureEvent); ok {
+				exposures = append(exposures, exposure)
+			}
+		}
+		return exposures
+	}
+	user := User{UserID: "a-user"}
+
+	c := newClient(false)
+	defer c.Shutdown()
+	c.CheckGate(user, "always_on_gate")
+	time.Sleep(250 * time.Millisecond)
+	c.CheckGate(user, "always_on_gate")
+	exposures := bufferedExposures(c)
+	if len(exposures) != 2 {
+		t.Fatalf("Expected 2 exposures, received %d", len(exposures))
+	}
+	if exposures[0].Metadata["warmup"] != "true" {
+		t.Errorf("Expected the exposure within the warm-up window to be tagged, received %v", exposures[0].Metadata)
+	}
+	if _, ok := exposures[1].Metadata["warmup"]; ok {
+		t.Errorf("Expected the exposure after the warm-up window to be untagged, received %v", exposures[1].Metadata)
+	}
+
+	suppressed := newClient(true)
+	defer suppressed.Shutdown()
+	suppressed.CheckGate(user, "always_on_gate")
+	if len(bufferedExposures(suppressed)) != 0 {
+		t.Error("Expected exposures within the warm-up window to be dropped when suppressed")
+	}
+}
+
+func TestEventQueueFullPolicy(t *testing.T) {
+	testServer := getTestServer(testServerOptions{})
+	defer testServer.Close()
+	var mu sync.Mutex
+	var loggedErrors []error
+	InitializeGlobalOutputLogger(OutputLoggerOptions{
+		LogCallback: func(message string, err error) {
+			mu.Lock()
+			loggedErrors = append(loggedErrors, err)
+			mu.Unlock()
+		},
+	})
+	defer InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+	user := User{UserID: "a-user"}
+
+	// Fills the buffer while a send is held back by MinLogEventInterval, then logs one more event
+	fillQueue := func(policy string, interval time.Duration) *Client {
+		c := NewClientWithOptions("secret-key", &Options{
+			API:                  testServer.URL,
+			LoggingMaxBufferSize: 2,
+			MinLogEventInterval:  interval,
+			EventQueueFullPolicy: policy,
+			StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+		})
+		c.LogEvent(Event{EventName: "event_1", User: user})
+		c.LogEvent(Event{EventName: "event_2", User: user})
+		for i := 0; i < 100 && c.QueuedEventCount() != 0; i++ {
+			time.Sleep(10 * time.Millisecond)
+		}
+		c.LogEvent(Event{EventName: "event_3", User: user})
+		c.LogEvent(Event{EventName: "event_4", User: user})
+		c.LogEvent(Event{EventName: "event_5", User: user})
+		return c
+	}
+	queuedNames := func(c *Client) []string {
+		c.logger.mu.Lock()
+		defer c.logger.mu.Unlock()
+		names := make([]string, 0)
+		for _, event := range c.logger.events {
+			if custom, ok := event.(Event); ok {
+				names = append(names, custom.EventName)
+			}
+		}
+		return names
+	}
+	reportedDrop := func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, err := range loggedErrors {
+			if errors.Is(err, ErrEventQueueFull) {
+				return true
+			}
+		}
+		return false
+	}
+
+	newest := fillQueue(EventQueueDropNewest, time.Hour)
+	if names := queuedNames(newest); !reflect.DeepEqual(names, []string{"event_3", "event_4"}) {
+		t.Errorf("Expected the newest event to be dropped, received %v", names)
+	}
+	if newest.QueuedEventCount() != 2 {
+		t.Errorf("Expected 2 queued events, received %d", newest.QueuedEventCount())
+	}
+	if !reportedDrop() {
+		t.Errorf("Expected the dropped event to be reported, received %v", loggedErrors)
+	}
+	newest.Shutdown()
+
+	oldest := fillQueue(EventQueueDropOldest, time.Hour)
+	if names := queuedNames(oldest); !reflect.DeepEqual(names, []string{"event_4", "event_5"}) {
+		t.Errorf("Expected the oldest event to be dropped, received %v", names)
+	}
+	oldest.Shutdown()
+
+	start := time.Now()
+	blocking := fillQueue(EventQueueBlock, 300*time.Millisecond)
+	defer blocking.Shutdown()
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Errorf("Expected logging to block until the held back send, took %v", elapsed)
+	}
+	if names := queuedNames(blocking); !reflect.DeepEqual(names, []string{"event_5"}) {
+		t.Errorf("Expected only the blocked event to remain queued, received %v", names)
+	}
+}
+
+func TestEventQueueFullPolicyWithSendsInFlight(t *testing.T) {
+	release := make(chan struct{})
+	testServer := getTestServer(testServerOptions{
+		onLogEvent: func(events []map[string]interface{}) {
+			<-release
+		},
+	})
+	defer testServer.Close()
+	var mu sync.Mutex
+	var drops []error
+	InitializeGlobalOutputLogger(OutputLoggerOptions{
+		LogCallback: func(message string, err error) {
+			if errors.Is(err, ErrEventQueueFull) {
+				mu.Lock()
+				drops = append(drops, err)
+				mu.Unlock()
+			}
+		},
+	})
+	defer InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+	c := NewClientWithOptions("secret-key", &Options{
+		API:                  testServer.URL,
+		LoggingMaxBufferSize: 2,
+		EventQueueFullPolicy: EventQueueDropNewest,
+		StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+	})
+	user := User{UserID: "a-user"}
+
+	for i := 0; i < 100; i++ {
+		c.LogEvent(Event{EventName: "event_" + strconv.Itoa(i), User: user})
+		time.Sleep(time.Millisecond)
+	}
+	if pending := c.logger.sendsInFlight(); pending > maxPendingEventSends {
+		t.Errorf("Expected at most %d sends in flight while log_event hangs, received %d", maxPendingEventSends, pending)
+	}
+	if queued := c.QueuedEventCount(); queued != 2 {
+		t.Errorf("Expected the buffer to stay full once the sends are capped, received %d queued events", queued)
+	}
+	mu.Lock()
+	if len(drops) != 1 {
+		t.Errorf("Expected the drops to be reported once, received %v", drops)
+	}
+	mu.Unlock()
+
+	close(release)
+	c.Shutdown()
+}
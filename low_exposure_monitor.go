@@ -0,0 +1,88 @@
+package statsig
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	lowExposureWarningEventName = "statsig::low_exposure_warning"
+	maxMonitoredExperiments     = 1000
+	defaultLowExposureWindow    = time.Hour
+)
+
+// Counts experiment exposures per window to flag experiments exposed fewer than Options.LowExposureWarningThreshold times.
+// Each experiment is flagged at most once. Bounded to maxMonitoredExperiments counters per window
+type lowExposureMonitor struct {
+	threshold   int
+	window      time.Duration
+	windowStart time.Time
+	counts      map[string]int
+	warned      map[string]bool
+	mu          sync.Mutex
+}
+
+func newLowExposureMonitor(options *Options) *lowExposureMonitor {
+	if options.LowExposureWarningThreshold <= 0 {
+		return nil
+	}
+	window := options.LowExposureWarningWindow
+	if window <= 0 {
+		window = defaultLowExposureWindow
+	}
+	return &lowExposureMonitor{
+		threshold:   options.LowExposureWarningThreshold,
+		window:      window,
+		windowStart: time.Now(),
+		counts:      make(map[string]int),
+		warned:      make(map[string]bool),
+	}
+}
+
+func (m *lowExposureMonitor) record(experiment string) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.warned[experiment] {
+		return
+	}
+	if _, ok := m.counts[experiment]; !ok && len(m.counts) >= maxMonitoredExperiments {
+		return
+	}
+	m.counts[experiment]++
+}
+
+// Closes the current window once it has elapsed, returning a warning event for each experiment exposed
+// fewer than threshold times during it, and starts counting the next window from zero
+func (m *lowExposureMonitor) collectWarnings(now time.Time) []diagnosticsEvent {
+	if m == nil {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if now.Sub(m.windowStart) < m.window {
+		return nil
+	}
+	var warnings []diagnosticsEvent
+	for experiment, count := range m.counts {
+		if count >= m.threshold {
+			continue
+		}
+		m.warned[experiment] = true
+		warnings = append(warnings, diagnosticsEvent{
+			EventName: lowExposureWarningEventName,
+			Time:      getUnixMilli(),
+			Metadata: map[string]interface{}{
+				"experiment": experiment,
+				"exposures":  count,
+				"threshold":  m.threshold,
+				"windowMs":   int64(m.window / time.Millisecond),
+			},
+		})
+	}
+	m.counts = make(map[string]int)
+	m.windowStart = now
+	return warnings
+}
@@ -0,0 +1,299 @@
+// Package openfeature resolves Statsig gates and config parameters in the shape of OpenFeature provider results.
+//
+// The types mirror the OpenFeature Go SDK provider contract without depending on it, so the
+// provider can be wrapped by a thin adapter in applications that already import the OpenFeature SDK.
+//
+// Flag keys without a separator resolve feature gates. Keys of the form "config:parameter"
+// resolve a single parameter of a dynamic config or experiment.
+package openfeature
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	statsig "github.com/statsig-io/go-sdk"
+)
+
+type Reason string
+
+const (
+	TargetingMatchReason Reason = "TARGETING_MATCH"
+	DefaultReason        Reason = "DEFAULT"
+	DisabledReason       Reason = "DISABLED"
+	StaticReason         Reason = "STATIC"
+	CachedReason         Reason = "CACHED"
+	StaleReason          Reason = "STALE"
+	UnknownReason        Reason = "UNKNOWN"
+	ErrorReason          Reason = "ERROR"
+)
+
+type ErrorCode string
+
+const (
+	ProviderNotReadyCode    ErrorCode = "PROVIDER_NOT_READY"
+	FlagNotFoundCode        ErrorCode = "FLAG_NOT_FOUND"
+	TypeMismatchCode        ErrorCode = "TYPE_MISMATCH"
+	TargetingKeyMissingCode ErrorCode = "TARGETING_KEY_MISSING"
+	GeneralCode             ErrorCode = "GENERAL"
+)
+
+const (
+	TargetingKey       = "targetingKey"
+	parameterSeparator = ":"
+)
+
+// The evaluation context, flattened to a map. TargetingKey becomes the UserID
+type FlattenedContext map[string]interface{}
+
+type ResolutionDetail struct {
+	Reason       Reason
+	ErrorCode    ErrorCode
+	ErrorMessage string
+	Variant      string
+	FlagMetadata map[string]interface{}
+}
+
+type BoolResolutionDetail struct {
+	Value bool
+	ResolutionDetail
+}
+
+type StringResolutionDetail struct {
+	Value string
+	ResolutionDetail
+}
+
+type FloatResolutionDetail struct {
+	Value float64
+	ResolutionDetail
+}
+
+type IntResolutionDetail struct {
+	Value int64
+	ResolutionDetail
+}
+
+type InterfaceResolutionDetail struct {
+	Value interface{}
+	ResolutionDetail
+}
+
+type Metadata struct {
+	Name string
+}
+
+type Provider struct {
+	client *statsig.Client
+}
+
+func NewProvider(client *statsig.Client) *Provider {
+	return &Provider{client: client}
+}
+
+func (p *Provider) Metadata() Metadata {
+	return Metadata{Name: "statsig-go-sdk"}
+}
+
+func (p *Provider) BooleanEvaluation(ctx context.Context, flag string, defaultValue bool, evalCtx FlattenedContext) BoolResolutionDetail {
+	user, detail, ok := toUser(evalCtx)
+	if !ok {
+		return BoolResolutionDetail{Value: defaultValue, ResolutionDetail: detail}
+	}
+	configName, parameter, hasParameter := splitFlag(flag)
+	if hasParameter {
+		value, detail := p.resolveParameter(ctx, user, configName, parameter)
+		if detail.ErrorCode != "" || value == nil {
+			return BoolResolutionDetail{Value: defaultValue, ResolutionDetail: detail}
+		}
+		if typed, ok := value.(bool); ok {
+			return BoolResolutionDetail{Value: typed, ResolutionDetail: detail}
+		}
+		return BoolResolutionDetail{Value: defaultValue, ResolutionDetail: typeMismatch(flag, "bool", value)}
+	}
+
+	gate := p.client.GetGateWithContext(ctx, user, flag)
+	details := gate.GetEvaluationDetails()
+	detail = resolutionDetail(flag, &details, gate.RuleID, gate.GroupName)
+	if detail.ErrorCode != "" {
+		return BoolResolutionDetail{Value: defaultValue, ResolutionDetail: detail}
+	}
+	return BoolResolutionDetail{Value: gate.Value, ResolutionDetail: detail}
+}
+
+func (p *Provider) StringEvaluation(ctx context.Context, flag string, defaultValue string, evalCtx FlattenedContext) StringResolutionDetail {
+	value, detail := p.resolveFlagParameter(ctx, flag, evalCtx)
+	if detail.ErrorCode != "" || value == nil {
+		return StringResolutionDetail{Value: defaultValue, ResolutionDetail: detail}
+	}
+	if typed, ok := value.(string); ok {
+		return StringResolutionDetail{Value: typed, ResolutionDetail: detail}
+	}
+	return StringResolutionDetail{Value: defaultValue, ResolutionDetail: typeMismatch(flag, "string", value)}
+}
+
+func (p *Provider) FloatEvaluation(ctx context.Context, flag string, defaultValue float64, evalCtx FlattenedContext) FloatResolutionDetail {
+	value, detail := p.resolveFlagParameter(ctx, flag, evalCtx)
+	if detail.ErrorCode != "" || value == nil {
+		return FloatResolutionDetail{Value: defaultValue, ResolutionDetail: detail}
+	}
+	if typed, ok := value.(float64); ok {
+		return FloatResolutionDetail{Value: typed, ResolutionDetail: detail}
+	}
+	return FloatResolutionDetail{Value: defaultValue, ResolutionDetail: typeMismatch(flag, "float", value)}
+}
+
+func (p *Provider) IntEvaluation(ctx context.Context, flag string, defaultValue int64, evalCtx FlattenedContext) IntResolutionDetail {
+	value, detail := p.resolveFlagParameter(ctx, flag, evalCtx)
+	if detail.ErrorCode != "" || value == nil {
+		return IntResolutionDetail{Value: defaultValue, ResolutionDetail: detail}
+	}
+	if typed, ok := value.(float64); ok && typed == float64(int64(typed)) {
+		return IntResolutionDetail{Value: int64(typed), ResolutionDetail: detail}
+	}
+	return IntResolutionDetail{Value: defaultValue, ResolutionDetail: typeMismatch(flag, "int", value)}
+}
+
+// Resolves a single parameter for "config:parameter" keys, or the whole config value for plain keys
+func (p *Provider) ObjectEvaluation(ctx context.Context, flag string, defaultValue interface{}, evalCtx FlattenedContext) InterfaceResolutionDetail {
+	user, detail, ok := toUser(evalCtx)
+	if !ok {
+		return InterfaceResolutionDetail{Value: defaultValue, ResolutionDetail: detail}
+	}
+	configName, parameter, hasParameter := splitFlag(flag)
+	if hasParameter {
+		value, detail := p.resolveParameter(ctx, user, configName, parameter)
+		if detail.ErrorCode != "" || value == nil {
+			return InterfaceResolutionDetail{Value: defaultValue, ResolutionDetail: detail}
+		}
+		return InterfaceResolutionDetail{Value: value, ResolutionDetail: detail}
+	}
+	config := p.client.GetConfigWithContext(ctx, user, flag)
+	detail = resolutionDetail(flag, config.EvaluationDetails, config.RuleID, config.GroupName)
+	if detail.ErrorCode != "" {
+		return InterfaceResolutionDetail{Value: defaultValue, ResolutionDetail: detail}
+	}
+	return InterfaceResolutionDetail{Value: config.Value, ResolutionDetail: detail}
+}
+
+func (p *Provider) resolveFlagParameter(ctx context.Context, flag string, evalCtx FlattenedContext) (interface{}, ResolutionDetail) {
+	user, detail, ok := toUser(evalCtx)
+	if !ok {
+		return nil, detail
+	}
+	configName, parameter, hasParameter := splitFlag(flag)
+	if !hasParameter {
+		return nil, errorDetail(TypeMismatchCode, fmt.Sprintf("flag %s must be of the form config%sparameter", flag, parameterSeparator))
+	}
+	return p.resolveParameter(ctx, user, configName, parameter)
+}
+
+// Returns a nil value with a DEFAULT reason when the config does not set the parameter
+func (p *Provider) resolveParameter(ctx context.Context, user statsig.User, configName string, parameter string) (interface{}, ResolutionDetail) {
+	config := p.client.GetConfigWithContext(ctx, user, configName)
+	detail := resolutionDetail(configName, config.EvaluationDetails, config.RuleID, config.GroupName)
+	if detail.ErrorCode != "" {
+		return nil, detail
+	}
+	value, ok := config.Value[parameter]
+	if !ok {
+		detail.Reason = DefaultReason
+		return nil, detail
+	}
+	return value, detail
+}
+
+// Configs that errored before evaluation carry no evaluation details, so a nil details is resolved from the rule ID alone
+func resolutionDetail(flag string, details *statsig.EvaluationDetails, ruleID string, groupName string) ResolutionDetail {
+	detail := ResolutionDetail{
+		Variant:      ruleID,
+		FlagMetadata: map[string]interface{}{"ruleID": ruleID, "groupName": groupName},
+	}
+	reason := statsig.ReasonNone
+	if details != nil {
+		if details.Source == statsig.SourceUninitialized {
+			return errorDetail(ProviderNotReadyCode, "statsig has not loaded any config specs")
+		}
+		detail.FlagMetadata["source"] = string(details.Source)
+		reason = details.Reason
+	}
+	switch reason {
+	case statsig.ReasonUnrecognized:
+		return errorDetail(FlagNotFoundCode, fmt.Sprintf("%s is not defined", flag))
+	case statsig.ReasonError:
+		return errorDetail(GeneralCode, fmt.Sprintf("%s could not be evaluated", flag))
+	case statsig.ReasonLocalOverride:
+		detail.Reason = StaticReason
+	case statsig.ReasonPersisted:
+		detail.Reason = CachedReason
+	case statsig.ReasonSegmentsNotReady:
+		detail.Reason = StaleReason
+	default:
+		switch ruleID {
+		case "default":
+			detail.Reason = DefaultReason
+		case "disabled":
+			detail.Reason = DisabledReason
+		default:
+			detail.Reason = TargetingMatchReason
+		}
+	}
+	return detail
+}
+
+func errorDetail(code ErrorCode, message string) ResolutionDetail {
+	return ResolutionDetail{Reason: ErrorReason, ErrorCode: code, ErrorMessage: message}
+}
+
+func typeMismatch(flag string, expected string, value interface{}) ResolutionDetail {
+	return errorDetail(TypeMismatchCode, fmt.Sprintf("%s is a %T, not a %s", flag, value, expected))
+}
+
+func splitFlag(flag string) (string, string, bool) {
+	parts := strings.SplitN(flag, parameterSeparator, 2)
+	if len(parts) != 2 {
+		return flag, "", false
+	}
+	return parts[0], parts[1], true
+}
+
+// Maps the well known user fields by their JSON names and places every other attribute in Custom
+func toUser(evalCtx FlattenedContext) (statsig.User, ResolutionDetail, bool) {
+	user := statsig.User{}
+	for key, value := range evalCtx {
+		str, isString := value.(string)
+		switch {
+		case key == TargetingKey && isString:
+			user.UserID = str
+		case key == "email" && isString:
+			user.Email = str
+		case key == "ip" && isString:
+			user.IpAddress = str
+		case key == "userAgent" && isString:
+			user.UserAgent = str
+		case key == "country" && isString:
+			user.Country = str
+		case key == "locale" && isString:
+			user.Locale = str
+		case key == "appVersion" && isString:
+			user.AppVersion = str
+		case key == "customIDs":
+			if ids, ok := value.(map[string]string); ok {
+				user.CustomIDs = ids
+			}
+		case key == "privateAttributes":
+			if attributes, ok := value.(map[string]interface{}); ok {
+				user.PrivateAttributes = attributes
+			}
+		default:
+			if user.Custom == nil {
+				user.Custom = make(map[string]interface{})
+			}
+			user.Custom[key] = value
+		}
+	}
+	if user.UserID == "" && len(user.CustomIDs) == 0 {
+		return user, errorDetail(TargetingKeyMissingCode, "evaluation context needs a targetingKey or customIDs"), false
+	}
+	return user, ResolutionDetail{}, true
+}
@@ -0,0 +1,76 @@
+package openfeature
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	statsig "github.com/statsig-io/go-sdk"
+)
+
+func getProviderForTest(t *testing.T) (*Provider, func()) {
+	specs, err := os.ReadFile("../download_config_specs.json")
+	if err != nil {
+		t.Fatalf("Failed to read specs: %s", err)
+	}
+	client := statsig.NewClientWithOptions("secret-key", &statsig.Options{
+		LocalMode:       true,
+		BootstrapValues: string(specs),
+		OutputLoggerOptions: statsig.OutputLoggerOptions{
+			LogCallback: func(message string, err error) {},
+		},
+	})
+	return NewProvider(client), client.Shutdown
+}
+
+func TestBooleanEvaluationFoundGate(t *testing.T) {
+	provider, shutdown := getProviderForTest(t)
+	defer shutdown()
+
+	res := provider.BooleanEvaluation(context.Background(), "always_on_gate", false, FlattenedContext{TargetingKey: "123"})
+	if !res.Value || res.Reason != TargetingMatchReason || res.ErrorCode != "" {
+		t.Errorf("Expected always_on_gate to resolve true with %s, received %+v", TargetingMatchReason, res)
+	}
+	if res.Variant == "" {
+		t.Error("Expected the rule ID as the variant")
+	}
+}
+
+func TestBooleanEvaluationMissingGate(t *testing.T) {
+	provider, shutdown := getProviderForTest(t)
+	defer shutdown()
+
+	res := provider.BooleanEvaluation(context.Background(), "not_a_gate", true, FlattenedContext{TargetingKey: "123"})
+	if !res.Value {
+		t.Error("Expected the default value for a missing gate")
+	}
+	if res.Reason != ErrorReason || res.ErrorCode != FlagNotFoundCode {
+		t.Errorf("Expected %s with %s, received %s with %s", ErrorReason, FlagNotFoundCode, res.Reason, res.ErrorCode)
+	}
+
+	res = provider.BooleanEvaluation(context.Background(), "always_on_gate", false, FlattenedContext{})
+	if res.Value || res.ErrorCode != TargetingKeyMissingCode {
+		t.Errorf("Expected the default value without a targeting key, received %+v", res)
+	}
+}
+
+func TestStringEvaluationConfigParameter(t *testing.T) {
+	provider, shutdown := getProviderForTest(t)
+	defer shutdown()
+	evalCtx := FlattenedContext{TargetingKey: "123", "email": "testuser@statsig.com"}
+
+	res := provider.StringEvaluation(context.Background(), "test_config:string", "fallback", evalCtx)
+	if res.Value != "statsig" || res.Reason != TargetingMatchReason {
+		t.Errorf("Expected test_config:string to resolve statsig with %s, received %+v", TargetingMatchReason, res)
+	}
+
+	res = provider.StringEvaluation(context.Background(), "test_config:number", "fallback", evalCtx)
+	if res.Value != "fallback" || res.ErrorCode != TypeMismatchCode {
+		t.Errorf("Expected a type mismatch for a number parameter, received %+v", res)
+	}
+
+	res = provider.StringEvaluation(context.Background(), "test_config:missing", "fallback", evalCtx)
+	if res.Value != "fallback" || res.Reason != DefaultReason || res.ErrorCode != "" {
+		t.Errorf("Expected the default value for an unset parameter, received %+v", res)
+	}
+}
@@ -0,0 +1,24 @@
+package statsig
+
+/**
+ * A source of overrides consulted on every evaluation, ahead of the overrides set with
+ * OverrideGate, OverrideConfig and OverrideLayer. Can be used to serve overrides from
+ * an external flag system or a watched file, and to override per user.
+ * Each method returns false when it has no override for the given user.
+ */
+type IOverrideProvider interface {
+	/**
+	 * Returns the overridden value of a gate for the user
+	 */
+	GateOverride(user User, gate string) (bool, bool)
+
+	/**
+	 * Returns the overridden value of a dynamic config or experiment for the user
+	 */
+	ConfigOverride(user User, config string) (map[string]interface{}, bool)
+
+	/**
+	 * Returns the overridden value of a layer for the user
+	 */
+	LayerOverride(user User, layer string) (map[string]interface{}, bool)
+}
@@ -1,6 +1,8 @@
 package statsig
 
 import (
+	"encoding/json"
+	"os"
 	"reflect"
 	"testing"
 )
@@ -75,3 +77,287 @@ func TestOverrides(t *testing.T) {
 		t.Errorf("Failed to get override value for a layer when in LocalMode")
 	}
 }
+
+func TestIsOverridden(t *testing.T) {
+	bytes, _ := os.ReadFile("download_config_specs.json")
+	c := newLocalModeClientForTest(t, bytes, nil)
+	defer c.Shutdown()
+	user := User{UserID: "123"}
+
+	gate := c.GetGate(user, "always_on_gate")
+	config := c.GetConfig(user, "test_config")
+	layer := c.GetLayer(user, "a_layer")
+	if gate.IsOverridden() || config.IsOverridden() || layer.IsOverridden() {
+		t.Error("Expected evaluated values to not be marked as overridden")
+	}
+
+	c.OverrideGate("always_on_gate", false)
+	c.OverrideConfig("test_config", map[string]interface{}{"number": 1})
+	c.OverrideLayer("a_layer", map[string]interface{}{"layer_param": false})
+	gate = c.GetGate(user, "always_on_gate")
+	config = c.GetConfig(user, "test_config")
+	layer = c.GetLayer(user, "a_layer")
+	if !gate.IsOverridden() {
+		t.Error("Expected overridden gate to be marked as overridden")
+	}
+	if !config.IsOverridden() {
+		t.Error("Expected overridden config to be marked as overridden")
+	}
+	if !layer.IsOverridden() {
+		t.Error("Expected overridden layer to be marked as overridden")
+	}
+}
+
+func TestForceDefaultConfigs(t *testing.T) {
+	treatment := publicRule("treatment")
+	treatment.ReturnValue = json.RawMessage(`{"color":"red"}`)
+	specs, _ := json.Marshal(downloadConfigSpecResponse{
+		HasUpdates: true,
+		Time:       1,
+		DynamicConfigs: []configSpec{
+			{Name: "broken_experiment", Type: "dynamic_config", Entity: "experiment", Enabled: true, DefaultValue: json.RawMessage(`{"color":"blue"}`), Rules: []configRule{treatment}},
+			{Name: "healthy_experiment", Type: "dynamic_config", Entity: "experiment", Enabled: true, DefaultValue: json.RawMessage(`{"color":"blue"}`), Rules: []configRule{treatment}},
+		},
+		LayerConfigs: []configSpec{
+			{Name: "broken_layer", Type: "dynamic_config", Entity: "layer", Enabled: true, DefaultValue: json.RawMessage(`{"color":"green"}`), Rules: []configRule{
+				{ID: "allocated", PassPercentage: 100, Conditions: []configCondition{{Type: "public"}}, ReturnValue: json.RawMessage(`{"color":"red"}`), ConfigDelegate: "broken_experiment"},
+			}},
+		},
+	})
+	var exposures []*ExposureEvent
+	c := newLocalModeClientForTest(t, specs, &Options{
+		ForceDefaultConfigs: []string{"broken_experiment"},
+		EvaluationCallbacks: EvaluationCallbacks{
+			ExperimentEvaluationCallback: func(name string, result DynamicConfig, exposure *ExposureEvent) {
+				exposures = append(exposures, exposure)
+			},
+		},
+	})
+	defer c.Shutdown()
+	user := User{UserID: "a-user"}
+
+	forced := c.GetExperiment(user, "broken_experiment")
+	if forced.GetString("color", "") != "blue" || forced.RuleID != "default" {
+		t.Errorf("Expected the default value, received %v from rule %s", forced.Value, forced.RuleID)
+	}
+	if forced.EvaluationDetails == nil || forced.EvaluationDetails.Reason != ReasonForcedDefault {
+		t.Errorf("Expected ReasonForcedDefault, received %+v", forced.EvaluationDetails)
+	}
+	if len(exposures) != 1 || exposures[0] == nil || exposures[0].Metadata["reason"] != "Bootstrap:ForcedDefault" {
+		t.Errorf("Expected the exposure to be logged with the forced reason, received %+v", exposures)
+	}
+
+	healthy := c.GetExperiment(user, "healthy_experiment")
+	if healthy.GetString("color", "") != "red" {
+		t.Errorf("Expected unlisted experiments to evaluate their rules, received %v", healthy.Value)
+	}
+
+	layer := c.GetLayerWithExposureLoggingDisabled(user, "broken_layer")
+	if layer.GetString("color", "") != "blue" {
+		t.Errorf("Expected a layer delegating to a forced experiment to serve its default, received %v", layer.Value)
+	}
+	response := c.GetClientInitializeResponseWithOptions(user, &GCIROptions{HashAlgorithm: "none"})
+	if color := response.DynamicConfigs["broken_experiment"].Value["color"]; color != "blue" {
+		t.Errorf("Expected the client initialize response to serve the default, received %v", color)
+	}
+	if color := response.LayerConfigs["broken_layer"].Value["color"]; color != "blue" {
+		t.Errorf("Expected the client initialize response layer to serve the forced default, received %v", color)
+	}
+}
+
+type userOverrideProvider struct {
+	gateUsers map[string]bool
+}
+
+func (p *userOverrideProvider) GateOverride(user User, gate string) (bool, bool) {
+	value, ok := p.gateUsers[user.UserID]
+	return value, ok && gate == "always_on_gate"
+}
+
+func (p *userOverrideProvider) ConfigOverride(user User, config string) (map[string]interface{}, bool) {
+	if user.UserID == "panicking-user" {
+		panic("provider unavailable")
+	}
+	return map[string]interface{}{"overridden_for": user.UserID}, config == "test_config" && user.UserID == "beta-user"
+}
+
+func (p *userOverrideProvider) LayerOverride(user User, layer string) (map[string]interface{}, bool) {
+	return nil, false
+}
+
+func TestOverrideProvider(t *testing.T) {
+	specs, _ := os.ReadFile("download_config_specs.json")
+	c := newLocalModeClientForTest(t, specs, &Options{
+		OverrideProvider: &userOverrideProvider{gateUsers: map[string]bool{"blocked-user": false, "beta-user": true}},
+	})
+	defer c.Shutdown()
+
+	blocked := c.GetGate(User{UserID: "blocked-user"}, "always_on_gate")
+	if blocked.Value || !blocked.IsOverridden() {
+		t.Errorf("Expected the provider to override the gate off for blocked-user, received %+v", blocked)
+	}
+	if !c.CheckGate(User{UserID: "another-user"}, "always_on_gate") {
+		t.Error("Expected users without a provided override to be evaluated")
+	}
+	if reasons := c.WhyNot(User{UserID: "blocked-user"}, "always_on_gate"); len(reasons) != 1 {
+		t.Errorf("Expected WhyNot to report the provided override, received %v", reasons)
+	}
+
+	c.OverrideGate("always_on_gate", false)
+	if !c.CheckGate(User{UserID: "beta-user"}, "always_on_gate") {
+		t.Error("Expected the provider to take precedence over OverrideGate")
+	}
+
+	config := c.GetConfig(User{UserID: "beta-user"}, "test_config")
+	if !reflect.DeepEqual(config.Value, map[string]interface{}{"overridden_for": "beta-user"}) || !config.IsOverridden() {
+		t.Errorf("Expected the provided config override, received %v", config.Value)
+	}
+	config = c.GetConfig(User{UserID: "panicking-user"}, "test_config")
+	if config.IsOverridden() || config.RuleID == "" {
+		t.Errorf("Expected a panicking provider to fall back to evaluation, received %+v", config)
+	}
+}
+
+func TestWithOverrides(t *testing.T) {
+	specs, _ := os.ReadFile("download_config_specs.json")
+	c := newLocalModeClientForTest(t, specs, nil)
+	defer c.Shutdown()
+	user := User{UserID: "123", Email: "testuser@statsig.com"}
+	c.OverrideGate("on_for_statsig_email", false)
+	originalConfig := c.GetConfig(user, "test_config").Value
+
+	scopedLayer := map[string]interface{}{"scoped": true}
+	restore := c.WithOverrides(Overrides{
+		Gates:   map[string]bool{"always_on_gate": false, "on_for_statsig_email": true},
+		Configs: map[string]map[string]interface{}{"test_config": {"scoped": true}},
+		Layers:  map[string]map[string]interface{}{"a_layer": scopedLayer},
+	})
+	if c.CheckGate(user, "always_on_gate") || !c.CheckGate(user, "on_for_statsig_email") {
+		t.Error("Expected the scoped gate overrides to apply")
+	}
+	if config := c.GetConfig(user, "test_config"); !config.IsOverridden() || config.Value["scoped"] != true {
+		t.Errorf("Expected the scoped config override, received %v", config.Value)
+	}
+	if layer := c.GetLayer(user, "a_layer"); !reflect.DeepEqual(layer.Value, scopedLayer) {
+		t.Errorf("Expected the scoped layer override, received %v", layer.Value)
+	}
+	c.OverrideGate("always_on_gate", false)
+
+	restore()
+	if !c.CheckGate(user, "always_on_gate") {
+		t.Error("Expected overrides set within the scope to be reverted")
+	}
+	if c.CheckGate(user, "on_for_statsig_email") {
+		t.Error("Expected the override set before the scope to be restored")
+	}
+	if config := c.GetConfig(user, "test_config"); config.IsOverridden() || !reflect.DeepEqual(config.Value, originalConfig) {
+		t.Errorf("Expected test_config to be evaluated again, received %v", config.Value)
+	}
+	if layer := c.GetLayer(user, "a_layer"); layer.IsOverridden() {
+		t.Error("Expected the layer override to be reverted")
+	}
+}
+
+func TestUserOverrides(t *testing.T) {
+	specs, _ := os.ReadFile("download_config_specs.json")
+	c := newLocalModeClientForTest(t, specs, nil)
+	defer c.Shutdown()
+	tester := User{UserID: "qa-account"}
+	other := User{UserID: "someone-else"}
+
+	c.OverrideGateForUser("qa-account", "always_on_gate", false)
+	gate := c.GetGate(tester, "always_on_gate")
+	if gate.Value || gate.EvaluationDetails.Reason != ReasonLocalOverride {
+		t.Errorf("Expected the gate to be overridden for the test account, received %+v", gate)
+	}
+	if !c.CheckGate(other, "always_on_gate") {
+		t.Error("Expected other users to be evaluated")
+	}
+
+	c.OverrideGate("always_on_gate", true)
+	c.OverrideGate("on_for_statsig_email", true)
+	if c.CheckGate(tester, "always_on_gate") {
+		t.Error("Expected the per user override to take precedence over the global one")
+	}
+	if !c.CheckGate(tester, "on_for_statsig_email") {
+		t.Error("Expected global overrides to apply to gates without a per user override")
+	}
+
+	qaConfig := map[string]interface{}{"qa": true}
+	c.OverrideConfigForUser("qa-account", "test_config", qaConfig)
+	c.OverrideLayerForUser("qa-account", "a_layer", qaConfig)
+	if config := c.GetConfig(tester, "test_config"); !reflect.DeepEqual(config.Value, qaConfig) || !config.IsOverridden() {
+		t.Errorf("Expected the per user config override, received %v", config.Value)
+	}
+	if layer := c.GetLayer(tester, "a_layer"); !reflect.DeepEqual(layer.Value, qaConfig) || !layer.IsOverridden() {
+		t.Errorf("Expected the per user layer override, received %v", layer.Value)
+	}
+	if config := c.GetConfig(other, "test_config"); config.IsOverridden() {
+		t.Error("Expected other users to get the evaluated config")
+	}
+
+	c.RemoveUserOverrides("qa-account")
+	if !c.CheckGate(tester, "always_on_gate") {
+		t.Error("Expected the global override to apply once the per user overrides are removed")
+	}
+	if config := c.GetConfig(tester, "test_config"); config.IsOverridden() {
+		t.Error("Expected the per user config override to be removed")
+	}
+	if layer := c.GetLayer(tester, "a_layer"); layer.IsOverridden() {
+		t.Error("Expected the per user layer override to be removed")
+	}
+}
+
+func TestRemoveOverrides(t *testing.T) {
+	specs, _ := os.ReadFile("download_config_specs.json")
+	c := newLocalModeClientForTest(t, specs, nil)
+	defer c.Shutdown()
+	user := User{UserID: "123", Email: "testuser@statsig.com"}
+	value := map[string]interface{}{"overridden": true}
+
+	c.OverrideGate("always_on_gate", false)
+	c.OverrideGate("on_for_statsig_email", false)
+	c.OverrideConfig("test_config", value)
+	c.OverrideLayer("a_layer", value)
+	c.OverrideGateForUser("qa-account", "always_on_gate", true)
+
+	expected := Overrides{
+		Gates:   map[string]bool{"always_on_gate": false, "on_for_statsig_email": false},
+		Configs: map[string]map[string]interface{}{"test_config": value},
+		Layers:  map[string]map[string]interface{}{"a_layer": value},
+		Users:   map[string]Overrides{"qa-account": {Gates: map[string]bool{"always_on_gate": true}}},
+	}
+	snapshot := c.GetAllOverrides()
+	if !reflect.DeepEqual(snapshot, expected) {
+		t.Errorf("Expected overrides %+v, received %+v", expected, snapshot)
+	}
+	snapshot.Gates["always_on_gate"] = true
+	if c.CheckGate(user, "always_on_gate") {
+		t.Error("Expected the snapshot to be a copy")
+	}
+
+	c.RemoveGateOverride("always_on_gate")
+	if !c.CheckGate(user, "always_on_gate") || c.CheckGate(user, "on_for_statsig_email") {
+		t.Error("Expected only the always_on_gate override to be removed")
+	}
+	c.RemoveConfigOverride("test_config")
+	if config := c.GetConfig(user, "test_config"); config.IsOverridden() {
+		t.Error("Expected the config override to be removed")
+	}
+	c.RemoveLayerOverride("a_layer")
+	if layer := c.GetLayer(user, "a_layer"); layer.IsOverridden() {
+		t.Error("Expected the layer override to be removed")
+	}
+	if len(c.GetAllOverrides().Users) != 1 {
+		t.Error("Expected removing global overrides to keep per user overrides")
+	}
+
+	c.RemoveAllOverrides()
+	overrides := c.GetAllOverrides()
+	if len(overrides.Gates) != 0 || len(overrides.Configs) != 0 || len(overrides.Layers) != 0 || len(overrides.Users) != 0 {
+		t.Errorf("Expected no overrides left, received %+v", overrides)
+	}
+	if !c.CheckGate(user, "on_for_statsig_email") {
+		t.Error("Expected gates to be evaluated once all overrides are removed")
+	}
+}
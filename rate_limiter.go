@@ -0,0 +1,86 @@
+package statsig
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+const maxRateLimitedUnits = 10000
+
+// Sliding-window event counters backing rate_limited conditions, keyed by counter key and unit ID.
+// Bounded to maxRateLimitedUnits counters, evicting the least recently used
+type rateLimiter struct {
+	counters map[string]*list.Element
+	lru      *list.List
+	maxUnits int
+	mu       sync.Mutex
+}
+
+type rateLimitCounter struct {
+	key    string
+	events []time.Time
+}
+
+func newRateLimiter(maxUnits int) *rateLimiter {
+	return &rateLimiter{
+		counters: make(map[string]*list.Element),
+		lru:      list.New(),
+		maxUnits: maxUnits,
+	}
+}
+
+// Records an event for the unit and returns whether it has exceeded limit events within the window ending now
+func (r *rateLimiter) recordAndCheck(key string, unitID string, now time.Time, window time.Duration, limit int) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	counterKey := key + "|" + unitID
+	var counter *rateLimitCounter
+	if elem, ok := r.counters[counterKey]; ok {
+		r.lru.MoveToFront(elem)
+		counter = elem.Value.(*rateLimitCounter)
+	} else {
+		counter = &rateLimitCounter{key: counterKey}
+		r.counters[counterKey] = r.lru.PushFront(counter)
+		if r.lru.Len() > r.maxUnits {
+			oldest := r.lru.Back()
+			r.lru.Remove(oldest)
+			delete(r.counters, oldest.Value.(*rateLimitCounter).key)
+		}
+	}
+
+	windowStart := now.Add(-window)
+	kept := counter.events[:0]
+	for _, t := range counter.events {
+		if t.After(windowStart) {
+			kept = append(kept, t)
+		}
+	}
+	kept = append(kept, now)
+	// Only the most recent limit+1 events are needed to know whether the limit was exceeded
+	if len(kept) > limit+1 {
+		kept = kept[len(kept)-(limit+1):]
+	}
+	counter.events = kept
+	return len(kept) > limit
+}
+
+// Returns whether the unit has already exceeded limit events within the window ending now, without recording an event
+func (r *rateLimiter) check(key string, unitID string, now time.Time, window time.Duration, limit int) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	elem, ok := r.counters[key+"|"+unitID]
+	if !ok {
+		return false
+	}
+	windowStart := now.Add(-window)
+	count := 0
+	for _, t := range elem.Value.(*rateLimitCounter).events {
+		if t.After(windowStart) {
+			count++
+		}
+	}
+	return count > limit
+}
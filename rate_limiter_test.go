@@ -0,0 +1,108 @@
+package statsig
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestRateLimitedCondition(t *testing.T) {
+	specs, _ := json.Marshal(downloadConfigSpecResponse{
+		HasUpdates: true,
+		Time:       1,
+		FeatureGates: []configSpec{
+			{Name: "too_many_logins", Type: "feature_gate", Enabled: true, Rules: []configRule{{
+				ID:             "rate_limited",
+				PassPercentage: 100,
+				Conditions: []configCondition{{
+					Type:             "rate_limited",
+					IDType:           "userID",
+					AdditionalValues: map[string]interface{}{"key": "login", "limit": 3, "window_ms": 60000},
+				}},
+			}}},
+		},
+	})
+	c := newLocalModeClientForTest(t, specs, nil)
+	defer c.Shutdown()
+	user := User{UserID: "123"}
+
+	for i := 1; i <= 3; i++ {
+		if c.CheckGate(user, "too_many_logins") {
+			t.Errorf("Expected gate to fail within the limit on event %d", i)
+		}
+	}
+	if !c.CheckGate(user, "too_many_logins") {
+		t.Error("Expected gate to pass once the limit is exceeded")
+	}
+	if c.CheckGate(User{UserID: "456"}, "too_many_logins") {
+		t.Error("Expected counters to be kept per unit ID")
+	}
+}
+
+func TestRateLimitedObservationalCallers(t *testing.T) {
+	specs, _ := json.Marshal(downloadConfigSpecResponse{
+		HasUpdates: true,
+		Time:       1,
+		FeatureGates: []configSpec{
+			{Name: "too_many_logins", Type: "feature_gate", Enabled: true, Rules: []configRule{{
+				ID:             "rate_limited",
+				PassPercentage: 100,
+				Conditions: []configCondition{{
+					Type:             "rate_limited",
+					IDType:           "userID",
+					AdditionalValues: map[string]interface{}{"key": "login", "limit": 3, "window_ms": 60000},
+				}},
+			}}},
+		},
+	})
+	c := newLocalModeClientForTest(t, specs, nil)
+	defer c.Shutdown()
+	user := User{UserID: "123"}
+
+	for i := 0; i < 3; i++ {
+		c.CheckGate(user, "too_many_logins")
+	}
+	for i := 0; i < 3; i++ {
+		c.WhyNot(user, "too_many_logins")
+		c.CompareUsers(user, user, "too_many_logins")
+		c.GetClientInitializeResponse(user, "", false)
+	}
+	c.evaluator.rateLimiter.mu.Lock()
+	recorded := len(c.evaluator.rateLimiter.counters["login|123"].Value.(*rateLimitCounter).events)
+	c.evaluator.rateLimiter.mu.Unlock()
+	if recorded != 3 {
+		t.Errorf("Expected only the 3 checks to be recorded, received %d", recorded)
+	}
+	if passed, _ := c.CompareUsers(user, user, "too_many_logins"); passed {
+		t.Error("Expected observational callers to see the unit within the limit")
+	}
+	if !c.CheckGate(user, "too_many_logins") {
+		t.Error("Expected the next check to exceed the limit")
+	}
+	if passed, _ := c.CompareUsers(user, user, "too_many_logins"); !passed {
+		t.Error("Expected observational callers to see the unit over the limit once a check exceeded it")
+	}
+}
+
+func TestRateLimiterWindowAndEviction(t *testing.T) {
+	limiter := newRateLimiter(2)
+	start := time.Now()
+	window := time.Minute
+
+	limiter.recordAndCheck("login", "a", start, window, 1)
+	if !limiter.recordAndCheck("login", "a", start.Add(time.Second), window, 1) {
+		t.Error("Expected second event within the window to exceed a limit of 1")
+	}
+	if limiter.recordAndCheck("login", "a", start.Add(2*window), window, 1) {
+		t.Error("Expected events outside the window to no longer count")
+	}
+
+	limiter.recordAndCheck("login", "b", start, window, 1)
+	limiter.recordAndCheck("login", "c", start, window, 1)
+	if len(limiter.counters) != 2 || limiter.lru.Len() != 2 {
+		t.Errorf("Expected counters to be bounded to 2 units, received %d", len(limiter.counters))
+	}
+	if _, ok := limiter.counters["login|a"]; ok {
+		t.Error("Expected the least recently used unit to be evicted")
+	}
+}
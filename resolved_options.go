@@ -0,0 +1,83 @@
+package statsig
+
+import (
+	"strings"
+	"time"
+)
+
+// The configuration a client is actually running with, after defaults and UpdateRuntimeOptions are applied
+type ResolvedOptions struct {
+	SDKKey                   string // Redacted to its prefix and last 4 characters
+	LocalMode                bool
+	ReadOnly                 bool
+	ConfigSyncInterval       time.Duration
+	IDListSyncInterval       time.Duration
+	LoggingInterval          time.Duration
+	LoggingMaxBufferSize     int
+	LoggingDisabled          bool
+	DownloadConfigSpecsAPI   string
+	GetIDListsAPI            string
+	LogEventAPI              string
+	FallbackToStatsigAPI     bool
+	DataAdapterEnabled       bool
+	BootstrapValuesProvided  bool
+	Source                   EvaluationSource // Where the config specs in use were last loaded from
+	DiagnosticsSamplingRates map[string]int   // Rates out of 10000 per diagnostics context, from the server merged with DiagnosticsSamplingOverride
+	CustomEventSamplingRate  map[string]int
+}
+
+func (c *Client) resolveOptions() ResolvedOptions {
+	store := c.evaluator.store
+	store.mu.RLock()
+	resolved := ResolvedOptions{
+		SDKKey:                  redactSDKKey(c.sdkKey),
+		LocalMode:               c.options.LocalMode,
+		ReadOnly:                c.options.ReadOnly,
+		ConfigSyncInterval:      store.configSyncInterval,
+		IDListSyncInterval:      store.idListSyncInterval,
+		DownloadConfigSpecsAPI:  c.transport.getAPI("/download_config_specs", false),
+		GetIDListsAPI:           c.transport.getAPI("/get_id_lists", false),
+		LogEventAPI:             c.transport.getAPI("/log_event", false),
+		FallbackToStatsigAPI:    c.options.FallbackToStatsigAPI,
+		DataAdapterEnabled:      store.dataAdapter != nil,
+		BootstrapValuesProvided: store.bootstrapValues != "",
+		Source:                  store.source,
+		CustomEventSamplingRate: copySamplingRates(c.options.CustomEventSamplingRate),
+	}
+	store.mu.RUnlock()
+
+	c.logger.mu.Lock()
+	resolved.LoggingInterval = c.logger.flushInterval
+	resolved.LoggingMaxBufferSize = c.logger.maxEvents
+	resolved.LoggingDisabled = c.logger.disabled
+	c.logger.mu.Unlock()
+
+	diagnostics := c.diagnostics.apiDiagnostics
+	diagnostics.mu.RLock()
+	resolved.DiagnosticsSamplingRates = copySamplingRates(diagnostics.samplingRates)
+	diagnostics.mu.RUnlock()
+	return resolved
+}
+
+func copySamplingRates(rates map[string]int) map[string]int {
+	if rates == nil {
+		return nil
+	}
+	copied := make(map[string]int, len(rates))
+	for key, rate := range rates {
+		copied[key] = rate
+	}
+	return copied
+}
+
+// Keeps the key type prefix, e.g. "secret-", and the last 4 characters so the key in use can still be told apart
+func redactSDKKey(sdkKey string) string {
+	prefix := ""
+	if i := strings.Index(sdkKey, "-"); i >= 0 {
+		prefix = sdkKey[:i+1]
+	}
+	if len(sdkKey)-len(prefix) <= 8 {
+		return prefix + "****"
+	}
+	return prefix + "****" + sdkKey[len(sdkKey)-4:]
+}
@@ -0,0 +1,146 @@
+package statsig
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestMaxSecondaryExposures(t *testing.T) {
+	gates := make([]configSpec, 0)
+	conditions := make([]configCondition, 0)
+	for i := 0; i < 10; i++ {
+		leaf := fmt.Sprintf("leaf_%d", i)
+		dep := fmt.Sprintf("dep_%d", i)
+		gates = append(gates, configSpec{Name: leaf, Type: "feature_gate", Enabled: true, Rules: []configRule{publicRule("public")}})
+		gates = append(gates, configSpec{Name: dep, Type: "feature_gate", Enabled: true, Rules: []configRule{{
+			Name:           dep,
+			ID:             dep,
+			PassPercentage: 100,
+			Conditions:     []configCondition{{Type: "pass_gate", TargetValue: leaf}},
+		}}})
+		conditions = append(conditions, configCondition{Type: "pass_gate", TargetValue: dep})
+	}
+	gates = append(gates, configSpec{Name: "many_deps", Type: "feature_gate", Enabled: true, Rules: []configRule{{
+		Name:           "many_deps",
+		ID:             "many_deps",
+		PassPercentage: 100,
+		Conditions:     conditions,
+	}}})
+	specs, _ := json.Marshal(downloadConfigSpecResponse{HasUpdates: true, Time: 1, FeatureGates: gates})
+
+	var exposure *ExposureEvent
+	c := newLocalModeClientForTest(t, specs, &Options{
+		MaxSecondaryExposures: 3,
+		EvaluationCallbacks: EvaluationCallbacks{
+			GateEvaluationCallback: func(name string, result bool, e *ExposureEvent) {
+				exposure = e
+			},
+		},
+	})
+	defer c.Shutdown()
+
+	if !c.CheckGate(User{UserID: "a-user"}, "many_deps") {
+		t.Error("Expected capping exposures to leave the gate value unaffected")
+	}
+	if exposure == nil {
+		t.Fatal("Expected an exposure for many_deps")
+	}
+	if len(exposure.SecondaryExposures) != 3 {
+		t.Errorf("Expected 3 secondary exposures, received %d", len(exposure.SecondaryExposures))
+	}
+	for _, secondary := range exposure.SecondaryExposures {
+		if !strings.HasPrefix(secondary.Gate, "dep_") {
+			t.Errorf("Expected direct dependencies to be kept first, received %s", secondary.Gate)
+		}
+	}
+	if exposure.Metadata["secondaryExposuresTruncated"] != "true" {
+		t.Error("Expected exposure metadata to flag truncated secondary exposures")
+	}
+
+	c.CheckGate(User{UserID: "a-user"}, "dep_0")
+	if len(exposure.SecondaryExposures) != 1 {
+		t.Errorf("Expected 1 secondary exposure for dep_0, received %d", len(exposure.SecondaryExposures))
+	}
+	if _, ok := exposure.Metadata["secondaryExposuresTruncated"]; ok {
+		t.Error("Expected no truncation flag when under the cap")
+	}
+}
+
+func TestSecondaryExposureFilter(t *testing.T) {
+	specs, _ := json.Marshal(downloadConfigSpecResponse{
+		HasUpdates: true,
+		Time:       1,
+		FeatureGates: []configSpec{
+			{Name: "noisy_holdout", Type: "feature_gate", Enabled: true, Rules: []configRule{publicRule("public")}},
+			{Name: "useful_dep", Type: "feature_gate", Enabled: true, Rules: []configRule{publicRule("public")}},
+			{Name: "target_gate", Type: "feature_gate", Enabled: true, Rules: []configRule{{
+				ID:             "target",
+				PassPercentage: 100,
+				Conditions: []configCondition{
+					{Type: "pass_gate", TargetValue: "noisy_holdout"},
+					{Type: "pass_gate", TargetValue: "useful_dep"},
+				},
+			}}},
+		},
+	})
+
+	var exposure *ExposureEvent
+	c := newLocalModeClientForTest(t, specs, &Options{
+		SecondaryExposureFilter: func(exposure SecondaryExposure) bool {
+			return exposure.Gate != "noisy_holdout"
+		},
+		EvaluationCallbacks: EvaluationCallbacks{
+			GateEvaluationCallback: func(name string, result bool, e *ExposureEvent) {
+				exposure = e
+			},
+		},
+	})
+	defer c.Shutdown()
+
+	if !c.CheckGate(User{UserID: "a-user"}, "target_gate") {
+		t.Error("Expected filtering exposures to leave the gate value unaffected")
+	}
+	if exposure == nil {
+		t.Fatal("Expected an exposure for target_gate")
+	}
+	if len(exposure.SecondaryExposures) != 1 || exposure.SecondaryExposures[0].Gate != "useful_dep" {
+		t.Errorf("Expected only useful_dep to remain in secondary exposures, received %+v", exposure.SecondaryExposures)
+	}
+}
+
+func TestSecondaryExposureRecorder(t *testing.T) {
+	specs, _ := json.Marshal(downloadConfigSpecResponse{
+		HasUpdates: true,
+		Time:       1,
+		FeatureGates: []configSpec{
+			{Name: "leaf_gate", Type: "feature_gate", Enabled: true, Rules: []configRule{publicRule("leaf_rule")}},
+			{Name: "parent_gate", Type: "feature_gate", Enabled: true, Rules: []configRule{{
+				ID:             "parent_rule",
+				PassPercentage: 100,
+				Conditions:     []configCondition{{Type: "pass_gate", TargetValue: "leaf_gate"}},
+			}}},
+		},
+	})
+
+	recorded := make(map[string][]SecondaryExposure)
+	c := newLocalModeClientForTest(t, specs, &Options{
+		SecondaryExposureRecorder: func(primary string, exposures []SecondaryExposure) {
+			recorded[primary] = exposures
+		},
+	})
+	defer c.Shutdown()
+
+	user := User{UserID: "a-user"}
+	c.CheckGate(user, "parent_gate")
+	c.CheckGate(user, "leaf_gate")
+
+	expected := SecondaryExposure{Gate: "leaf_gate", GateValue: "true", RuleID: "leaf_rule"}
+	if exposures := recorded["parent_gate"]; len(exposures) != 1 || exposures[0] != expected {
+		t.Errorf("Expected parent_gate to record %+v, received %+v", expected, exposures)
+	}
+	if _, ok := recorded["leaf_gate"]; ok {
+		t.Error("Expected no recording for a gate without dependencies")
+	}
+}
@@ -0,0 +1,74 @@
+package statsig
+
+// A read-only view of a loaded gate, config, experiment or layer definition.
+// Condition target values are omitted as they may contain sensitive data
+type SpecSummary struct {
+	Name    string
+	Type    string
+	Entity  string
+	Enabled bool
+	IDType  string
+	Rules   []RuleSummary
+}
+
+type RuleSummary struct {
+	ID             string
+	Name           string
+	GroupName      string
+	PassPercentage float64
+	ConfigDelegate string
+	Conditions     []ConditionSummary
+}
+
+type ConditionSummary struct {
+	Type     string
+	Operator string
+	Field    string
+	IDType   string
+}
+
+func newSpecSummary(spec configSpec) *SpecSummary {
+	rules := make([]RuleSummary, 0, len(spec.Rules))
+	for _, rule := range spec.Rules {
+		conditions := make([]ConditionSummary, 0, len(rule.Conditions))
+		for _, cond := range rule.Conditions {
+			conditions = append(conditions, ConditionSummary{
+				Type:     cond.Type,
+				Operator: cond.Operator,
+				Field:    cond.Field,
+				IDType:   cond.IDType,
+			})
+		}
+		rules = append(rules, RuleSummary{
+			ID:             rule.ID,
+			Name:           rule.Name,
+			GroupName:      rule.GroupName,
+			PassPercentage: rule.PassPercentage,
+			ConfigDelegate: rule.ConfigDelegate,
+			Conditions:     conditions,
+		})
+	}
+	return &SpecSummary{
+		Name:    spec.Name,
+		Type:    spec.Type,
+		Entity:  spec.Entity,
+		Enabled: spec.Enabled,
+		IDType:  spec.IDType,
+		Rules:   rules,
+	}
+}
+
+func (s *store) getSpecSummary(name string) *SpecSummary {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if spec, ok := s.featureGates[name]; ok {
+		return newSpecSummary(spec)
+	}
+	if spec, ok := s.dynamicConfigs[name]; ok {
+		return newSpecSummary(spec)
+	}
+	if spec, ok := s.layerConfigs[name]; ok {
+		return newSpecSummary(spec)
+	}
+	return nil
+}
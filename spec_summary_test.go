@@ -0,0 +1,41 @@
+package statsig
+
+import (
+	"os"
+	"testing"
+)
+
+func TestGetConfigSpecSummary(t *testing.T) {
+	bytes, _ := os.ReadFile("download_config_specs.json")
+	c := newLocalModeClientForTest(t, bytes, nil)
+	defer c.Shutdown()
+
+	summary := c.GetConfigSpecSummary("on_for_statsig_email")
+	if summary == nil {
+		t.Fatal("Expected a summary for on_for_statsig_email")
+	}
+	if !summary.Enabled || summary.Type != "feature_gate" {
+		t.Errorf("Unexpected summary %+v", summary)
+	}
+	if len(summary.Rules) != 1 {
+		t.Fatalf("Expected 1 rule, received %d", len(summary.Rules))
+	}
+	rule := summary.Rules[0]
+	if rule.ID != "7w9rbTSffLT89pxqpyhuqK" || rule.PassPercentage != 100 {
+		t.Errorf("Unexpected rule summary %+v", rule)
+	}
+	if len(rule.Conditions) != 1 {
+		t.Fatalf("Expected 1 condition, received %d", len(rule.Conditions))
+	}
+	cond := rule.Conditions[0]
+	if cond.Type != "user_field" || cond.Operator != "str_contains_any" || cond.Field != "email" {
+		t.Errorf("Unexpected condition summary %+v", cond)
+	}
+
+	if c.GetConfigSpecSummary("a_layer") == nil {
+		t.Error("Expected a summary for layer a_layer")
+	}
+	if c.GetConfigSpecSummary("not_a_spec") != nil {
+		t.Error("Expected no summary for an unknown spec")
+	}
+}
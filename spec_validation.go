@@ -0,0 +1,115 @@
+package statsig
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// A structural problem found in the loaded config specs, such as a rule referencing a gate that does not exist
+type SpecWarning struct {
+	SpecName      string
+	SpecType      string
+	RuleID        string
+	Reference     string
+	ReferenceType string
+	Message       string
+}
+
+// Walks every rule of the loaded specs and reports references to unknown gates, segments and configs
+func (s *store) validateSpecs() []SpecWarning {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	warnings := make([]SpecWarning, 0)
+	validate := func(specs map[string]configSpec) {
+		names := make([]string, 0, len(specs))
+		for name := range specs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			spec := specs[name]
+			for _, rule := range spec.Rules {
+				for _, cond := range rule.Conditions {
+					if !strings.EqualFold(cond.Type, "pass_gate") && !strings.EqualFold(cond.Type, "fail_gate") {
+						continue
+					}
+					gateName, ok := cond.TargetValue.(string)
+					if !ok {
+						continue
+					}
+					if _, exists := s.featureGates[gateName]; exists {
+						continue
+					}
+					referenceType := "gate"
+					if strings.HasPrefix(gateName, "segment:") {
+						referenceType = "segment"
+					}
+					warnings = append(warnings, SpecWarning{
+						SpecName:      spec.Name,
+						SpecType:      spec.Type,
+						RuleID:        rule.ID,
+						Reference:     gateName,
+						ReferenceType: referenceType,
+						Message:       fmt.Sprintf("%s condition references unknown %s %s", cond.Type, referenceType, gateName),
+					})
+				}
+				if rule.ConfigDelegate == "" {
+					continue
+				}
+				if _, exists := s.dynamicConfigs[rule.ConfigDelegate]; !exists {
+					warnings = append(warnings, SpecWarning{
+						SpecName:      spec.Name,
+						SpecType:      spec.Type,
+						RuleID:        rule.ID,
+						Reference:     rule.ConfigDelegate,
+						ReferenceType: "config",
+						Message:       fmt.Sprintf("rule delegates to unknown config %s", rule.ConfigDelegate),
+					})
+				}
+			}
+		}
+	}
+	validate(s.featureGates)
+	validate(s.dynamicConfigs)
+	validate(s.layerConfigs)
+	return warnings
+}
+
+// Checks that config specs, e.g. BootstrapValues, parse the way initialize parses them, without loading them into
+// any client. Meant for checking rule files in CI. When sdkKey is not empty, specs generated for another key are
+// rejected with an SDKKeyMismatchError as they would be on initialize
+func ValidateBootstrapValues(raw string, sdkKey string) error {
+	specs := downloadConfigSpecResponse{}
+	if err := json.Unmarshal([]byte(raw), &specs); err != nil {
+		return fmt.Errorf("bootstrap values are not valid config specs: %w", err)
+	}
+	if sdkKey != "" && specs.HashedSDKKeyUsed != "" && specs.HashedSDKKeyUsed != getDJB2Hash(sdkKey) {
+		return &SDKKeyMismatchError{Expected: getDJB2Hash(sdkKey), Received: specs.HashedSDKKeyUsed}
+	}
+	if !specs.HasUpdates {
+		return errors.New("bootstrap values have has_updates set to false, so no specs would be loaded")
+	}
+	for _, group := range [][]configSpec{specs.FeatureGates, specs.DynamicConfigs, specs.LayerConfigs} {
+		for _, spec := range group {
+			if err := validateSpecParses(spec); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Runs the parsing applied when specs are loaded, which panics on malformed user_bucket targets
+func validateSpecParses(spec configSpec) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%s %s has a malformed rule: %v", spec.Type, spec.Name, r)
+		}
+	}()
+	parseTargetValueMapFromSpec(&spec)
+	parseJSONValuesFromSpec(&spec)
+	return nil
+}
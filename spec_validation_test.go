@@ -0,0 +1,87 @@
+package statsig
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestValidateSpecs(t *testing.T) {
+	specs, _ := json.Marshal(downloadConfigSpecResponse{
+		HasUpdates: true,
+		Time:       1,
+		FeatureGates: []configSpec{
+			{Name: "existing_gate", Type: "feature_gate", Enabled: true, Rules: []configRule{publicRule("public")}},
+			{Name: "dangling_gate", Type: "feature_gate", Enabled: true, Rules: []configRule{{
+				ID:             "dangling_rule",
+				PassPercentage: 100,
+				Conditions: []configCondition{
+					{Type: "pass_gate", TargetValue: "existing_gate"},
+					{Type: "pass_gate", TargetValue: "missing_gate"},
+				},
+			}}},
+		},
+		LayerConfigs: []configSpec{
+			{Name: "a_layer", Type: "dynamic_config", Enabled: true, Rules: []configRule{{
+				ID:             "allocation",
+				PassPercentage: 100,
+				ConfigDelegate: "missing_experiment",
+				Conditions:     []configCondition{{Type: "public"}},
+			}}},
+		},
+	})
+	c := newLocalModeClientForTest(t, specs, nil)
+	defer c.Shutdown()
+
+	warnings := c.ValidateSpecs()
+	if len(warnings) != 2 {
+		t.Fatalf("Expected 2 warnings, received %+v", warnings)
+	}
+	if warnings[0].SpecName != "dangling_gate" || warnings[0].RuleID != "dangling_rule" ||
+		warnings[0].Reference != "missing_gate" || warnings[0].ReferenceType != "gate" {
+		t.Errorf("Unexpected warning for dangling pass_gate: %+v", warnings[0])
+	}
+	if warnings[1].SpecName != "a_layer" || warnings[1].Reference != "missing_experiment" || warnings[1].ReferenceType != "config" {
+		t.Errorf("Unexpected warning for dangling config delegate: %+v", warnings[1])
+	}
+}
+
+func TestValidateBootstrapValues(t *testing.T) {
+	valid, _ := os.ReadFile("download_config_specs.json")
+	if err := ValidateBootstrapValues(string(valid), ""); err != nil {
+		t.Errorf("Expected the fixture specs to be valid, got %s", err)
+	}
+
+	if err := ValidateBootstrapValues("{\"feature_gates\": [", ""); err == nil {
+		t.Errorf("Expected an error for malformed json")
+	}
+
+	noUpdates, _ := json.Marshal(downloadConfigSpecResponse{HasUpdates: false, Time: 1})
+	if err := ValidateBootstrapValues(string(noUpdates), ""); err == nil {
+		t.Errorf("Expected an error when has_updates is false")
+	}
+
+	keyed, _ := json.Marshal(downloadConfigSpecResponse{HasUpdates: true, Time: 1, HashedSDKKeyUsed: getDJB2Hash("secret-key")})
+	if err := ValidateBootstrapValues(string(keyed), "secret-key"); err != nil {
+		t.Errorf("Expected specs generated for the key to be valid, got %s", err)
+	}
+	var mismatch *SDKKeyMismatchError
+	if err := ValidateBootstrapValues(string(keyed), "secret-other"); !errors.As(err, &mismatch) {
+		t.Errorf("Expected an SDKKeyMismatchError, got %v", err)
+	}
+
+	badBucket, _ := json.Marshal(downloadConfigSpecResponse{
+		HasUpdates: true,
+		Time:       1,
+		FeatureGates: []configSpec{{Name: "bucket_gate", Type: "feature_gate", Enabled: true, Rules: []configRule{{
+			ID:             "bucket_rule",
+			PassPercentage: 100,
+			Conditions:     []configCondition{{Type: "user_bucket", Operator: "any", TargetValue: []interface{}{"not_a_number"}}},
+		}}}},
+	})
+	if err := ValidateBootstrapValues(string(badBucket), ""); err == nil || !strings.Contains(err.Error(), "bucket_gate") {
+		t.Errorf("Expected an error naming bucket_gate, got %v", err)
+	}
+}
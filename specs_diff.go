@@ -0,0 +1,48 @@
+package statsig
+
+import (
+	"reflect"
+	"sort"
+)
+
+// Names of the specs that changed in a config specs update, as passed to Options.SpecsUpdatedCallback.
+// On the first load every spec is reported as added
+type SpecsDiff struct {
+	AddedGates      []string
+	RemovedGates    []string
+	ModifiedGates   []string
+	AddedConfigs    []string // Dynamic configs and experiments
+	RemovedConfigs  []string
+	ModifiedConfigs []string
+	AddedLayers     []string
+	RemovedLayers   []string
+	ModifiedLayers  []string
+}
+
+func diffSpecMaps(previous map[string]configSpec, next map[string]configSpec) (added []string, removed []string, modified []string) {
+	for name, spec := range next {
+		previousSpec, ok := previous[name]
+		if !ok {
+			added = append(added, name)
+		} else if !reflect.DeepEqual(previousSpec, spec) {
+			modified = append(modified, name)
+		}
+	}
+	for name := range previous {
+		if _, ok := next[name]; !ok {
+			removed = append(removed, name)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	sort.Strings(modified)
+	return added, removed, modified
+}
+
+func diffSpecs(previousGates, previousConfigs, previousLayers, gates, configs, layers map[string]configSpec) SpecsDiff {
+	diff := SpecsDiff{}
+	diff.AddedGates, diff.RemovedGates, diff.ModifiedGates = diffSpecMaps(previousGates, gates)
+	diff.AddedConfigs, diff.RemovedConfigs, diff.ModifiedConfigs = diffSpecMaps(previousConfigs, configs)
+	diff.AddedLayers, diff.RemovedLayers, diff.ModifiedLayers = diffSpecMaps(previousLayers, layers)
+	return diff
+}
@@ -2,7 +2,10 @@
 package statsig
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"net"
 	"net/http"
 	"time"
 )
@@ -17,10 +20,17 @@ type Options struct {
 	LocalMode             bool        `json:"localMode"`
 	ConfigSyncInterval    time.Duration
 	IDListSyncInterval    time.Duration
+	LazyLoadIDLists       bool  // Serve evaluations as soon as specs load instead of waiting on ID lists. Segment based evaluations report ReasonSegmentsNotReady until lists load
+	MaxIDListBytes        int64 // Stops loading further IDs into a single ID list once it reaches this many bytes. 0 means no limit
 	LoggingInterval       time.Duration
 	LoggingMaxBufferSize  int
+	LoggingMaxBufferBytes int    // Flushes once the serialized size of buffered events reaches this many bytes. 0 means no byte limit
+	EventQueueFullPolicy  string // What logging does while the buffer is full and cannot be sent yet, e.g. while log_event is unreachable: EventQueueDropNewest, EventQueueDropOldest or EventQueueBlock. Empty keeps buffering
+	// Minimum time between log_event requests. Flushes triggered sooner, including by a full buffer, are coalesced into one send
+	MinLogEventInterval   time.Duration
 	BootstrapValues       string
 	RulesUpdatedCallback  func(rules string, time int64)
+	SpecsUpdatedCallback  func(changed SpecsDiff) // Called with the names of the gates, configs and layers added, removed or modified each time new config specs are applied
 	InitTimeout           time.Duration
 	DataAdapter           IDataAdapter
 	OutputLoggerOptions   OutputLoggerOptions
@@ -30,6 +40,54 @@ type Options struct {
 	UserPersistentStorage IUserPersistentStorage
 	IPCountryOptions      IPCountryOptions
 	UAParserOptions       UAParserOptions
+	// Called once per gate, config, experiment and layer evaluation with the matched rule, or the default rule, before any exposure is logged.
+	// Runs inline with the evaluation so it must be fast. Panics are recovered and logged
+	RuleEvaluationHook func(configName, ruleID string, passed bool, user User)
+	// Dials connections for the default transport, e.g. for custom DNS resolution. Ignored when Transport is set
+	DialContext           func(ctx context.Context, network, addr string) (net.Conn, error)
+	HTTPClientConfig      HTTPClientConfig               // Connection pool settings for the default transport. Ignored when Transport is set
+	UserFromContext       func(ctx context.Context) User // Derives the user for the *WithContext methods. Explicitly passed user fields take precedence
+	DefaultUser           User                           // Fields and custom attributes merged beneath every user evaluated or logged. Per-call user fields take precedence
+	MaxSecondaryExposures int                            // Caps the secondary exposures logged per evaluation, keeping direct dependencies first. 0 means no cap
+	AppVersionField       string                         // Custom attribute consulted for app_version conditions when User.AppVersion is empty
+	ExposureDebugWriter   io.Writer                      // Receives every exposure as a JSON line in addition to normal logging. Meant for local debugging
+	BucketingHasher       func(input string) uint64      // Replaces the sha256 based hash used for pass percentages and user_bucket conditions. WARNING: reassigns every user, and diverges from other Statsig SDKs and the console
+	IDListKeyHasher       func(id string) string         // Derives the key IDs are looked up by in ID lists, for lists built with another scheme. Defaults to the first 8 characters of the base64 encoded sha256 the server uses
+	ForceDefaultConfigs   []string                       // Configs and experiments that skip their rules and serve their default value with ReasonForcedDefault. Exposures are still logged
+	MaxEvaluationDuration time.Duration                  // Aborts a single evaluation that runs longer than this, serving the default value with ReasonTimeout. Checked between rules. 0 means no limit
+	ReadOnly              bool                           // For tools that only read: nothing is logged or written to storage, overrides and LogImmediate are rejected and no background flusher is started
+	EmptyUnitIDBehavior   string                         // EmptyUnitIDFail makes rules skip users without the rule's ID type. EmptyUnitIDHashEmpty, the default, buckets them all on an empty ID
+	OverrideProvider      IOverrideProvider              // Consulted for per user overrides before those set with OverrideGate, OverrideConfig and OverrideLayer
+	MaxRetries            int                            // Retries of requests failing with a retryable status. 0 keeps the defaults: 5 for log_event, none for config and ID list downloads, or 1 with FallbackToStatsigAPI. Negative disables retries
+	RetryBackoff          time.Duration                  // Wait before the first retry, multiplied by 10 before each later one. Defaults to a second
+	DefaultHeaders        map[string]string              // Sent on every config spec, ID list and log_event request, e.g. for a gateway. Headers the SDK sets itself, like STATSIG-API-KEY, are never overwritten
+
+	DefaultGateValuesOnError     map[string]bool                       // Values returned for the listed gates when their evaluation errors, instead of false
+	ExposureLogging              *ExposureLoggingOptions               // Turns off exposures per entity type. nil logs exposures for all of them
+	SecondaryExposureFilter      func(exposure SecondaryExposure) bool // Drops secondary exposures from logged events when it returns false. Evaluation is unaffected
+	InitializationSourcePriority []DataSource                          // Order in which the data adapter, bootstrap values and network are tried on initialize. Defaults to adapter, else bootstrap, then network
+	DiagnosticsSamplingOverride  map[string]int                        // Diagnostics sampling rates out of 10000 per context ("initialize", "config_sync", "api_call"), taking precedence over rates from the server
+	UserPersistedValuesCacheTTL  time.Duration                         // Serves repeated UserPersistentStorage loads of the same user and ID type from memory for this long. Saves and deletes invalidate the entry. 0 disables the cache
+	TreatEmptyUserAgentAsUnset   *bool                                 // Whether an empty User.UserAgent counts as missing, so it never matches eq "". nil means true. When false it is read like the other string fields
+	CaseInsensitiveConfigNames   bool                                  // Resolves gate, config, experiment and layer names regardless of case. Of names differing only by case, the first is used
+	LowExposureWarningThreshold  int                                   // Logs a one time statsig::low_exposure_warning event for experiments exposed fewer times than this within a window. 0 disables the check
+	LowExposureWarningWindow     time.Duration                         // Length of each window counted against LowExposureWarningThreshold, starting at initialization. Defaults to an hour
+	CustomEventSamplingRate      map[string]int                        // Keeps custom events with the given names at this rate out of 10000, recording the rate in their samplingRate metadata for reweighting
+	EnvironmentTierAliases       map[string][]string                   // Additional tiers a tier also matches in environment_field tier conditions, e.g. "staging": {"non-production"}. Followed transitively
+	ExposureReasonAllowlist      []EvaluationReason                    // Only logs exposures whose evaluation reason is listed, e.g. ReasonNone to skip unrecognized names and overrides. Empty logs every reason
+	ClearUnresolvedPlaceholders  bool                                  // Makes DynamicConfig.GetStringInterpolated remove ${name} placeholders the user has no value for, instead of keeping them as written
+	ExposureWarmupDuration       time.Duration                         // Tags exposures logged within this long of creating the client with "warmup": "true" metadata, so analytics can exclude them
+	SuppressWarmupExposures      bool                                  // Drops the exposures within ExposureWarmupDuration instead of tagging them
+	DuplicateConfigNameBehavior  string                                // DuplicateNameKeepFirst keeps the first of several gates, configs or layers sharing a name. DuplicateNameKeepLast, the default, keeps the last. Duplicates are logged either way
+	// Builds the download_config_specs request in place of the default, e.g. to pass sinceTime the way a proxy expects.
+	// Responses are parsed as usual. Retries resend the built request
+	DownloadConfigSpecsRequestBuilder func(sinceTime int64, sdkKey string) (*http.Request, error)
+	// Receives the secondary exposures of every gate, config, experiment and layer evaluation that has any, keyed by the
+	// unhashed name evaluated. Called inline after evaluation, so it must be fast. Panics are recovered and logged
+	SecondaryExposureRecorder func(primary string, exposures []SecondaryExposure)
+	// Overrides the class of failed requests, deciding which are retried, e.g. to stop retrying a status.
+	// statusCode is 0 when there was no response. Returning "" keeps the default class
+	NetworkErrorClassifier func(statusCode int, err error) NetworkErrorClass
 }
 
 type APIOverrides struct {
@@ -38,6 +96,14 @@ type APIOverrides struct {
 	LogEvent            string `json:"log_event"`
 }
 
+// Evaluation still runs for disabled entity types, only their exposure events are skipped. Unset fields keep logging
+type ExposureLoggingOptions struct {
+	DisableGates       bool
+	DisableConfigs     bool
+	DisableExperiments bool
+	DisableLayers      bool
+}
+
 type EvaluationCallbacks struct {
 	GateEvaluationCallback       func(name string, result bool, exposure *ExposureEvent)
 	ConfigEvaluationCallback     func(name string, result DynamicConfig, exposure *ExposureEvent)
@@ -67,6 +133,13 @@ type IPCountryOptions struct {
 	EnsureLoaded bool // Wait until loaded when needed
 }
 
+// Zero values keep the http.DefaultTransport settings
+type HTTPClientConfig struct {
+	MaxIdleConns        int
+	MaxIdleConnsPerHost int
+	IdleConnTimeout     time.Duration
+}
+
 type UAParserOptions struct {
 	Disabled     bool // Fully disable UA parser
 	LazyLoad     bool // Load in background
@@ -85,6 +158,11 @@ type GCIROptions struct {
 	ClientKey             string
 	TargetAppID           string
 	HashAlgorithm         string
+	DedupedExposureFormat bool       // Sends each secondary exposure once in a shared Exposures pool, with secondary_exposures holding keys into it as client SDKs expect
+	LogExposures          bool       // Logs an exposure for every gate, config and layer evaluated into the response
+	RedactUserInResponse  bool       // Leaves only the environment of the user in User and drops the IDs from EvaluatedKeys. Evaluated values are unaffected
+	LegacyValueFormat     bool       // Also sends gates as {"gates": {name: value}} and configs as {"configs": {name: {"value": value, "group": ruleID}}} for client SDKs that predate feature_gates and dynamic_configs
+	Format                GCIRFormat // Encoding used by GetClientInitializeResponseBytes. Defaults to GCIRFormatJSON
 }
 
 type InitializeDetails struct {
@@ -92,6 +170,7 @@ type InitializeDetails struct {
 	Success  bool
 	Error    error
 	Source   EvaluationSource
+	Markers  []DiagnosticMarker // Timings of each step of initialization, e.g. the download_config_specs network request and its processing. Empty with StatsigLoggerOptions.DisableInitDiagnostics
 }
 
 var instance *Client
@@ -117,12 +196,25 @@ func InitializeWithOptions(sdkKey string, options *Options) InitializeDetails {
 
 	var context *initContext
 	instance, context = newClientImpl(sdkKey, options)
-	return InitializeDetails{
-		Duration: time.Since(context.Start),
-		Success:  context.Success,
-		Error:    context.Error,
-		Source:   context.Source,
+	return instance.getInitializeDetails(context)
+}
+
+// Initializes the global Statsig instance with the given sdkKey and options in the background.
+// The instance is usable immediately and serves default values until the returned channel receives the details of initialization
+func InitializeAsync(sdkKey string, options *Options) <-chan InitializeDetails {
+	InitializeGlobalOutputLogger(options.OutputLoggerOptions)
+	InitializeGlobalSessionID()
+	if IsInitialized() {
+		Logger().Log("Statsig is already initialized.", nil)
+		channel := make(chan InitializeDetails, 1)
+		channel <- InitializeDetails{Success: true, Source: instance.evaluator.store.source}
+		close(channel)
+		return channel
 	}
+
+	var channel <-chan InitializeDetails
+	instance, channel = NewClientAsync(sdkKey, options)
+	return channel
 }
 
 // Checks the value of a Feature Gate for the given user
@@ -141,6 +233,22 @@ func CheckGateWithExposureLoggingDisabled(user User, gate string) bool {
 	return instance.CheckGateWithExposureLoggingDisabled(user, gate)
 }
 
+// Checks the value of a Feature Gate for the given user with configurable options
+func CheckGateWithOptions(user User, gate string, options *GetGateOptions) bool {
+	if !IsInitialized() {
+		panic(fmt.Errorf("must Initialize() statsig before calling CheckGateWithOptions"))
+	}
+	return instance.CheckGateWithOptions(user, gate, options)
+}
+
+// Checks the value of a Feature Gate for the user derived from the given context, merged with the given user
+func CheckGateWithContext(ctx context.Context, user User, gate string) bool {
+	if !IsInitialized() {
+		panic(fmt.Errorf("must Initialize() statsig before calling CheckGateWithContext"))
+	}
+	return instance.CheckGateWithContext(ctx, user, gate)
+}
+
 // Get the Feature Gate for the given user
 func GetGate(user User, gate string) FeatureGate {
 	if !IsInitialized() {
@@ -157,6 +265,22 @@ func GetGateWithExposureLoggingDisabled(user User, gate string) FeatureGate {
 	return instance.GetGateWithExposureLoggingDisabled(user, gate)
 }
 
+// Get the Feature Gate for the given user with configurable options
+func GetGateWithOptions(user User, gate string, options *GetGateOptions) FeatureGate {
+	if !IsInitialized() {
+		panic(fmt.Errorf("must Initialize() statsig before calling GetGateWithOptions"))
+	}
+	return instance.GetGateWithOptions(user, gate, options)
+}
+
+// Get the Feature Gate for the user derived from the given context, merged with the given user
+func GetGateWithContext(ctx context.Context, user User, gate string) FeatureGate {
+	if !IsInitialized() {
+		panic(fmt.Errorf("must Initialize() statsig before calling GetGateWithContext"))
+	}
+	return instance.GetGateWithContext(ctx, user, gate)
+}
+
 // Logs an exposure event for the gate
 func ManuallyLogGateExposure(user User, config string) {
 	if !IsInitialized() {
@@ -181,6 +305,14 @@ func GetConfigWithExposureLoggingDisabled(user User, config string) DynamicConfi
 	return instance.GetConfigWithExposureLoggingDisabled(user, config)
 }
 
+// Gets the DynamicConfig value for the user derived from the given context, merged with the given user
+func GetConfigWithContext(ctx context.Context, user User, config string) DynamicConfig {
+	if !IsInitialized() {
+		panic(fmt.Errorf("must Initialize() statsig before calling GetConfigWithContext"))
+	}
+	return instance.GetConfigWithContext(ctx, user, config)
+}
+
 // Logs an exposure event for the dynamic config
 func ManuallyLogConfigExposure(user User, config string) {
 	if !IsInitialized() {
@@ -189,6 +321,22 @@ func ManuallyLogConfigExposure(user User, config string) {
 	instance.ManuallyLogConfigExposure(user, config)
 }
 
+// Gets the hashed members of every loaded ID list of the Statsig client. Slow for large lists
+func ExportIDLists() map[string][]string {
+	if !IsInitialized() {
+		panic(fmt.Errorf("must Initialize() statsig before calling ExportIDLists"))
+	}
+	return instance.ExportIDLists()
+}
+
+// Logs a manual exposure for each entry, skipping duplicates
+func ManuallyLogExposures(user User, entries []ManualExposureEntry) {
+	if !IsInitialized() {
+		panic(fmt.Errorf("must Initialize() statsig before calling ManuallyLogExposures"))
+	}
+	instance.ManuallyLogExposures(user, entries)
+}
+
 // Override the value of a Feature Gate for the given user
 func OverrideGate(gate string, val bool) {
 	if !IsInitialized() {
@@ -213,6 +361,86 @@ func OverrideLayer(layer string, val map[string]interface{}) {
 	instance.OverrideLayer(layer, val)
 }
 
+// Removes the override set by OverrideGate
+func RemoveGateOverride(gate string) {
+	if !IsInitialized() {
+		panic(fmt.Errorf("must Initialize() statsig before calling RemoveGateOverride"))
+	}
+	instance.RemoveGateOverride(gate)
+}
+
+// Removes the override set by OverrideConfig
+func RemoveConfigOverride(config string) {
+	if !IsInitialized() {
+		panic(fmt.Errorf("must Initialize() statsig before calling RemoveConfigOverride"))
+	}
+	instance.RemoveConfigOverride(config)
+}
+
+// Removes the override set by OverrideLayer
+func RemoveLayerOverride(layer string) {
+	if !IsInitialized() {
+		panic(fmt.Errorf("must Initialize() statsig before calling RemoveLayerOverride"))
+	}
+	instance.RemoveLayerOverride(layer)
+}
+
+// Removes every gate, config and layer override, including those set for single users
+func RemoveAllOverrides() {
+	if !IsInitialized() {
+		panic(fmt.Errorf("must Initialize() statsig before calling RemoveAllOverrides"))
+	}
+	instance.RemoveAllOverrides()
+}
+
+// Gets a snapshot of the overrides currently set
+func GetAllOverrides() Overrides {
+	if !IsInitialized() {
+		panic(fmt.Errorf("must Initialize() statsig before calling GetAllOverrides"))
+	}
+	return instance.GetAllOverrides()
+}
+
+// Override the value of a Feature Gate for the user with the given ID only
+func OverrideGateForUser(userID string, gate string, val bool) {
+	if !IsInitialized() {
+		panic(fmt.Errorf("must Initialize() statsig before calling OverrideGateForUser"))
+	}
+	instance.OverrideGateForUser(userID, gate, val)
+}
+
+// Override the DynamicConfig value for the user with the given ID only
+func OverrideConfigForUser(userID string, config string, val map[string]interface{}) {
+	if !IsInitialized() {
+		panic(fmt.Errorf("must Initialize() statsig before calling OverrideConfigForUser"))
+	}
+	instance.OverrideConfigForUser(userID, config, val)
+}
+
+// Override the Layer value for the user with the given ID only
+func OverrideLayerForUser(userID string, layer string, val map[string]interface{}) {
+	if !IsInitialized() {
+		panic(fmt.Errorf("must Initialize() statsig before calling OverrideLayerForUser"))
+	}
+	instance.OverrideLayerForUser(userID, layer, val)
+}
+
+// Removes every override set for the user with the given ID
+func RemoveUserOverrides(userID string) {
+	if !IsInitialized() {
+		panic(fmt.Errorf("must Initialize() statsig before calling RemoveUserOverrides"))
+	}
+	instance.RemoveUserOverrides(userID)
+}
+
+// Applies a set of overrides at once and returns a function that restores the overrides set before
+func WithOverrides(overrides Overrides) func() {
+	if !IsInitialized() {
+		panic(fmt.Errorf("must Initialize() statsig before calling WithOverrides"))
+	}
+	return instance.WithOverrides(overrides)
+}
+
 // Gets the name of layer an Experiment
 func GetExperimentLayer(experiment string) (string, bool) {
 	if !IsInitialized() {
@@ -221,6 +449,22 @@ func GetExperimentLayer(experiment string) (string, bool) {
 	return instance.GetExperimentLayer(experiment)
 }
 
+// Gets the experiment the user is allocated to in a layer, and whether they are allocated to one, without logging an exposure
+func GetLayerAllocatedExperiment(user User, layer string) (string, bool) {
+	if !IsInitialized() {
+		panic(fmt.Errorf("must Initialize() statsig before calling GetLayerAllocatedExperiment"))
+	}
+	return instance.GetLayerAllocatedExperiment(user, layer)
+}
+
+// Gets the layer name of an Experiment, telling an experiment that is not in any layer apart from an unknown one
+func GetExperimentLayerInfo(experiment string) ExperimentLayerInfo {
+	if !IsInitialized() {
+		panic(fmt.Errorf("must Initialize() statsig before calling GetExperimentLayerInfo"))
+	}
+	return instance.GetExperimentLayerInfo(experiment)
+}
+
 // Gets the DynamicConfig value of an Experiment for the given user
 func GetExperiment(user User, experiment string) DynamicConfig {
 	if !IsInitialized() {
@@ -245,6 +489,14 @@ func GetExperimentWithOptions(user User, experiment string, options *GetExperime
 	return instance.GetExperimentWithOptions(user, experiment, options)
 }
 
+// Gets the DynamicConfig value of an Experiment for the user derived from the given context, merged with the given user
+func GetExperimentWithContext(ctx context.Context, user User, experiment string) DynamicConfig {
+	if !IsInitialized() {
+		panic(fmt.Errorf("must Initialize() statsig before calling GetExperimentWithContext"))
+	}
+	return instance.GetExperimentWithContext(ctx, user, experiment)
+}
+
 // Logs an exposure event for the experiment
 func ManuallyLogExperimentExposure(user User, experiment string) {
 	if !IsInitialized() {
@@ -253,6 +505,54 @@ func ManuallyLogExperimentExposure(user User, experiment string) {
 	instance.ManuallyLogExperimentExposure(user, experiment)
 }
 
+// Reports rules in the loaded specs that reference gates, segments or configs which do not exist
+func ValidateSpecs() []SpecWarning {
+	if !IsInitialized() {
+		panic(fmt.Errorf("must Initialize() statsig before calling ValidateSpecs"))
+	}
+	return instance.ValidateSpecs()
+}
+
+// Gets a read-only summary of the rules of a loaded gate, config, experiment or layer
+func GetConfigSpecSummary(name string) *SpecSummary {
+	if !IsInitialized() {
+		panic(fmt.Errorf("must Initialize() statsig before calling GetConfigSpecSummary"))
+	}
+	return instance.GetConfigSpecSummary(name)
+}
+
+// Gets every gate and config the given gate depends on, walking the loaded specs without evaluating a user
+func GateDependencyTree(gate string) DependencyTree {
+	if !IsInitialized() {
+		panic(fmt.Errorf("must Initialize() statsig before calling GateDependencyTree"))
+	}
+	return instance.GateDependencyTree(gate)
+}
+
+// Gets the names of the ID lists the given gate reads, including through the gates it depends on
+func GateIDListDependencies(gate string) []string {
+	if !IsInitialized() {
+		panic(fmt.Errorf("must Initialize() statsig before calling GateIDListDependencies"))
+	}
+	return instance.GateIDListDependencies(gate)
+}
+
+// Explains why a gate did not pass for the given user. Returns an empty slice when the gate passes
+func WhyNot(user User, gate string) []string {
+	if !IsInitialized() {
+		panic(fmt.Errorf("must Initialize() statsig before calling WhyNot"))
+	}
+	return instance.WhyNot(user, gate)
+}
+
+// Checks a gate for two users, returning the value for userA, then for userB. No exposures are logged
+func CompareUsers(userA User, userB User, gate string) (bool, bool) {
+	if !IsInitialized() {
+		panic(fmt.Errorf("must Initialize() statsig before calling CompareUsers"))
+	}
+	return instance.CompareUsers(userA, userB, gate)
+}
+
 func GetUserPersistedValues(user User, idType string) UserPersistedValues {
 	if !IsInitialized() {
 		panic(fmt.Errorf("must Initialize() statsig before calling GetUserPersistedValues"))
@@ -260,6 +560,14 @@ func GetUserPersistedValues(user User, idType string) UserPersistedValues {
 	return instance.GetUserPersistedValues(user, idType)
 }
 
+// Loads the user's persisted values, unless ctx is done first
+func GetUserPersistedValuesWithContext(ctx context.Context, user User, idType string) UserPersistedValues {
+	if !IsInitialized() {
+		panic(fmt.Errorf("must Initialize() statsig before calling GetUserPersistedValuesWithContext"))
+	}
+	return instance.GetUserPersistedValuesWithContext(ctx, user, idType)
+}
+
 // Gets the Layer object for the given user
 func GetLayer(user User, layer string) Layer {
 	if !IsInitialized() {
@@ -284,6 +592,22 @@ func GetLayerWithOptions(user User, layer string, options *GetLayerOptions) Laye
 	return instance.GetLayerWithOptions(user, layer, options)
 }
 
+// Gets the Layer object for the user derived from the given context, merged with the given user
+func GetLayerWithContext(ctx context.Context, user User, layer string) Layer {
+	if !IsInitialized() {
+		panic(fmt.Errorf("must Initialize() statsig before calling GetLayerWithContext"))
+	}
+	return instance.GetLayerWithContext(ctx, user, layer)
+}
+
+// Gets the Layer objects for the given user, normalizing the user once
+func GetLayers(user User, layers []string) map[string]Layer {
+	if !IsInitialized() {
+		panic(fmt.Errorf("must Initialize() statsig before calling GetLayers"))
+	}
+	return instance.GetLayers(user, layers)
+}
+
 // Logs an exposure event for the parameter in the given layer
 func ManuallyLogLayerParameterExposure(user User, layer string, parameter string) {
 	if !IsInitialized() {
@@ -322,6 +646,14 @@ func GetClientInitializeResponseWithOptions(user User, options *GCIROptions) Cli
 	return instance.GetClientInitializeResponseWithOptions(user, options)
 }
 
+// Gets the client initialize response encoded in options.Format, e.g. GCIRFormatMessagePack for constrained clients
+func GetClientInitializeResponseBytes(user User, options *GCIROptions) ([]byte, error) {
+	if !IsInitialized() {
+		panic(fmt.Errorf("must Initialize() statsig before calling GetClientInitializeResponseBytes"))
+	}
+	return instance.GetClientInitializeResponseBytes(user, options)
+}
+
 func GetClientInitializeResponseForTargetApp(user User, clientKey string) ClientInitializeResponse {
 	if !IsInitialized() {
 		panic(fmt.Errorf("must Initialize() statsig before calling GetClientInitializeResponseForTargetApp"))
@@ -329,6 +661,46 @@ func GetClientInitializeResponseForTargetApp(user User, clientKey string) Client
 	return instance.GetClientInitializeResponse(user, clientKey, false)
 }
 
+// Updates the sync and logging behavior of the running Statsig client
+func UpdateRuntimeOptions(opts RuntimeOptions) error {
+	if !IsInitialized() {
+		panic(fmt.Errorf("must Initialize() statsig before calling UpdateRuntimeOptions"))
+	}
+	return instance.UpdateRuntimeOptions(opts)
+}
+
+// Creates a client evaluating against its own copy of the given specs, sharing the logger and transport of the Statsig client
+func NewSubClient(bootstrapValues string) *Client {
+	if !IsInitialized() {
+		panic(fmt.Errorf("must Initialize() statsig before calling NewSubClient"))
+	}
+	return instance.NewSubClient(bootstrapValues)
+}
+
+// Syncs config specs right away instead of waiting for the next poll. Returns whether any spec changed
+func SyncNow() (bool, error) {
+	if !IsInitialized() {
+		panic(fmt.Errorf("must Initialize() statsig before calling SyncNow"))
+	}
+	return instance.SyncNow()
+}
+
+// Gets the most recent sync error of the Statsig client, or nil if the last sync succeeded
+func LastSyncError() error {
+	if !IsInitialized() {
+		panic(fmt.Errorf("must Initialize() statsig before calling LastSyncError"))
+	}
+	return instance.LastSyncError()
+}
+
+// Gets the configuration the Statsig client is running with, with the SDK key redacted
+func EffectiveOptions() ResolvedOptions {
+	if !IsInitialized() {
+		panic(fmt.Errorf("must Initialize() statsig before calling EffectiveOptions"))
+	}
+	return instance.EffectiveOptions()
+}
+
 // Cleans up Statsig, persisting any Event Logs and cleanup processes
 // Using any method is undefined after Shutdown() has been called
 func Shutdown() {
@@ -338,6 +710,30 @@ func Shutdown() {
 	instance.Shutdown()
 }
 
+// Sends the buffered events without shutting the client down. Returns once the send is dispatched in the background
+func FlushEvents() {
+	if !IsInitialized() {
+		panic(fmt.Errorf("must Initialize() statsig before calling FlushEvents"))
+	}
+	instance.FlushEvents()
+}
+
+// Sends the buffered events and blocks until they complete, or returns ErrFlushTimeout once timeout elapses
+func FlushEventsAndWait(timeout time.Duration) error {
+	if !IsInitialized() {
+		panic(fmt.Errorf("must Initialize() statsig before calling FlushEventsAndWait"))
+	}
+	return instance.FlushEventsAndWait(timeout)
+}
+
+// Gets the number of events buffered and not yet handed to a send
+func QueuedEventCount() int {
+	if !IsInitialized() {
+		panic(fmt.Errorf("must Initialize() statsig before calling QueuedEventCount"))
+	}
+	return instance.QueuedEventCount()
+}
+
 // For test only so we can clear the shared instance. Not thread safe.
 func ShutdownAndDangerouslyClearInstance() {
 	Shutdown()
@@ -1,6 +1,7 @@
 package statsig
 
 import (
+	"context"
 	"sync"
 	"time"
 )
@@ -14,16 +15,45 @@ type errorContext struct {
 }
 
 type evalContext struct {
-	Caller                string `json:"tag,omitempty"`
-	ConfigName            string `json:"configName,omitempty"`
-	ClientKey             string `json:"clientKey,omitempty"`
-	Hash                  string `json:"hash,omitempty"`
-	TargetAppID           string
-	IncludeLocalOverrides bool
-	IsManualExposure      bool
-	IsExperiment          bool
-	DisableLogExposures   bool
-	PersistedValues       UserPersistedValues
+	Caller                   string `json:"tag,omitempty"`
+	ConfigName               string `json:"configName,omitempty"`
+	ClientKey                string `json:"clientKey,omitempty"`
+	Hash                     string `json:"hash,omitempty"`
+	TargetAppID              string
+	IncludeLocalOverrides    bool
+	IsManualExposure         bool
+	IsExperiment             bool
+	DisableLogExposures      bool
+	PersistedValues          UserPersistedValues
+	EvaluationTime           time.Time
+	DedupedExposureFormat    bool
+	CaptureConditionFailures bool
+	dependentGateResults     map[string]*evalResult // Memoizes pass_gate/fail_gate targets so each is evaluated once per call
+	evaluationDeadline       time.Time              // Set per top level evaluation from Options.MaxEvaluationDuration
+	ctx                      context.Context        // Set by the *WithContext methods
+	abortReason              EvaluationReason       // ReasonTimeout or ReasonCanceled once the evaluation was abandoned
+	recordRateLimits         bool                   // Set by the check APIs so rate_limited counts the evaluation. Observational callers only read the counters
+}
+
+// Checked before each rule. Once the deadline passes or the context is done every remaining rule in the evaluation is skipped
+func (c *evalContext) shouldAbortEvaluation() bool {
+	if c.abortReason != "" {
+		return true
+	}
+	if c.ctx != nil && c.ctx.Err() != nil {
+		c.abortReason = ReasonCanceled
+		return true
+	}
+	if c.evaluationDeadline.IsZero() || time.Now().Before(c.evaluationDeadline) {
+		return false
+	}
+	c.abortReason = ReasonTimeout
+	return true
+}
+
+// Storage calls can block, so they are skipped once the caller's context is done
+func (c *evalContext) isCanceled() bool {
+	return c.ctx != nil && c.ctx.Err() != nil
 }
 
 type initContext struct {
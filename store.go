@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -31,6 +32,14 @@ type configSpec struct {
 	TargetAppIDs       []string               `json:"targetAppIDs,omitempty"`
 }
 
+// Specs from older responses carry no entity, in which case the type is derived from the spec type
+func getConfigType(spec configSpec) ConfigType {
+	if spec.Entity != "" {
+		return ConfigType(spec.Entity)
+	}
+	return ConfigType(spec.Type)
+}
+
 func (c configSpec) hasTargetAppID(appId string) bool {
 	if appId == "" {
 		return true
@@ -56,6 +65,7 @@ type configRule struct {
 	IDType            string                 `json:"idType"`
 	ConfigDelegate    string                 `json:"configDelegate"`
 	IsExperimentGroup *bool                  `json:"isExperimentGroup,omitempty"`
+	SamplingRate      *int                   `json:"samplingRate,omitempty"`
 }
 
 type configCondition struct {
@@ -102,8 +112,15 @@ type idList struct {
 type DataSource string
 
 const (
-	AdapterDataSource DataSource = "adapter"
-	NetworkDataSource DataSource = "network"
+	AdapterDataSource   DataSource = "adapter"
+	NetworkDataSource   DataSource = "network"
+	BootstrapDataSource DataSource = "bootstrap"
+)
+
+// Values for Options.DuplicateConfigNameBehavior
+const (
+	DuplicateNameKeepLast  = "keep_last"
+	DuplicateNameKeepFirst = "keep_first"
 )
 
 type store struct {
@@ -119,11 +136,13 @@ type store struct {
 	initialSyncTime         int64
 	source                  EvaluationSource
 	initializedIDLists      bool
+	idListsFrom             *store // Set on sub client stores, which read the parent's ID lists instead of loading their own
 	transport               *transport
 	configSyncInterval      time.Duration
 	idListSyncInterval      time.Duration
 	shutdown                bool
 	rulesUpdatedCallback    func(rules string, time int64)
+	specsUpdatedCallback    func(changed SpecsDiff)
 	errorBoundary           *errorBoundary
 	dataAdapter             IDataAdapter
 	syncFailureCount        int
@@ -132,10 +151,29 @@ type store struct {
 	sdkKey                  string
 	isPolling               bool
 	bootstrapValues         string
+	lazyLoadIDLists         bool
+	initSourcePriority      []DataSource
+	maxIDListBytes          int64
+	lastSyncError           error
+	lastSyncErrorMu         sync.RWMutex
+	caseInsensitiveNames    bool
+	keepFirstDuplicateName  bool
+	duplicateSpecNames      map[string]bool
+	readOnly                bool
+	lowercaseGateNames      map[string]string
+	lowercaseConfigNames    map[string]string
+	lowercaseLayerNames     map[string]string
+	idListDownloads         map[string]bool
+	idListDownloadsMu       sync.Mutex
+	configSyncMu            sync.Mutex
+	configIntervalChanged   chan struct{}
+	idListIntervalChanged   chan struct{}
 }
 
 var syncOutdatedMax = 2 * time.Minute
 
+const idListSizeExceededReason = "max_id_list_bytes_exceeded"
+
 func newStore(
 	transport *transport,
 	errorBoundary *errorBoundary,
@@ -151,7 +189,7 @@ func newStore(
 	if options.IDListSyncInterval > 0 {
 		idListSyncInterval = options.IDListSyncInterval
 	}
-	return newStoreInternal(
+	store := newStoreInternal(
 		transport,
 		configSyncInterval,
 		idListSyncInterval,
@@ -162,6 +200,14 @@ func newStore(
 		sdkKey,
 		options.BootstrapValues,
 	)
+	store.lazyLoadIDLists = options.LazyLoadIDLists
+	store.initSourcePriority = options.InitializationSourcePriority
+	store.maxIDListBytes = options.MaxIDListBytes
+	store.caseInsensitiveNames = options.CaseInsensitiveConfigNames
+	store.specsUpdatedCallback = options.SpecsUpdatedCallback
+	store.keepFirstDuplicateName = options.DuplicateConfigNameBehavior == DuplicateNameKeepFirst
+	store.readOnly = options.ReadOnly
+	return store
 }
 
 func newStoreInternal(
@@ -176,22 +222,25 @@ func newStoreInternal(
 	bootstrapValues string,
 ) *store {
 	store := &store{
-		featureGates:         make(map[string]configSpec),
-		dynamicConfigs:       make(map[string]configSpec),
-		idLists:              make(map[string]*idList),
-		transport:            transport,
-		configSyncInterval:   configSyncInterval,
-		idListSyncInterval:   idListSyncInterval,
-		rulesUpdatedCallback: rulesUpdatedCallback,
-		errorBoundary:        errorBoundary,
-		source:               SourceUninitialized,
-		initializedIDLists:   false,
-		dataAdapter:          dataAdapter,
-		syncFailureCount:     0,
-		diagnostics:          diagnostics,
-		sdkKey:               sdkKey,
-		isPolling:            false,
-		bootstrapValues:      bootstrapValues,
+		featureGates:          make(map[string]configSpec),
+		dynamicConfigs:        make(map[string]configSpec),
+		idLists:               make(map[string]*idList),
+		transport:             transport,
+		configSyncInterval:    configSyncInterval,
+		idListSyncInterval:    idListSyncInterval,
+		rulesUpdatedCallback:  rulesUpdatedCallback,
+		errorBoundary:         errorBoundary,
+		source:                SourceUninitialized,
+		initializedIDLists:    false,
+		dataAdapter:           dataAdapter,
+		syncFailureCount:      0,
+		diagnostics:           diagnostics,
+		sdkKey:                sdkKey,
+		isPolling:             false,
+		bootstrapValues:       bootstrapValues,
+		idListDownloads:       make(map[string]bool),
+		configIntervalChanged: make(chan struct{}, 1),
+		idListIntervalChanged: make(chan struct{}, 1),
 	}
 	return store
 }
@@ -207,32 +256,73 @@ func (s *store) startPolling() {
 }
 
 func (s *store) initialize(context *initContext) {
-	firstAttempt := true
 	if s.dataAdapter != nil {
-		firstAttempt = false
 		s.dataAdapter.Initialize()
-		s.fetchConfigSpecsFromAdapter(context)
-	} else if s.bootstrapValues != "" {
-		firstAttempt = false
-		if parsed, updated := s.processConfigSpecs(s.bootstrapValues, s.addDiagnostics().bootstrap()); parsed {
-			if updated {
-				s.mu.Lock()
-				s.source = SourceBootstrap
-				s.mu.Unlock()
-			}
-		} else {
-			context.setError(errors.New("Failed to parse bootstrap values"))
-		}
 	}
-	if s.lastSyncTime == 0 {
-		if !firstAttempt {
-			s.diagnostics.initDiagnostics.logProcess("Retrying with network...")
+	firstAttempt := true
+	for _, source := range s.getInitSourcePriority() {
+		if s.lastSyncTime != 0 {
+			break
+		}
+		switch source {
+		case AdapterDataSource:
+			if s.dataAdapter == nil {
+				continue
+			}
+			s.fetchConfigSpecsFromAdapter(context)
+		case BootstrapDataSource:
+			if s.bootstrapValues == "" {
+				continue
+			}
+			s.initializeFromBootstrap(context)
+		case NetworkDataSource:
+			if !firstAttempt {
+				s.diagnostics.initDiagnostics.logProcess("Retrying with network...")
+			}
+			s.fetchConfigSpecsFromServer(context)
+		default:
+			continue
 		}
-		s.fetchConfigSpecsFromServer(context)
+		firstAttempt = false
 	}
 	s.mu.Lock()
 	s.initialSyncTime = s.lastSyncTime
 	s.mu.Unlock()
+	if s.lazyLoadIDLists {
+		go s.initializeIDLists()
+	} else {
+		s.initializeIDLists()
+	}
+	s.startPolling()
+}
+
+// Sources not in the priority list are not tried during initialization.
+// By default a data adapter takes precedence over bootstrap values, falling back to the network
+func (s *store) getInitSourcePriority() []DataSource {
+	if len(s.initSourcePriority) > 0 {
+		return s.initSourcePriority
+	}
+	if s.dataAdapter != nil {
+		return []DataSource{AdapterDataSource, NetworkDataSource}
+	}
+	return []DataSource{BootstrapDataSource, NetworkDataSource}
+}
+
+func (s *store) initializeFromBootstrap(context *initContext) {
+	if parsed, updated, err := s.processConfigSpecs(s.bootstrapValues, s.addDiagnostics().bootstrap()); parsed {
+		if updated {
+			s.mu.Lock()
+			s.source = SourceBootstrap
+			s.mu.Unlock()
+		}
+	} else if err != nil {
+		context.setError(err)
+	} else {
+		context.setError(errors.New("Failed to parse bootstrap values"))
+	}
+}
+
+func (s *store) initializeIDLists() {
 	if s.dataAdapter != nil {
 		s.fetchIDListsFromAdapter()
 	} else {
@@ -241,13 +331,24 @@ func (s *store) initialize(context *initContext) {
 	s.mu.Lock()
 	s.initializedIDLists = true
 	s.mu.Unlock()
-	s.startPolling()
+}
+
+func (s *store) areIDListsInitialized() bool {
+	if s.idListsFrom != nil {
+		return s.idListsFrom.areIDListsInitialized()
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.initializedIDLists
 }
 
 func (s *store) getGate(name string) (configSpec, bool) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 	gate, ok := s.featureGates[name]
+	if !ok && s.caseInsensitiveNames {
+		gate, ok = s.featureGates[s.lowercaseGateNames[strings.ToLower(name)]]
+	}
 	return gate, ok
 }
 
@@ -255,6 +356,9 @@ func (s *store) getDynamicConfig(name string) (configSpec, bool) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 	config, ok := s.dynamicConfigs[name]
+	if !ok && s.caseInsensitiveNames {
+		config, ok = s.dynamicConfigs[s.lowercaseConfigNames[strings.ToLower(name)]]
+	}
 	return config, ok
 }
 
@@ -262,9 +366,45 @@ func (s *store) getLayerConfig(name string) (configSpec, bool) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 	layer, ok := s.layerConfigs[name]
+	if !ok && s.caseInsensitiveNames {
+		layer, ok = s.layerConfigs[s.lowercaseLayerNames[strings.ToLower(name)]]
+	}
 	return layer, ok
 }
 
+// Gets the name a gate is stored under, which differs from the given one only by case under CaseInsensitiveConfigNames
+func (s *store) getGateName(name string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.canonicalName(s.featureGates, s.lowercaseGateNames, name)
+}
+
+func (s *store) getDynamicConfigName(name string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.canonicalName(s.dynamicConfigs, s.lowercaseConfigNames, name)
+}
+
+func (s *store) getLayerConfigName(name string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.canonicalName(s.layerConfigs, s.lowercaseLayerNames, name)
+}
+
+// Must be called with s.mu held. Unknown names are returned unchanged
+func (s *store) canonicalName(specs map[string]configSpec, lowercaseNames map[string]string, name string) string {
+	if !s.caseInsensitiveNames {
+		return name
+	}
+	if _, ok := specs[name]; ok {
+		return name
+	}
+	if canonical, ok := lowercaseNames[strings.ToLower(name)]; ok {
+		return canonical
+	}
+	return name
+}
+
 func (s *store) getExperimentLayer(experimentName string) (string, bool) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -272,6 +412,19 @@ func (s *store) getExperimentLayer(experimentName string) (string, bool) {
 	return layer, ok
 }
 
+func (s *store) getExperimentLayerInfo(experimentName string) ExperimentLayerInfo {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if _, ok := s.dynamicConfigs[experimentName]; !ok && s.caseInsensitiveNames {
+		if name, ok := s.lowercaseConfigNames[strings.ToLower(experimentName)]; ok {
+			experimentName = name
+		}
+	}
+	_, exists := s.dynamicConfigs[experimentName]
+	layer, inLayer := s.experimentToLayer[experimentName]
+	return ExperimentLayerInfo{LayerName: layer, InLayer: inLayer, ExperimentExists: exists}
+}
+
 func (s *store) getAppIDForSDKKey(clientKey string) (string, bool) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -289,28 +442,33 @@ func (s *store) getEntitiesForSDKKey(clientKey string) (configEntities, bool) {
 	return entities, ok
 }
 
-func (s *store) fetchConfigSpecsFromAdapter(context *initContext) {
+func (s *store) fetchConfigSpecsFromAdapter(context *initContext) (updated bool, err error) {
 	s.addDiagnostics().dataStoreConfigSpecs().fetch().start().mark()
 	defer func() {
-		if err := recover(); err != nil {
-			dataAdapterError := DataAdapterError{Err: toError(err), Method: "get"}
+		if panicErr := recover(); panicErr != nil {
+			dataAdapterError := DataAdapterError{Err: toError(panicErr), Method: "get"}
 			Logger().LogError(dataAdapterError)
 			if context != nil {
 				context.setError(&dataAdapterError)
 			}
+			err = &dataAdapterError
 		}
 	}()
 	specString := s.dataAdapter.Get(CONFIG_SPECS_KEY)
 	s.addDiagnostics().dataStoreConfigSpecs().fetch().end().success(true).mark()
-	if _, updated := s.processConfigSpecs(specString, s.addDiagnostics().dataStoreConfigSpecs()); updated {
+	_, updated, err = s.processConfigSpecs(specString, s.addDiagnostics().dataStoreConfigSpecs())
+	if updated {
 		s.mu.Lock()
 		s.source = SourceDataAdapter
 		s.mu.Unlock()
+	} else if err != nil && context != nil {
+		context.setError(err)
 	}
+	return updated, err
 }
 
 func (s *store) saveConfigSpecsToAdapter(specs downloadConfigSpecResponse) {
-	if s.dataAdapter == nil {
+	if s.dataAdapter == nil || s.readOnly {
 		return
 	}
 	specString, err := json.Marshal(specs)
@@ -326,8 +484,9 @@ func (s *store) saveConfigSpecsToAdapter(specs downloadConfigSpecResponse) {
 }
 
 func (s *store) handleSyncError(err error, context *initContext) {
+	s.setLastSyncError(err)
 	s.syncFailureCount += 1
-	failDuration := time.Duration(s.syncFailureCount) * s.configSyncInterval
+	failDuration := time.Duration(s.syncFailureCount) * s.getConfigSyncInterval()
 	if context != nil {
 		Logger().LogError(fmt.Sprintf("Failed to initialize from the network. " +
 			"See https://docs.statsig.com/messages/serverSDKConnection for more information\n"))
@@ -342,17 +501,19 @@ func (s *store) handleSyncError(err error, context *initContext) {
 	}
 }
 
-func (s *store) fetchConfigSpecsFromServer(context *initContext) {
+// Returns whether the specs changed, and the error when they could not be downloaded or applied
+func (s *store) fetchConfigSpecsFromServer(context *initContext) (bool, error) {
 	if s.transport.options.LocalMode {
-		return
+		return false, nil
 	}
 	var specs downloadConfigSpecResponse
 	res, err := s.transport.download_config_specs(s.lastSyncTime, &specs, s.addDiagnostics())
 	if res == nil || err != nil {
 		s.handleSyncError(err, context)
-		return
+		return false, err
 	}
-	parsed, updated := s.processConfigSpecs(specs, s.addDiagnostics().downloadConfigSpecs())
+	parsed, updated, err := s.processConfigSpecs(specs, s.addDiagnostics().downloadConfigSpecs())
+	s.setLastSyncError(err)
 	if parsed {
 		s.mu.Lock()
 		defer s.mu.Unlock()
@@ -366,33 +527,49 @@ func (s *store) fetchConfigSpecsFromServer(context *initContext) {
 		} else {
 			s.source = SourceNetworkNotModified
 		}
-	} else {
-		if context != nil {
-			context.setError(errors.New("Failed to parse config specs"))
-		}
+		return updated, nil
+	}
+	if err == nil {
+		err = errors.New("Failed to parse config specs")
 	}
+	if context != nil {
+		context.setError(err)
+	}
+	return false, err
+}
+
+// Runs one config spec sync from the data adapter when it is used for updates, else from the network. Syncs are
+// serialized so a manual sync racing the poller does not download and apply the same specs twice
+func (s *store) syncConfigSpecs() (bool, error) {
+	s.configSyncMu.Lock()
+	defer s.configSyncMu.Unlock()
+	if s.dataAdapter != nil && s.dataAdapter.ShouldBeUsedForQueryingUpdates(CONFIG_SPECS_KEY) {
+		return s.fetchConfigSpecsFromAdapter(nil)
+	}
+	return s.fetchConfigSpecsFromServer(nil)
 }
 
-func (s *store) processConfigSpecs(configSpecs interface{}, diagnosticsMarker *marker) (bool, bool) {
+func (s *store) processConfigSpecs(configSpecs interface{}, diagnosticsMarker *marker) (bool, bool, error) {
 	diagnosticsMarker.process().start().mark()
 	specs := downloadConfigSpecResponse{}
 	parsed, updated := false, false
+	var rejection error
 	switch specsTyped := configSpecs.(type) {
 	case string:
 		err := json.Unmarshal([]byte(specsTyped), &specs)
 		if err == nil {
-			parsed, updated = s.setConfigSpecs(specs)
+			parsed, updated, rejection = s.setConfigSpecs(specs)
 		}
 	case downloadConfigSpecResponse:
-		parsed, updated = s.setConfigSpecs(specsTyped)
+		parsed, updated, rejection = s.setConfigSpecs(specsTyped)
 	default:
 		parsed, updated = false, false
 	}
 	diagnosticsMarker.process().end().success(updated).mark()
-	return parsed, updated
+	return parsed, updated, rejection
 }
 
-func (s *store) parseJSONValuesFromSpec(spec *configSpec) {
+func parseJSONValuesFromSpec(spec *configSpec) {
 	var defaultValue map[string]interface{}
 	err := json.Unmarshal(spec.DefaultValue, &defaultValue)
 	if err != nil {
@@ -409,7 +586,7 @@ func (s *store) parseJSONValuesFromSpec(spec *configSpec) {
 	}
 }
 
-func (s *store) parseTargetValueMapFromSpec(spec *configSpec) {
+func parseTargetValueMapFromSpec(spec *configSpec) {
 	for _, rule := range spec.Rules {
 		for i, cond := range rule.Conditions {
 			if (cond.Operator == "any" || cond.Operator == "none") && cond.Type == "user_bucket" {
@@ -426,39 +603,41 @@ func (s *store) parseTargetValueMapFromSpec(spec *configSpec) {
 	}
 }
 
-// Returns a tuple of booleans indicating 1. parsed, 2. updated
-func (s *store) setConfigSpecs(specs downloadConfigSpecResponse) (bool, bool) {
+// Returns a tuple indicating 1. parsed, 2. updated, 3. why well formed specs were rejected, if they were
+func (s *store) setConfigSpecs(specs downloadConfigSpecResponse) (bool, bool, error) {
 	if specs.Time < s.lastSyncTime {
-		return false, false
+		return false, false, nil
 	}
 	s.diagnostics.initDiagnostics.updateSamplingRates(specs.DiagnosticsSampleRates)
 	s.diagnostics.syncDiagnostics.updateSamplingRates(specs.DiagnosticsSampleRates)
 	s.diagnostics.apiDiagnostics.updateSamplingRates(specs.DiagnosticsSampleRates)
 
 	if specs.HashedSDKKeyUsed != "" && specs.HashedSDKKeyUsed != getDJB2Hash(s.sdkKey) {
-		s.errorBoundary.logException(fmt.Errorf("SDK key mismatch. Key used to generate response does not match key provided. Expected %s, got %s", getDJB2Hash(s.sdkKey), specs.HashedSDKKeyUsed))
-		return false, false
+		err := &SDKKeyMismatchError{Expected: getDJB2Hash(s.sdkKey), Received: specs.HashedSDKKeyUsed}
+		s.errorBoundary.logException(err)
+		return false, false, err
 	}
 
 	if specs.HasUpdates {
+		duplicates := make(map[string]bool)
 		newGates := make(map[string]configSpec)
 		for _, gate := range specs.FeatureGates {
-			s.parseTargetValueMapFromSpec(&gate)
-			newGates[gate.Name] = gate
+			parseTargetValueMapFromSpec(&gate)
+			s.addSpecByName(newGates, gate, duplicates)
 		}
 
 		newConfigs := make(map[string]configSpec)
 		for _, config := range specs.DynamicConfigs {
-			s.parseTargetValueMapFromSpec(&config)
-			s.parseJSONValuesFromSpec(&config)
-			newConfigs[config.Name] = config
+			parseTargetValueMapFromSpec(&config)
+			parseJSONValuesFromSpec(&config)
+			s.addSpecByName(newConfigs, config, duplicates)
 		}
 
 		newLayers := make(map[string]configSpec)
 		for _, layer := range specs.LayerConfigs {
-			s.parseTargetValueMapFromSpec(&layer)
-			s.parseJSONValuesFromSpec(&layer)
-			newLayers[layer.Name] = layer
+			parseTargetValueMapFromSpec(&layer)
+			parseJSONValuesFromSpec(&layer)
+			s.addSpecByName(newLayers, layer, duplicates)
 		}
 
 		newExperimentToLayer := make(map[string]string)
@@ -468,22 +647,87 @@ func (s *store) setConfigSpecs(specs downloadConfigSpecResponse) (bool, bool) {
 			}
 		}
 
+		var gateNames, configNames, layerNames map[string]string
+		if s.caseInsensitiveNames {
+			gateNames = buildLowercaseNameIndex(specs.FeatureGates)
+			configNames = buildLowercaseNameIndex(specs.DynamicConfigs)
+			layerNames = buildLowercaseNameIndex(specs.LayerConfigs)
+		}
+
 		s.mu.Lock()
+		previousGates, previousConfigs, previousLayers := s.featureGates, s.dynamicConfigs, s.layerConfigs
 		s.featureGates = newGates
 		s.dynamicConfigs = newConfigs
 		s.layerConfigs = newLayers
+		s.lowercaseGateNames = gateNames
+		s.lowercaseConfigNames = configNames
+		s.lowercaseLayerNames = layerNames
 		s.experimentToLayer = newExperimentToLayer
 		s.sdkKeysToAppID = specs.SDKKeysToAppID
 		s.hashedSDKKeysToAppID = specs.HashedSDKKeysToAppID
 		s.hashedSDKKeysToEntities = specs.HashedSDKKeysToEntities
 		s.lastSyncTime = specs.Time
+		previousDuplicates := s.duplicateSpecNames
+		s.duplicateSpecNames = duplicates
 		s.mu.Unlock()
-		return true, true
+		s.warnNewDuplicateSpecNames(previousDuplicates, duplicates)
+		if s.specsUpdatedCallback != nil {
+			s.specsUpdatedCallback(diffSpecs(previousGates, previousConfigs, previousLayers, newGates, newConfigs, newLayers))
+		}
+		return true, true, nil
 	}
-	return true, false
+	return true, false, nil
+}
+
+// Spec names are expected to be unique per entity type. A duplicate replaces the earlier spec unless
+// Options.DuplicateConfigNameBehavior is DuplicateNameKeepFirst, and is recorded in duplicates either way
+func (s *store) addSpecByName(specs map[string]configSpec, spec configSpec, duplicates map[string]bool) {
+	if _, exists := specs[spec.Name]; exists {
+		duplicates[fmt.Sprintf("%s %s", spec.Type, spec.Name)] = true
+		if s.keepFirstDuplicateName {
+			return
+		}
+	}
+	specs[spec.Name] = spec
+}
+
+// Every sync repeats the same duplicates, so only those the previous specs did not have are reported
+func (s *store) warnNewDuplicateSpecNames(previous map[string]bool, current map[string]bool) {
+	kept := "last"
+	if s.keepFirstDuplicateName {
+		kept = "first"
+	}
+	names := make([]string, 0, len(current))
+	for name := range current {
+		if !previous[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		Logger().LogError(fmt.Sprintf("%s is defined more than once in the config specs. Using the %s definition", name, kept))
+	}
+}
+
+// Maps lowercased names to the name of the first spec with that spelling. Later specs differing only by case are
+// unreachable through a case-insensitive lookup and are reported
+func buildLowercaseNameIndex(specs []configSpec) map[string]string {
+	index := make(map[string]string, len(specs))
+	for _, spec := range specs {
+		lowercase := strings.ToLower(spec.Name)
+		if existing, ok := index[lowercase]; ok && existing != spec.Name {
+			Logger().LogError(fmt.Sprintf("%s and %s differ only by case. Case-insensitive lookups resolve to %s", existing, spec.Name, existing))
+			continue
+		}
+		index[lowercase] = spec.Name
+	}
+	return index
 }
 
 func (s *store) getIDList(name string) *idList {
+	if s.idListsFrom != nil {
+		return s.idListsFrom.getIDList(name)
+	}
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 	list, ok := s.idLists[name]
@@ -512,9 +756,11 @@ func (s *store) fetchIDListsFromServer() {
 	var serverLists map[string]idList
 	res, err := s.transport.get_id_lists(&serverLists, s.addDiagnostics())
 	if res == nil || err != nil {
+		s.setLastSyncError(err)
 		s.errorBoundary.logException(err)
 		return
 	}
+	s.clearIDListSyncError()
 	s.processIDListsFromNetwork(serverLists)
 	s.saveIDListsToAdapter(s.idLists)
 }
@@ -539,7 +785,7 @@ func (s *store) fetchIDListsFromAdapter() {
 }
 
 func (s *store) saveIDListsToAdapter(idLists map[string]*idList) {
-	if s.dataAdapter == nil {
+	if s.dataAdapter == nil || s.readOnly {
 		return
 	}
 	idListsJSON, err := json.Marshal(idLists)
@@ -565,6 +811,28 @@ func (s *store) saveIDListsToAdapter(idLists map[string]*idList) {
 	}
 }
 
+func (s *store) exportIDLists() map[string][]string {
+	s.mu.RLock()
+	lists := make([]*idList, 0, len(s.idLists))
+	for _, list := range s.idLists {
+		lists = append(lists, list)
+	}
+	s.mu.RUnlock()
+	export := make(map[string][]string, len(lists))
+	for _, list := range lists {
+		ids := make([]string, 0)
+		list.mu.RLock()
+		list.ids.Range(func(key, value interface{}) bool {
+			ids = append(ids, key.(string))
+			return true
+		})
+		list.mu.RUnlock()
+		sort.Strings(ids)
+		export[list.Name] = ids
+	}
+	return export
+}
+
 func (s *store) processIDListsFromNetwork(idLists map[string]idList) {
 	s.addDiagnostics().getIdListSources().process().start().idListCount(len(idLists)).mark()
 	s.processIDLists(idLists, NetworkDataSource)
@@ -580,39 +848,20 @@ func (s *store) processIDListsFromAdapter(idLists map[string]idList) {
 func (s *store) processIDLists(idLists map[string]idList, source DataSource) {
 	wg := sync.WaitGroup{}
 	for name, serverList := range idLists {
-		localList := s.getIDList(name)
-		if localList == nil {
-			localList = &idList{Name: name}
-			s.setIDList(name, localList)
-		}
-
-		// skip if server list is invalid
-		if serverList.URL == "" || serverList.CreationTime < localList.CreationTime || serverList.FileID == "" {
+		// skip if another sync is already downloading this list, it picks up the same changes
+		if !s.startIDListDownload(name) {
 			continue
 		}
-
-		// reset the local list if returns server list has a newer file
-		if serverList.FileID != localList.FileID && serverList.CreationTime >= localList.CreationTime {
-			localList = &idList{
-				Name:         localList.Name,
-				Size:         0,
-				CreationTime: serverList.CreationTime,
-				URL:          serverList.URL,
-				FileID:       serverList.FileID,
-				ids:          &sync.Map{},
-				mu:           &sync.RWMutex{},
-			}
-			s.setIDList(name, localList)
-		}
-
-		// skip if server list is not bigger
-		if serverList.Size <= localList.Size {
+		localList := s.getIDListToDownload(name, serverList)
+		if localList == nil {
+			s.finishIDListDownload(name)
 			continue
 		}
 
 		wg.Add(1)
 		go func(name string, l *idList) {
 			defer wg.Done()
+			defer s.finishIDListDownload(name)
 			if source == NetworkDataSource {
 				s.downloadSingleIDListFromServer(l)
 			} else if source == AdapterDataSource {
@@ -630,6 +879,57 @@ func (s *store) processIDLists(idLists map[string]idList, source DataSource) {
 	}
 }
 
+// Returns the local list the server list should be downloaded into, or nil when there is nothing to download
+func (s *store) getIDListToDownload(name string, serverList idList) *idList {
+	localList := s.getIDList(name)
+	if localList == nil {
+		localList = &idList{Name: name}
+		s.setIDList(name, localList)
+	}
+
+	// skip if server list is invalid
+	if serverList.URL == "" || serverList.CreationTime < localList.CreationTime || serverList.FileID == "" {
+		return nil
+	}
+
+	// reset the local list if returns server list has a newer file
+	if serverList.FileID != localList.FileID && serverList.CreationTime >= localList.CreationTime {
+		localList = &idList{
+			Name:         localList.Name,
+			Size:         0,
+			CreationTime: serverList.CreationTime,
+			URL:          serverList.URL,
+			FileID:       serverList.FileID,
+			ids:          &sync.Map{},
+			mu:           &sync.RWMutex{},
+		}
+		s.setIDList(name, localList)
+	}
+
+	// skip if server list is not bigger, or the local list is already capped
+	if serverList.Size <= localList.Size || (s.maxIDListBytes > 0 && localList.Size >= s.maxIDListBytes) {
+		return nil
+	}
+	return localList
+}
+
+// Returns false when the list is already being downloaded, otherwise marks it in flight until finishIDListDownload
+func (s *store) startIDListDownload(name string) bool {
+	s.idListDownloadsMu.Lock()
+	defer s.idListDownloadsMu.Unlock()
+	if s.idListDownloads[name] {
+		return false
+	}
+	s.idListDownloads[name] = true
+	return true
+}
+
+func (s *store) finishIDListDownload(name string) {
+	s.idListDownloadsMu.Lock()
+	defer s.idListDownloadsMu.Unlock()
+	delete(s.idListDownloads, name)
+}
+
 func (s *store) downloadSingleIDListFromServer(list *idList) {
 	s.addDiagnostics().getIdList().networkRequest().start().name(list.Name).url(list.URL).mark()
 	res, err := s.transport.get_id_list(list.URL, map[string]string{"Range": fmt.Sprintf("bytes=%d-", list.Size)})
@@ -639,6 +939,7 @@ func (s *store) downloadSingleIDListFromServer(list *idList) {
 			marker.statusCode(res.StatusCode).sdkRegion(safeGetFirst(res.Header["X-Statsig-Region"]))
 		}
 		marker.mark()
+		s.setLastSyncError(err)
 		s.errorBoundary.logException(err)
 		return
 	}
@@ -672,7 +973,12 @@ func (s *store) processSingleIDListFromNetwork(list *idList, res *http.Response)
 		return
 	}
 
-	bodyBytes, err := io.ReadAll(res.Body)
+	var body io.Reader = res.Body
+	if s.maxIDListBytes > 0 {
+		// Anything past the cap is discarded anyway, so don't pull it into memory
+		body = io.LimitReader(res.Body, s.maxIDListBytes-list.Size)
+	}
+	bodyBytes, err := io.ReadAll(body)
 	if err != nil {
 		s.addDiagnostics().getIdList().process().end().name(list.Name).url(list.URL).success(false).mark()
 		s.errorBoundary.logException(err)
@@ -685,19 +991,36 @@ func (s *store) processSingleIDListFromNetwork(list *idList, res *http.Response)
 		s.deleteIDList(list.Name)
 		return
 	}
-	s.processSingleIDList(list, content, length)
+	if !s.processSingleIDList(list, content, length) {
+		s.addDiagnostics().getIdList().process().end().name(list.Name).url(list.URL).success(false).reason(idListSizeExceededReason).mark()
+		return
+	}
 	s.addDiagnostics().getIdList().process().end().name(list.Name).url(list.URL).success(true).mark()
 }
 
 func (s *store) processSingleIDListFromAdapter(list *idList, content string) {
 	s.addDiagnostics().dataStoreIDList().process().start().name(list.Name).url(list.URL).mark()
-	s.processSingleIDList(list, content, len(content))
+	if !s.processSingleIDList(list, content, len(content)) {
+		s.addDiagnostics().dataStoreIDList().process().end().name(list.Name).url(list.URL).success(false).reason(idListSizeExceededReason).mark()
+		return
+	}
 	s.addDiagnostics().dataStoreIDList().process().end().name(list.Name).url(list.URL).success(true).mark()
 }
 
-func (s *store) processSingleIDList(list *idList, content string, length int) {
+// Returns false when MaxIDListBytes cut the content short. Whatever fit under the cap is still applied
+func (s *store) processSingleIDList(list *idList, content string, length int) bool {
 	list.mu.Lock()
 	defer list.mu.Unlock()
+	withinLimit := true
+	if s.maxIDListBytes > 0 && list.Size+int64(length) > s.maxIDListBytes {
+		withinLimit = false
+		content = truncateToWholeLines(content, s.maxIDListBytes-list.Size)
+		// Pin the size at the cap so later syncs skip this file until its FileID changes
+		length = int(s.maxIDListBytes - list.Size)
+		err := fmt.Errorf("ID list %s exceeded MaxIDListBytes of %d bytes, remaining IDs were not loaded", list.Name, s.maxIDListBytes)
+		Logger().LogError(err)
+		s.errorBoundary.logException(err)
+	}
 	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
 	for _, line := range lines {
 		line = strings.TrimSpace(line)
@@ -713,11 +1036,22 @@ func (s *store) processSingleIDList(list *idList, content string, length int) {
 		}
 	}
 	atomic.AddInt64((&list.Size), int64(length))
+	return withinLimit
+}
+
+func truncateToWholeLines(content string, limit int64) string {
+	if limit <= 0 {
+		return ""
+	}
+	if int64(len(content)) <= limit {
+		return content
+	}
+	return content[:strings.LastIndex(content[:limit], "\n")+1]
 }
 
 func (s *store) pollForIDListChanges() {
 	for {
-		time.Sleep(s.idListSyncInterval)
+		waitForInterval(s.getIDListSyncInterval, s.idListIntervalChanged)
 		stop := func() bool {
 			s.mu.RLock()
 			defer s.mu.RUnlock()
@@ -736,7 +1070,7 @@ func (s *store) pollForIDListChanges() {
 
 func (s *store) pollForRulesetChanges() {
 	for {
-		time.Sleep(s.configSyncInterval)
+		waitForInterval(s.getConfigSyncInterval, s.configIntervalChanged)
 		stop := func() bool {
 			s.mu.RLock()
 			defer s.mu.RUnlock()
@@ -745,14 +1079,89 @@ func (s *store) pollForRulesetChanges() {
 		if stop {
 			break
 		}
-		if s.dataAdapter != nil && s.dataAdapter.ShouldBeUsedForQueryingUpdates(CONFIG_SPECS_KEY) {
-			s.fetchConfigSpecsFromAdapter(nil)
-		} else {
-			s.fetchConfigSpecsFromServer(nil)
+		_, _ = s.syncConfigSpecs()
+	}
+}
+
+func (s *store) getConfigSyncInterval() time.Duration {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.configSyncInterval
+}
+
+func (s *store) getIDListSyncInterval() time.Duration {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.idListSyncInterval
+}
+
+// Restarts the current wait of the affected poller with the new interval. Zero values leave the interval unchanged
+func (s *store) setSyncIntervals(configSyncInterval time.Duration, idListSyncInterval time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if configSyncInterval > 0 {
+		s.configSyncInterval = configSyncInterval
+		signalIntervalChanged(s.configIntervalChanged)
+	}
+	if idListSyncInterval > 0 {
+		s.idListSyncInterval = idListSyncInterval
+		signalIntervalChanged(s.idListIntervalChanged)
+	}
+}
+
+// Wakes a poller without blocking. A pending signal already makes it read the latest interval
+func signalIntervalChanged(changed chan struct{}) {
+	select {
+	case changed <- struct{}{}:
+	default:
+	}
+}
+
+// Waits out the interval returned by getInterval, starting the wait over whenever changed is signaled
+func waitForInterval(getInterval func() time.Duration, changed chan struct{}) {
+	timer := time.NewTimer(getInterval())
+	defer timer.Stop()
+	for {
+		select {
+		case <-timer.C:
+			return
+		case <-changed:
+			if !timer.Stop() {
+				<-timer.C
+			}
+			timer.Reset(getInterval())
 		}
 	}
 }
 
+func (s *store) setLastSyncError(err error) {
+	s.lastSyncErrorMu.Lock()
+	defer s.lastSyncErrorMu.Unlock()
+	s.lastSyncError = err
+}
+
+// A config spec response generated for another SDK key keeps failing until the key is fixed, so a successful
+// ID list sync does not clear it
+func (s *store) clearIDListSyncError() {
+	s.lastSyncErrorMu.Lock()
+	defer s.lastSyncErrorMu.Unlock()
+	if !errors.Is(s.lastSyncError, ErrSDKKeyMismatch) {
+		s.lastSyncError = nil
+	}
+}
+
+func (s *store) getLastSyncError() error {
+	s.lastSyncErrorMu.RLock()
+	defer s.lastSyncErrorMu.RUnlock()
+	return s.lastSyncError
+}
+
+func (s *store) isShutdown() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.shutdown
+}
+
 func (s *store) stopPolling() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -761,13 +1170,8 @@ func (s *store) stopPolling() {
 }
 
 func (s *store) addDiagnostics() *marker {
-	var marker *marker
-	s.mu.RLock()
-	if !s.initializedIDLists {
-		marker = s.diagnostics.initialize()
-	} else {
-		marker = s.diagnostics.configSync()
+	if !s.areIDListsInitialized() {
+		return s.diagnostics.initialize()
 	}
-	s.mu.RUnlock()
-	return marker
+	return s.diagnostics.configSync()
 }
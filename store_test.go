@@ -2,9 +2,14 @@ package statsig
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"reflect"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -260,6 +265,405 @@ func TestStoreSync(t *testing.T) {
 	}
 }
 
+func TestMaxIDListBytes(t *testing.T) {
+	var hugeList strings.Builder
+	for i := 0; i < 100000; i++ {
+		hugeList.WriteString(fmt.Sprintf("+id_%d\n", i))
+	}
+	var listDownloads int32
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if strings.Contains(req.URL.Path, "huge_list") {
+			res.Header().Set("Content-Length", strconv.Itoa(hugeList.Len()))
+		}
+		res.WriteHeader(http.StatusOK)
+		if strings.Contains(req.URL.Path, "download_config_specs") {
+			v, _ := json.Marshal(&downloadConfigSpecResponse{HasUpdates: true, Time: getUnixMilli()})
+			_, _ = res.Write(v)
+		} else if strings.Contains(req.URL.Path, "get_id_lists") {
+			v, _ := json.Marshal(map[string]idList{
+				"huge_list": {Name: "huge_list", Size: int64(hugeList.Len()), URL: "http://" + req.Host + "/huge_list", CreationTime: 1, FileID: "file_id"},
+			})
+			_, _ = res.Write(v)
+		} else if strings.Contains(req.URL.Path, "huge_list") {
+			_, _ = res.Write([]byte(hugeList.String()))
+			incrementCounter(&listDownloads)
+		}
+	}))
+	defer testServer.Close()
+
+	opt := &Options{API: testServer.URL, MaxIDListBytes: 1000}
+	InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+	n := newTransport("secret-123", opt)
+	d := newDiagnostics(opt)
+	e := newErrorBoundary("client-key", opt, d)
+	s := newStore(n, e, opt, d, "secret-123")
+	s.initialize(nil)
+	defer s.stopPolling()
+
+	list := s.getIDList("huge_list")
+	if list == nil {
+		t.Fatal("Expected huge_list to be loaded up to the cap")
+	}
+	if size := atomic.LoadInt64(&list.Size); size != 1000 {
+		t.Errorf("Expected list size to be pinned at the 1000 byte cap, received %d", size)
+	}
+	ids := unsyncIDList(list.ids)
+	if len(ids) == 0 || len(ids) > 1000/len("+id_0\n") {
+		t.Errorf("Expected the loaded ids to be bounded by the cap, received %d", len(ids))
+	}
+	if !ids["id_0"] || ids["id_99999"] {
+		t.Error("Expected only the leading ids to be loaded")
+	}
+
+	s.fetchIDListsFromServer()
+	if getCounter(&listDownloads) != 1 {
+		t.Errorf("Expected a capped list not to be downloaded again, received %d downloads", getCounter(&listDownloads))
+	}
+
+	body := &countingReader{reader: strings.NewReader(hugeList.String())}
+	fresh := &idList{Name: "fresh_list", ids: &sync.Map{}, mu: &sync.RWMutex{}}
+	s.processSingleIDListFromNetwork(fresh, &http.Response{
+		Header: http.Header{"Content-Length": []string{strconv.Itoa(hugeList.Len())}},
+		Body:   io.NopCloser(body),
+	})
+	if body.read > 1000 {
+		t.Errorf("Expected at most 1000 bytes to be read from the response, read %d", body.read)
+	}
+}
+
+type countingReader struct {
+	reader io.Reader
+	read   int
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.read += n
+	return n, err
+}
+
+func TestConcurrentIDListSyncs(t *testing.T) {
+	var listADownloads, listBDownloads int32
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if strings.Contains(req.URL.Path, "get_id_lists") {
+			v, _ := json.Marshal(map[string]idList{
+				"list_a": {Name: "list_a", Size: 6, URL: "http://" + req.Host + "/list_a", CreationTime: 1, FileID: "file_a"},
+				"list_b": {Name: "list_b", Size: 6, URL: "http://" + req.Host + "/list_b", CreationTime: 1, FileID: "file_b"},
+			})
+			_, _ = res.Write(v)
+			return
+		}
+		// hold the download open so every sync overlaps with it
+		time.Sleep(200 * time.Millisecond)
+		res.Header().Set("Content-Length", "6")
+		if strings.Contains(req.URL.Path, "list_a") {
+			incrementCounter(&listADownloads)
+			_, _ = res.Write([]byte("+id_a\n"))
+		} else if strings.Contains(req.URL.Path, "list_b") {
+			incrementCounter(&listBDownloads)
+			_, _ = res.Write([]byte("+id_b\n"))
+		}
+	}))
+	defer testServer.Close()
+
+	opt := &Options{API: testServer.URL}
+	InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+	n := newTransport("secret-123", opt)
+	d := newDiagnostics(opt)
+	e := newErrorBoundary("client-key", opt, d)
+	s := newStore(n, e, opt, d, "secret-123")
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.fetchIDListsFromServer()
+		}()
+	}
+	wg.Wait()
+
+	if getCounter(&listADownloads) != 1 || getCounter(&listBDownloads) != 1 {
+		t.Errorf("Expected each id list to be downloaded once, received %d and %d downloads",
+			getCounter(&listADownloads), getCounter(&listBDownloads))
+	}
+	if list := s.getIDList("list_a"); list == nil || !unsyncIDList(list.ids)["id_a"] {
+		t.Error("Expected list_a to be loaded")
+	}
+	if list := s.getIDList("list_b"); list == nil || !unsyncIDList(list.ids)["id_b"] {
+		t.Error("Expected list_b to be loaded")
+	}
+
+	s.fetchIDListsFromServer()
+	if getCounter(&listADownloads) != 1 || getCounter(&listBDownloads) != 1 {
+		t.Error("Expected fully downloaded lists not to be downloaded again")
+	}
+}
+
+func TestLastSyncError(t *testing.T) {
+	var forbidden int32 = 1
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if atomic.LoadInt32(&forbidden) == 1 {
+			res.WriteHeader(http.StatusForbidden)
+			return
+		}
+		res.WriteHeader(http.StatusOK)
+		if strings.Contains(req.URL.Path, "download_config_specs") {
+			v, _ := json.Marshal(&downloadConfigSpecResponse{HasUpdates: true, Time: getUnixMilli()})
+			_, _ = res.Write(v)
+		} else if strings.Contains(req.URL.Path, "get_id_lists") {
+			_, _ = res.Write([]byte("{}"))
+		}
+	}))
+	defer testServer.Close()
+
+	c := NewClientWithOptions("secret-key", &Options{
+		API:                  testServer.URL,
+		OutputLoggerOptions:  getOutputLoggerOptionsForTest(t),
+		StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+	})
+	defer c.Shutdown()
+
+	var transportError *TransportError
+	if err := c.LastSyncError(); !errors.As(err, &transportError) {
+		t.Fatalf("Expected a TransportError after a failed sync, received %v", err)
+	}
+	if transportError.RequestMetadata == nil || transportError.RequestMetadata.StatusCode != http.StatusForbidden {
+		t.Errorf("Expected the sync error to carry status 403, received %+v", transportError.RequestMetadata)
+	}
+
+	atomic.StoreInt32(&forbidden, 0)
+	c.evaluator.store.fetchConfigSpecsFromServer(nil)
+	if err := c.LastSyncError(); err != nil {
+		t.Errorf("Expected the sync error to be cleared after a successful sync, received %v", err)
+	}
+}
+
+func TestSDKKeyMismatch(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.WriteHeader(http.StatusOK)
+		if strings.Contains(req.URL.Path, "download_config_specs") {
+			v, _ := json.Marshal(&downloadConfigSpecResponse{
+				HasUpdates:       true,
+				Time:             getUnixMilli(),
+				HashedSDKKeyUsed: getDJB2Hash("secret-other-key"),
+			})
+			_, _ = res.Write(v)
+		} else if strings.Contains(req.URL.Path, "get_id_lists") {
+			_, _ = res.Write([]byte("{}"))
+		}
+	}))
+	defer testServer.Close()
+
+	c, details := NewClientWithDetails("secret-key", &Options{
+		API:                  testServer.URL,
+		OutputLoggerOptions:  getOutputLoggerOptionsForTest(t),
+		StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+	})
+	defer c.Shutdown()
+
+	if !errors.Is(details.Error, ErrSDKKeyMismatch) {
+		t.Errorf("Expected initialization to report ErrSDKKeyMismatch, received %v", details.Error)
+	}
+	var mismatch *SDKKeyMismatchError
+	if !errors.As(c.LastSyncError(), &mismatch) {
+		t.Fatalf("Expected the last sync error to be an SDKKeyMismatchError, received %v", c.LastSyncError())
+	}
+	if mismatch.Expected != getDJB2Hash("secret-key") || mismatch.Received != getDJB2Hash("secret-other-key") {
+		t.Errorf("Expected the mismatch to carry both hashed keys, received %+v", mismatch)
+	}
+}
+
+func TestExportIDLists(t *testing.T) {
+	var testServer *httptest.Server
+	testServer = httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.WriteHeader(http.StatusOK)
+		switch {
+		case strings.Contains(req.URL.Path, "download_config_specs"):
+			v, _ := json.Marshal(&downloadConfigSpecResponse{HasUpdates: true, Time: getUnixMilli()})
+			_, _ = res.Write(v)
+		case strings.Contains(req.URL.Path, "get_id_lists"):
+			v, _ := json.Marshal(map[string]idList{
+				"employees": {Name: "employees", Size: 20, URL: testServer.URL + "/employees", CreationTime: 1, FileID: "employees_file"},
+				"empty":     {Name: "empty", Size: 0, URL: testServer.URL + "/empty", CreationTime: 1, FileID: "empty_file"},
+			})
+			_, _ = res.Write(v)
+		case strings.HasSuffix(req.URL.Path, "/employees"):
+			_, _ = res.Write([]byte("+bbbb\n+aaaa\n-aaaa\n+cccc\n"))
+		}
+	}))
+	defer testServer.Close()
+
+	c := NewClientWithOptions("secret-key", &Options{
+		API:                  testServer.URL,
+		OutputLoggerOptions:  getOutputLoggerOptionsForTest(t),
+		StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+	})
+	defer c.Shutdown()
+
+	expected := map[string][]string{"employees": {"bbbb", "cccc"}, "empty": {}}
+	exported := c.ExportIDLists()
+	if !reflect.DeepEqual(exported, expected) {
+		t.Errorf("Expected exported ID lists %v, received %v", expected, exported)
+	}
+
+	exported["employees"][0] = "changed"
+	if ids := c.ExportIDLists()["employees"]; ids[0] != "bbbb" {
+		t.Errorf("Expected the export to be a copy, received %v", ids)
+	}
+}
+
+func TestGetExperimentLayerInfo(t *testing.T) {
+	specs, _ := os.ReadFile("download_config_specs.json")
+	c := newLocalModeClientForTest(t, specs, nil)
+	defer c.Shutdown()
+
+	expected := map[string]ExperimentLayerInfo{
+		"sample_experiment":                {LayerName: "a_layer", InLayer: true, ExperimentExists: true},
+		"experiment_with_holdout_and_gate": {ExperimentExists: true},
+		"non_exist_experiment":             {},
+	}
+	for experiment, info := range expected {
+		if received := c.GetExperimentLayerInfo(experiment); received != info {
+			t.Errorf("Expected %+v for %s, received %+v", info, experiment, received)
+		}
+	}
+}
+
+func TestSyncNow(t *testing.T) {
+	var version int32 = 1
+	var downloads int32
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if !strings.Contains(req.URL.Path, "download_config_specs") {
+			res.WriteHeader(http.StatusOK)
+			return
+		}
+		incrementCounter(&downloads)
+		current := int64(getCounter(&version))
+		sinceTime, _ := strconv.ParseInt(req.URL.Query().Get("sinceTime"), 10, 64)
+		response := downloadConfigSpecResponse{HasUpdates: sinceTime < current, Time: current}
+		if response.HasUpdates {
+			response.FeatureGates = []configSpec{
+				{Name: "webhook_gate", Type: "feature_gate", Enabled: current > 1, Rules: []configRule{
+					publicRule("public_rule"),
+				}},
+			}
+		}
+		v, _ := json.Marshal(response)
+		res.WriteHeader(http.StatusOK)
+		_, _ = res.Write(v)
+	}))
+	defer testServer.Close()
+
+	var rulesUpdates int32
+	c := NewClientWithOptions("secret-key", &Options{
+		API:                  testServer.URL,
+		ConfigSyncInterval:   time.Hour,
+		OutputLoggerOptions:  getOutputLoggerOptionsForTest(t),
+		StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+		RulesUpdatedCallback: func(rules string, time int64) { incrementCounter(&rulesUpdates) },
+	})
+	defer c.Shutdown()
+	user := User{UserID: "a-user"}
+
+	if c.CheckGate(user, "webhook_gate") {
+		t.Error("Expected the gate to start disabled")
+	}
+	if updated, err := c.SyncNow(); updated || err != nil {
+		t.Errorf("Expected no update before the specs change, received %v %v", updated, err)
+	}
+
+	atomic.StoreInt32(&version, 2)
+	results := make(chan bool, 5)
+	wg := sync.WaitGroup{}
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			updated, _ := c.SyncNow()
+			results <- updated
+		}()
+	}
+	wg.Wait()
+	close(results)
+	updates := 0
+	for updated := range results {
+		if updated {
+			updates++
+		}
+	}
+	if updates != 1 || getCounter(&rulesUpdates) != 2 {
+		t.Errorf("Expected concurrent syncs to apply the new specs once, received %d updates and %d callbacks", updates, getCounter(&rulesUpdates))
+	}
+	if !c.CheckGate(user, "webhook_gate") {
+		t.Error("Expected the gate to be enabled right after SyncNow")
+	}
+}
+
+func TestCaseInsensitiveConfigNames(t *testing.T) {
+	specs, _ := json.Marshal(downloadConfigSpecResponse{
+		HasUpdates: true,
+		Time:       1,
+		FeatureGates: []configSpec{
+			{Name: "my_gate", Type: "feature_gate", Enabled: true, Rules: []configRule{publicRule("public")}},
+			{Name: "Shadowed_Gate", Type: "feature_gate", Enabled: true, Rules: []configRule{publicRule("public")}},
+			{Name: "shadowed_gate", Type: "feature_gate", Enabled: false},
+		},
+	})
+	var messages []string
+	InitializeGlobalOutputLogger(OutputLoggerOptions{
+		LogCallback: func(message string, err error) {
+			messages = append(messages, message)
+		},
+	})
+	defer InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+	newClient := func(caseInsensitive bool) *Client {
+		return NewClientWithOptions("secret-key", &Options{
+			LocalMode:                  true,
+			BootstrapValues:            string(specs),
+			CaseInsensitiveConfigNames: caseInsensitive,
+			StatsigLoggerOptions:       getStatsigLoggerOptionsForTest(t),
+		})
+	}
+	user := User{UserID: "a-user"}
+
+	exact := newClient(false)
+	defer exact.Shutdown()
+	if exact.CheckGate(user, "My_Gate") {
+		t.Error("Expected names to be case sensitive by default")
+	}
+
+	messages = nil
+	c := newClient(true)
+	defer c.Shutdown()
+	if !c.CheckGate(user, "My_Gate") || !c.CheckGate(user, "MY_GATE") {
+		t.Error("Expected a differently cased name to resolve under CaseInsensitiveConfigNames")
+	}
+	if gate := c.GetGate(user, "My_Gate"); gate.Name != "my_gate" {
+		t.Errorf("Expected the result to carry the canonical name my_gate, received %s", gate.Name)
+	}
+	c.logger.mu.Lock()
+	for _, evt := range c.logger.events {
+		if exposure, ok := evt.(ExposureEvent); ok && exposure.Metadata["gate"] != "my_gate" {
+			t.Errorf("Expected exposures to be logged under the canonical name my_gate, received %s", exposure.Metadata["gate"])
+		}
+	}
+	c.logger.mu.Unlock()
+	if !c.CheckGate(user, "SHADOWED_GATE") {
+		t.Error("Expected the first of the names differing only by case to be kept")
+	}
+	if c.CheckGate(user, "shadowed_gate") {
+		t.Error("Expected exact names to take precedence over the case-insensitive index")
+	}
+	warned := false
+	for _, message := range messages {
+		warned = warned || strings.Contains(message, "Shadowed_Gate and shadowed_gate differ only by case")
+	}
+	if !warned {
+		t.Errorf("Expected a warning about names differing only by case, received %v", messages)
+	}
+}
+
 func compareIDLists(l1 *idList, l2 *idList) bool {
 	if l1.Name != l2.Name || atomic.LoadInt64(&l1.Size) != atomic.LoadInt64(&l2.Size) || l1.URL != l2.URL || l1.CreationTime != l2.CreationTime || l1.FileID != l2.FileID {
 		return false
@@ -306,3 +710,183 @@ func (s *store) getConfigsCount() int {
 	defer s.mu.RUnlock()
 	return len(s.dynamicConfigs)
 }
+
+func TestSpecsUpdatedCallback(t *testing.T) {
+	var version int32 = 1
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if !strings.Contains(req.URL.Path, "download_config_specs") {
+			res.WriteHeader(http.StatusOK)
+			return
+		}
+		current := int64(getCounter(&version))
+		gate := func(name string, enabled bool) configSpec {
+			return configSpec{Name: name, Type: "feature_gate", Enabled: enabled}
+		}
+		response := downloadConfigSpecResponse{HasUpdates: true, Time: current}
+		if current == 1 {
+			response.FeatureGates = []configSpec{gate("kept_gate", true), gate("changed_gate", false), gate("removed_gate", true)}
+			response.DynamicConfigs = []configSpec{{Name: "a_config", Type: "dynamic_config", Enabled: true}}
+		} else {
+			response.FeatureGates = []configSpec{gate("kept_gate", true), gate("changed_gate", true), gate("added_gate", true)}
+			response.DynamicConfigs = []configSpec{{Name: "a_config", Type: "dynamic_config", Enabled: true}}
+			response.LayerConfigs = []configSpec{{Name: "a_layer", Type: "dynamic_config", Enabled: true}}
+		}
+		v, _ := json.Marshal(response)
+		res.WriteHeader(http.StatusOK)
+		_, _ = res.Write(v)
+	}))
+	defer testServer.Close()
+
+	var mu sync.Mutex
+	var diffs []SpecsDiff
+	var rulesUpdates int32
+	c := NewClientWithOptions("secret-key", &Options{
+		API:                  testServer.URL,
+		ConfigSyncInterval:   time.Hour,
+		OutputLoggerOptions:  getOutputLoggerOptionsForTest(t),
+		StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+		RulesUpdatedCallback: func(rules string, time int64) { incrementCounter(&rulesUpdates) },
+		SpecsUpdatedCallback: func(changed SpecsDiff) {
+			mu.Lock()
+			diffs = append(diffs, changed)
+			mu.Unlock()
+		},
+	})
+	defer c.Shutdown()
+
+	atomic.StoreInt32(&version, 2)
+	if updated, err := c.SyncNow(); !updated || err != nil {
+		t.Fatalf("Expected the sync to apply new specs, received %v %v", updated, err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(diffs) != 2 {
+		t.Fatalf("Expected a diff for the initial load and the sync, received %d", len(diffs))
+	}
+	if !reflect.DeepEqual(diffs[0].AddedGates, []string{"changed_gate", "kept_gate", "removed_gate"}) {
+		t.Errorf("Expected every gate to be added on the initial load, received %v", diffs[0].AddedGates)
+	}
+	expected := SpecsDiff{
+		AddedGates:    []string{"added_gate"},
+		RemovedGates:  []string{"removed_gate"},
+		ModifiedGates: []string{"changed_gate"},
+		AddedLayers:   []string{"a_layer"},
+	}
+	if !reflect.DeepEqual(diffs[1], expected) {
+		t.Errorf("Expected %+v, received %+v", expected, diffs[1])
+	}
+	if getCounter(&rulesUpdates) != 2 {
+		t.Errorf("Expected RulesUpdatedCallback to still be called on each update, received %d", getCounter(&rulesUpdates))
+	}
+}
+
+func TestDuplicateConfigNames(t *testing.T) {
+	specs, _ := json.Marshal(downloadConfigSpecResponse{
+		HasUpdates: true,
+		Time:       1,
+		FeatureGates: []configSpec{
+			{Name: "renamed_gate", Type: "feature_gate", Enabled: true, Rules: []configRule{publicRule("public")}},
+			{Name: "renamed_gate", Type: "feature_gate", Enabled: false},
+		},
+	})
+	var messages []string
+	InitializeGlobalOutputLogger(OutputLoggerOptions{
+		LogCallback: func(message string, err error) {
+			messages = append(messages, message)
+		},
+	})
+	defer InitializeGlobalOutputLogger(getOutputLoggerOptionsForTest(t))
+	newClient := func(behavior string) *Client {
+		return NewClientWithOptions("secret-key", &Options{
+			LocalMode:                   true,
+			BootstrapValues:             string(specs),
+			DuplicateConfigNameBehavior: behavior,
+			StatsigLoggerOptions:        getStatsigLoggerOptionsForTest(t),
+		})
+	}
+	user := User{UserID: "a-user"}
+	warned := func(kept string) bool {
+		for _, message := range messages {
+			if strings.Contains(message, "feature_gate renamed_gate is defined more than once") && strings.Contains(message, "Using the "+kept) {
+				return true
+			}
+		}
+		return false
+	}
+
+	last := newClient("")
+	defer last.Shutdown()
+	if last.CheckGate(user, "renamed_gate") {
+		t.Error("Expected the last definition to be kept by default")
+	}
+	if !warned("last") {
+		t.Errorf("Expected a warning about the duplicate name, received %v", messages)
+	}
+
+	messages = nil
+	first := newClient(DuplicateNameKeepFirst)
+	defer first.Shutdown()
+	if !first.CheckGate(user, "renamed_gate") {
+		t.Error("Expected the first definition to be kept with DuplicateNameKeepFirst")
+	}
+	if !warned("first") {
+		t.Errorf("Expected a warning about the duplicate name, received %v", messages)
+	}
+
+	messages = nil
+	var resync downloadConfigSpecResponse
+	_ = json.Unmarshal(specs, &resync)
+	resync.Time = 2
+	first.evaluator.store.setConfigSpecs(resync)
+	if warned("first") {
+		t.Errorf("Expected unchanged duplicates not to be reported again, received %v", messages)
+	}
+}
+
+func TestUpdateRuntimeSyncIntervalResetsPolling(t *testing.T) {
+	var downloads int32
+	testServer := getTestServer(testServerOptions{onDCS: func() { incrementCounter(&downloads) }})
+	defer testServer.Close()
+	c := NewClientWithOptions("secret-key", &Options{
+		API:                  testServer.URL,
+		ConfigSyncInterval:   time.Hour,
+		IDListSyncInterval:   time.Hour,
+		OutputLoggerOptions:  getOutputLoggerOptionsForTest(t),
+		StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+	})
+	defer c.Shutdown()
+	initial := getCounter(&downloads)
+
+	if err := c.UpdateRuntimeOptions(RuntimeOptions{ConfigSyncInterval: 20 * time.Millisecond}); err != nil {
+		t.Fatalf("Expected the update to succeed, received %s", err)
+	}
+	time.Sleep(300 * time.Millisecond)
+	if getCounter(&downloads) <= initial {
+		t.Error("Expected the shorter interval to apply without waiting out the hour long one")
+	}
+}
+
+func TestGetLayerAllocatedExperiment(t *testing.T) {
+	specs, _ := os.ReadFile("download_config_specs.json")
+	c := newLocalModeClientForTest(t, specs, nil)
+	defer c.Shutdown()
+	user := User{UserID: "123"}
+
+	experiment, allocated := c.GetLayerAllocatedExperiment(user, "a_layer")
+	if !allocated || experiment != "sample_experiment" {
+		t.Errorf("Expected the user to be allocated to sample_experiment, received %s %v", experiment, allocated)
+	}
+	if layer := c.GetLayer(user, "a_layer"); layer.AllocatedExperimentName != experiment {
+		t.Errorf("Expected the same allocation as GetLayer, received %s", layer.AllocatedExperimentName)
+	}
+	if experiment, allocated := c.GetLayerAllocatedExperiment(user, "non_exist_layer"); allocated || experiment != "" {
+		t.Errorf("Expected no allocation for an unknown layer, received %s %v", experiment, allocated)
+	}
+	c.logger.mu.Lock()
+	buffered := len(c.logger.events)
+	c.logger.mu.Unlock()
+	if buffered != 0 {
+		t.Errorf("Expected no exposures to be logged, received %d events", buffered)
+	}
+}
@@ -0,0 +1,19 @@
+package statsig
+
+import "testing"
+
+// Lives in a _test file because it builds on the test logger options from evaluation_test.go.
+func newLocalModeClientForTest(t *testing.T, specs []byte, options *Options) *Client {
+	if options == nil {
+		options = &Options{}
+	}
+	options.LocalMode = true
+	options.BootstrapValues = string(specs)
+	options.OutputLoggerOptions = getOutputLoggerOptionsForTest(t)
+	options.StatsigLoggerOptions = getStatsigLoggerOptionsForTest(t)
+	return NewClientWithOptions("secret-key", options)
+}
+
+func publicRule(id string) configRule {
+	return configRule{ID: id, PassPercentage: 100, Conditions: []configCondition{{Type: "public"}}}
+}
@@ -4,8 +4,10 @@ import (
 	"bytes"
 	"compress/gzip"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -24,10 +26,12 @@ const (
 )
 
 type transport struct {
-	sdkKey   string
-	metadata statsigMetadata // Safe to read from but not thread safe to write into. If value needs to change, please ensure thread safety.
-	client   *http.Client
-	options  *Options
+	sdkKey       string
+	metadata     statsigMetadata // Safe to read from but not thread safe to write into. If value needs to change, please ensure thread safety.
+	client       *http.Client
+	options      *Options
+	maxRetries   int
+	retryBackoff time.Duration
 }
 
 func newTransport(secret string, options *Options) *transport {
@@ -42,18 +46,53 @@ func newTransport(secret string, options *Options) *transport {
 		sdkKey:   secret,
 		client: &http.Client{
 			Timeout:   time.Second * 3,
-			Transport: options.Transport,
+			Transport: newRoundTripper(options),
 		},
-		options: options,
+		options:      options,
+		maxRetries:   options.MaxRetries,
+		retryBackoff: options.RetryBackoff,
 	}
 }
 
+// Applies Options.HTTPClientConfig and Options.DialContext on top of the default transport, unless a custom Transport was supplied
+func newRoundTripper(options *Options) http.RoundTripper {
+	config := options.HTTPClientConfig
+	if options.Transport != nil || (config == (HTTPClientConfig{}) && options.DialContext == nil) {
+		return options.Transport
+	}
+	roundTripper := http.DefaultTransport.(*http.Transport).Clone()
+	if options.DialContext != nil {
+		roundTripper.DialContext = options.DialContext
+	}
+	if config.MaxIdleConns > 0 {
+		roundTripper.MaxIdleConns = config.MaxIdleConns
+	}
+	if config.MaxIdleConnsPerHost > 0 {
+		roundTripper.MaxIdleConnsPerHost = config.MaxIdleConnsPerHost
+	}
+	if config.IdleConnTimeout > 0 {
+		roundTripper.IdleConnTimeout = config.IdleConnTimeout
+	}
+	return roundTripper
+}
+
 type RequestOptions struct {
 	retries int
 	backoff time.Duration
 	header  map[string]string
 }
 
+// Request options for an endpoint that retries defaultRetries times unless Options.MaxRetries is set
+func (transport *transport) retryOptions(defaultRetries int) RequestOptions {
+	options := RequestOptions{retries: defaultRetries, backoff: transport.retryBackoff}
+	if transport.maxRetries > 0 {
+		options.retries = transport.maxRetries
+	} else if transport.maxRetries < 0 {
+		options.retries = 0
+	}
+	return options
+}
+
 func (opts *RequestOptions) fill_defaults() {
 	if opts.backoff == 0 {
 		opts.backoff = time.Second
@@ -68,20 +107,32 @@ func (transport *transport) download_config_specs(sinceTime int64, responseBody
 	} else {
 		endpoint = fmt.Sprintf("/download_config_specs/%s.json?sinceTime=%d", transport.sdkKey, sinceTime)
 	}
-	options := RequestOptions{}
-	if transport.options.FallbackToStatsigAPI {
-		options.retries = 1
+	options := transport.retryOptions(transport.defaultFetchRetries())
+	if builder := transport.options.DownloadConfigSpecsRequestBuilder; builder != nil {
+		request, err := builder(sinceTime, transport.sdkKey)
+		if err == nil && request == nil {
+			err = fmt.Errorf("DownloadConfigSpecsRequestBuilder returned no request")
+		}
+		if err != nil {
+			return nil, &TransportError{Err: err}
+		}
+		transport.addDefaultHeaders(request)
+		return transport.sendRequest(request, request.URL.Path, responseBody, options, diagnostics)
 	}
 	return transport.get(endpoint, responseBody, options, diagnostics)
 }
 
 func (transport *transport) get_id_lists(responseBody interface{}, diagnostics *marker) (*http.Response, error) {
 	diagnostics.getIdListSources().networkRequest().start().mark()
-	options := RequestOptions{}
+	options := transport.retryOptions(transport.defaultFetchRetries())
+	return transport.post("/get_id_lists", nil, responseBody, options, diagnostics)
+}
+
+func (transport *transport) defaultFetchRetries() int {
 	if transport.options.FallbackToStatsigAPI {
-		options.retries = 1
+		return 1
 	}
-	return transport.post("/get_id_lists", nil, responseBody, options, diagnostics)
+	return 0
 }
 
 func (transport *transport) get_id_list(url string, headers map[string]string) (*http.Response, error) {
@@ -93,6 +144,7 @@ func (transport *transport) get_id_list(url string, headers map[string]string) (
 	for k, v := range headers {
 		req.Header.Set(k, v)
 	}
+	transport.addDefaultHeaders(req)
 
 	res, err := transport.client.Do(req)
 
@@ -107,7 +159,8 @@ func (transport *transport) get_id_list(url string, headers map[string]string) (
 				Endpoint:   url,
 				Retries:    0,
 			},
-			Err: err}
+			Err:   err,
+			Class: transport.classifyError(res, err)}
 	}
 
 	return res, nil
@@ -197,39 +250,48 @@ func (transport *transport) buildRequest(method, endpoint string, body interface
 		req.Header.Add(k, v)
 
 	}
+	transport.addDefaultHeaders(req)
 	return req, nil
 }
 
+// Adds Options.DefaultHeaders to the request. Headers already on the request, and the STATSIG- headers
+// the SDK reserves for itself, are left as they are
+func (transport *transport) addDefaultHeaders(req *http.Request) {
+	for k, v := range transport.options.DefaultHeaders {
+		if req.Header.Get(k) != "" || strings.HasPrefix(strings.ToUpper(k), "STATSIG-") {
+			continue
+		}
+		req.Header.Set(k, v)
+	}
+}
+
 func (t *transport) buildURL(path string, isRetry bool) (*url.URL, error) {
-	var api string
-	useDefaultAPI := isRetry && t.options.FallbackToStatsigAPI
 	endpoint := strings.TrimPrefix(path, "/v1")
+	return url.Parse(strings.TrimSuffix(t.getAPI(endpoint, isRetry), "/") + endpoint)
+}
+
+func (t *transport) getAPI(endpoint string, isRetry bool) string {
+	useDefaultAPI := isRetry && t.options.FallbackToStatsigAPI
 	if strings.Contains(endpoint, "download_config_specs") {
 		if useDefaultAPI {
-			api = StatsigCDN
-		} else {
-			api = defaultString(t.options.APIOverrides.DownloadConfigSpecs, defaultString(t.options.API, StatsigCDN))
+			return StatsigCDN
 		}
+		return defaultString(t.options.APIOverrides.DownloadConfigSpecs, defaultString(t.options.API, StatsigCDN))
 	} else if strings.Contains(endpoint, "get_id_list") {
 		if useDefaultAPI {
-			api = StatsigAPI
-		} else {
-			api = defaultString(t.options.APIOverrides.GetIDLists, defaultString(t.options.API, StatsigAPI))
+			return StatsigAPI
 		}
+		return defaultString(t.options.APIOverrides.GetIDLists, defaultString(t.options.API, StatsigAPI))
 	} else if strings.Contains(endpoint, "log_event") {
 		if useDefaultAPI {
-			api = StatsigAPI
-		} else {
-			api = defaultString(t.options.APIOverrides.LogEvent, defaultString(t.options.API, StatsigAPI))
-		}
-	} else {
-		if useDefaultAPI {
-			api = StatsigAPI
-		} else {
-			api = defaultString(t.options.API, StatsigAPI)
+			return StatsigAPI
 		}
+		return defaultString(t.options.APIOverrides.LogEvent, defaultString(t.options.API, StatsigAPI))
+	}
+	if useDefaultAPI {
+		return StatsigAPI
 	}
-	return url.Parse(strings.TrimSuffix(api, "/") + endpoint)
+	return defaultString(t.options.API, StatsigAPI)
 }
 
 func (t *transport) updateRequestForRetry(r *http.Request) *http.Request {
@@ -237,6 +299,9 @@ func (t *transport) updateRequestForRetry(r *http.Request) *http.Request {
 	if err == nil && strings.Compare(r.URL.Host, retryURL.Host) != 0 {
 		retryRequest, err := http.NewRequest(r.Method, retryURL.String(), r.Body)
 		if err == nil {
+			// Keeps the headers, e.g. Content-Encoding, and the already encoded body of the original request
+			retryRequest.Header = r.Header.Clone()
+			retryRequest.GetBody = r.GetBody
 			return retryRequest
 		}
 	}
@@ -258,8 +323,24 @@ func (transport *transport) doRequest(
 		}
 		return nil, nil
 	}
+	return transport.sendRequest(request, endpoint, out, options, diagnostics)
+}
+
+func (transport *transport) sendRequest(
+	request *http.Request,
+	endpoint string,
+	out interface{},
+	options RequestOptions,
+	diagnostics *marker,
+) (*http.Response, error) {
 	options.fill_defaults()
 	response, err, attempts := retry(options.retries, time.Duration(options.backoff), func() (*http.Response, bool, error) {
+		// A sent body is consumed, so every attempt resends the body as encoded when the request was built
+		if request.GetBody != nil {
+			if body, err := request.GetBody(); err == nil {
+				request.Body = body
+			}
+		}
 		response, err := transport.client.Do(request)
 
 		if diagnostics != nil {
@@ -276,7 +357,7 @@ func (transport *transport) doRequest(
 		}
 
 		if err != nil {
-			return response, response != nil, err
+			return response, transport.classifyError(response, err) == NetworkErrorRetryable, err
 		}
 
 		retryRequest := transport.updateRequestForRetry(request)
@@ -297,12 +378,14 @@ func (transport *transport) doRequest(
 			return response, false, transport.parseResponse(response, out)
 		}
 
-		return response, retryableStatusCode(response.StatusCode), fmt.Errorf("%s", response.Status)
+		err = fmt.Errorf("%s", response.Status)
+		return response, transport.classifyError(response, err) == NetworkErrorRetryable, err
 	})
 
 	if err != nil {
+		class := transport.classifyError(response, err)
 		if response == nil {
-			return response, &TransportError{Err: err}
+			return response, &TransportError{Err: err, Class: class}
 		}
 		return response, &TransportError{
 			RequestMetadata: &RequestMetadata{
@@ -310,7 +393,8 @@ func (transport *transport) doRequest(
 				Endpoint:   endpoint,
 				Retries:    attempts,
 			},
-			Err: err,
+			Err:   err,
+			Class: class,
 		}
 	}
 
@@ -342,6 +426,36 @@ func retry(retries int, backoff time.Duration, fn func() (*http.Response, bool,
 	}
 }
 
+func (transport *transport) classifyError(response *http.Response, err error) NetworkErrorClass {
+	statusCode := 0
+	if response != nil {
+		statusCode = response.StatusCode
+	}
+	if transport.options != nil && transport.options.NetworkErrorClassifier != nil {
+		if class := transport.options.NetworkErrorClassifier(statusCode, err); class != "" {
+			return class
+		}
+	}
+	return classifyNetworkError(statusCode, err)
+}
+
+func classifyNetworkError(statusCode int, err error) NetworkErrorClass {
+	var netErr net.Error
+	switch {
+	case statusCode == 0 && errors.As(err, &netErr) && netErr.Timeout():
+		return NetworkErrorRetryable
+	case statusCode == 0:
+		return NetworkErrorUnreachable
+	case retryableStatusCode(statusCode):
+		return NetworkErrorRetryable
+	case statusCode == 401 || statusCode == 403:
+		return NetworkErrorAuth
+	case statusCode >= 400 && statusCode < 500:
+		return NetworkErrorBadRequest
+	}
+	return NetworkErrorOther
+}
+
 func retryableStatusCode(code int) bool {
 	switch code {
 	case 408, 500, 502, 503, 504, 522, 524, 599:
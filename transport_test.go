@@ -1,11 +1,21 @@
 package statsig
 
 import (
+	"compress/gzip"
+	"context"
 	"encoding/json"
+	"errors"
+	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 type Empty struct{}
@@ -106,3 +116,303 @@ func TestProxy(t *testing.T) {
 		t.Errorf("Expected request to hit proxy server")
 	}
 }
+
+func TestHTTPClientConfig(t *testing.T) {
+	n := newTransport("secret-123", &Options{
+		HTTPClientConfig: HTTPClientConfig{
+			MaxIdleConns:        200,
+			MaxIdleConnsPerHost: 50,
+			IdleConnTimeout:     45 * time.Second,
+		},
+	})
+	roundTripper, ok := n.client.Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("Expected an *http.Transport, received %T", n.client.Transport)
+	}
+	if roundTripper.MaxIdleConns != 200 || roundTripper.MaxIdleConnsPerHost != 50 || roundTripper.IdleConnTimeout != 45*time.Second {
+		t.Errorf("Expected configured pool settings, received %d, %d, %s",
+			roundTripper.MaxIdleConns, roundTripper.MaxIdleConnsPerHost, roundTripper.IdleConnTimeout)
+	}
+	if roundTripper == http.DefaultTransport {
+		t.Error("Expected the default transport to be left unmodified")
+	}
+
+	custom := &http.Transport{}
+	n = newTransport("secret-123", &Options{Transport: custom, HTTPClientConfig: HTTPClientConfig{MaxIdleConns: 200}})
+	if n.client.Transport != custom || custom.MaxIdleConns != 0 {
+		t.Error("Expected a custom Transport to be used as is")
+	}
+}
+
+func TestDialContext(t *testing.T) {
+	testServer := getTestServer(testServerOptions{})
+	defer testServer.Close()
+	serverURL, _ := url.Parse(testServer.URL)
+
+	var dialedAPI int32
+	dialer := &net.Dialer{}
+	c := NewClientWithOptions("secret-key", &Options{
+		API: "http://statsig.invalid:" + serverURL.Port() + "/v1",
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			if addr == "statsig.invalid:"+serverURL.Port() {
+				atomic.AddInt32(&dialedAPI, 1)
+			}
+			return dialer.DialContext(ctx, network, serverURL.Host)
+		},
+		HTTPClientConfig:     HTTPClientConfig{MaxIdleConns: 10},
+		OutputLoggerOptions:  getOutputLoggerOptionsForTest(t),
+		StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+	})
+	defer c.Shutdown()
+
+	if atomic.LoadInt32(&dialedAPI) == 0 {
+		t.Fatal("Expected the custom dialer to be used for the download_config_specs request")
+	}
+	if c.evaluator.store.source != SourceNetwork {
+		t.Errorf("Expected specs to be downloaded through the custom dialer, received source %s", c.evaluator.store.source)
+	}
+	roundTripper, ok := c.transport.client.Transport.(*http.Transport)
+	if !ok || roundTripper.MaxIdleConns != 10 {
+		t.Error("Expected the dialer to coexist with HTTPClientConfig")
+	}
+}
+
+func TestDownloadConfigSpecsRequestBuilder(t *testing.T) {
+	var sinceTimes []string
+	var keys []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/proxy/specs" {
+			res.WriteHeader(http.StatusNotFound)
+			return
+		}
+		sinceTimes = append(sinceTimes, req.URL.Query().Get("since"))
+		keys = append(keys, req.Header.Get("X-Proxy-Key"))
+		res.WriteHeader(http.StatusOK)
+		v, _ := json.Marshal(&downloadConfigSpecResponse{HasUpdates: true, Time: 1234})
+		_, _ = res.Write(v)
+	}))
+	defer proxy.Close()
+
+	c := NewClientWithOptions("secret-key", &Options{
+		API:                  proxy.URL,
+		OutputLoggerOptions:  getOutputLoggerOptionsForTest(t),
+		StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+		DownloadConfigSpecsRequestBuilder: func(sinceTime int64, sdkKey string) (*http.Request, error) {
+			req, err := http.NewRequest("GET", proxy.URL+"/proxy/specs?since="+strconv.FormatInt(sinceTime, 10), nil)
+			if err != nil {
+				return nil, err
+			}
+			req.Header.Set("X-Proxy-Key", sdkKey)
+			return req, nil
+		},
+	})
+	defer c.Shutdown()
+	c.evaluator.store.fetchConfigSpecsFromServer(nil)
+
+	if len(sinceTimes) != 2 || sinceTimes[0] != "0" || sinceTimes[1] != "1234" {
+		t.Errorf("Expected the proxy to receive since times [0 1234], received %v", sinceTimes)
+	}
+	if len(keys) == 0 || keys[0] != "secret-key" {
+		t.Errorf("Expected the builder to receive the SDK key, received %v", keys)
+	}
+	if c.evaluator.store.source != SourceNetwork {
+		t.Errorf("Expected specs from the custom request to be parsed, received source %s", c.evaluator.store.source)
+	}
+}
+
+func TestRetryOptions(t *testing.T) {
+	var hits int32
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		res.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer testServer.Close()
+	var out ServerResponse
+
+	n := newTransport("secret-123", &Options{API: testServer.URL, MaxRetries: 2, RetryBackoff: time.Millisecond})
+	start := time.Now()
+	_, err := n.post("/log_event", Empty{}, &out, n.retryOptions(maxRetries), nil)
+	if err == nil || atomic.LoadInt32(&hits) != 3 {
+		t.Errorf("Expected MaxRetries to allow 3 attempts, received %d", atomic.LoadInt32(&hits))
+	}
+	if elapsed := time.Since(start); elapsed < 11*time.Millisecond || elapsed > time.Second {
+		t.Errorf("Expected backoffs of 1ms then 10ms, took %v", elapsed)
+	}
+
+	atomic.StoreInt32(&hits, 0)
+	_, _ = n.download_config_specs(0, &out, newDiagnostics(&Options{}).configSync())
+	if atomic.LoadInt32(&hits) != 3 {
+		t.Errorf("Expected MaxRetries to apply to config spec downloads, received %d attempts", atomic.LoadInt32(&hits))
+	}
+
+	atomic.StoreInt32(&hits, 0)
+	n = newTransport("secret-123", &Options{API: testServer.URL, MaxRetries: -1})
+	_, _ = n.post("/log_event", Empty{}, &out, n.retryOptions(maxRetries), nil)
+	if atomic.LoadInt32(&hits) != 1 {
+		t.Errorf("Expected a negative MaxRetries to disable retries, received %d attempts", atomic.LoadInt32(&hits))
+	}
+
+	n = newTransport("secret-123", &Options{API: testServer.URL})
+	if options := n.retryOptions(maxRetries); options.retries != maxRetries {
+		t.Errorf("Expected log_event to keep %d retries by default, received %d", maxRetries, options.retries)
+	}
+	if options := n.retryOptions(n.defaultFetchRetries()); options.retries != 0 {
+		t.Errorf("Expected config spec downloads to keep no retries by default, received %d", options.retries)
+	}
+}
+
+type headerRecordingRoundTripper struct {
+	mu       sync.Mutex
+	requests []*http.Request
+}
+
+func (r *headerRecordingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	r.mu.Lock()
+	r.requests = append(r.requests, req)
+	r.mu.Unlock()
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("{}")), Header: http.Header{}, Request: req}, nil
+}
+
+func TestDefaultHeaders(t *testing.T) {
+	recorder := &headerRecordingRoundTripper{}
+	n := newTransport("secret-123", &Options{
+		Transport: recorder,
+		DefaultHeaders: map[string]string{
+			"Authorization":   "Bearer gateway-token",
+			"X-Tenant-ID":     "tenant-1",
+			"STATSIG-API-KEY": "secret-overwritten",
+			"Content-Type":    "text/plain",
+		},
+	})
+	diagnostics := newDiagnostics(&Options{})
+	var out map[string]interface{}
+	_, _ = n.download_config_specs(0, &out, diagnostics.configSync())
+	_, _ = n.get_id_lists(&out, diagnostics.configSync())
+	_, _ = n.get_id_list("https://idlists.example.com/list", map[string]string{"Range": "bytes=0-"})
+	_, _ = n.log_event([]interface{}{}, &out, RequestOptions{})
+
+	if len(recorder.requests) != 4 {
+		t.Fatalf("Expected 4 requests, received %d", len(recorder.requests))
+	}
+	for _, req := range recorder.requests {
+		if req.Header.Get("Authorization") != "Bearer gateway-token" || req.Header.Get("X-Tenant-ID") != "tenant-1" {
+			t.Errorf("Expected the default headers on %s, received %v", req.URL, req.Header)
+		}
+		if req.Header.Get("STATSIG-API-KEY") == "secret-overwritten" {
+			t.Errorf("Expected the reserved STATSIG-API-KEY header to be ignored on %s", req.URL)
+		}
+		if req.URL.Host != "idlists.example.com" && req.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Expected the SDK's Content-Type to be kept on %s", req.URL)
+		}
+	}
+	if recorder.requests[0].Header.Get("STATSIG-API-KEY") != "secret-123" {
+		t.Error("Expected the SDK key to still be sent")
+	}
+}
+
+func TestNetworkErrorClassification(t *testing.T) {
+	var hits int32
+	var status int32 = http.StatusForbidden
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		res.WriteHeader(int(atomic.LoadInt32(&status)))
+	}))
+	defer testServer.Close()
+	var out ServerResponse
+
+	n := newTransport("secret-123", &Options{API: testServer.URL, MaxRetries: 2, RetryBackoff: time.Millisecond})
+	_, err := n.post("/log_event", Empty{}, &out, n.retryOptions(maxRetries), nil)
+	if atomic.LoadInt32(&hits) != 1 {
+		t.Errorf("Expected a 403 not to be retried, received %d attempts", atomic.LoadInt32(&hits))
+	}
+	if class := ClassifyNetworkError(err); class != NetworkErrorAuth {
+		t.Errorf("Expected a 403 to be classified as %s, received %s", NetworkErrorAuth, class)
+	}
+
+	atomic.StoreInt32(&hits, 0)
+	atomic.StoreInt32(&status, http.StatusServiceUnavailable)
+	_, err = n.post("/log_event", Empty{}, &out, n.retryOptions(maxRetries), nil)
+	if atomic.LoadInt32(&hits) != 3 {
+		t.Errorf("Expected a 503 to be retried, received %d attempts", atomic.LoadInt32(&hits))
+	}
+	if class := ClassifyNetworkError(err); class != NetworkErrorRetryable {
+		t.Errorf("Expected a 503 to be classified as %s, received %s", NetworkErrorRetryable, class)
+	}
+
+	atomic.StoreInt32(&hits, 0)
+	n = newTransport("secret-123", &Options{
+		API:          testServer.URL,
+		MaxRetries:   2,
+		RetryBackoff: time.Millisecond,
+		NetworkErrorClassifier: func(statusCode int, err error) NetworkErrorClass {
+			if statusCode == http.StatusServiceUnavailable {
+				return NetworkErrorOther
+			}
+			return ""
+		},
+	})
+	_, _ = n.post("/log_event", Empty{}, &out, n.retryOptions(maxRetries), nil)
+	if atomic.LoadInt32(&hits) != 1 {
+		t.Errorf("Expected NetworkErrorClassifier to stop retries, received %d attempts", atomic.LoadInt32(&hits))
+	}
+
+	atomic.StoreInt32(&status, http.StatusUnauthorized)
+	c := NewClientWithOptions("secret-123", &Options{
+		API:                  testServer.URL,
+		OutputLoggerOptions:  getOutputLoggerOptionsForTest(t),
+		StatsigLoggerOptions: getStatsigLoggerOptionsForTest(t),
+	})
+	defer c.Shutdown()
+	if class := ClassifyNetworkError(c.LastSyncError()); class != NetworkErrorAuth {
+		t.Errorf("Expected LastSyncError to carry the %s class, received %s", NetworkErrorAuth, class)
+	}
+	if class := ClassifyNetworkError(errors.New("not a request")); class != "" {
+		t.Errorf("Expected no class for other errors, received %s", class)
+	}
+}
+
+type failingOnceRoundTripper struct {
+	mu     sync.Mutex
+	bodies []string
+}
+
+// Reads the whole gzipped body of each request, failing the first one with a 503
+func (r *failingOnceRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	body := ""
+	if req.Body != nil && req.Header.Get("Content-Encoding") == "gzip" {
+		if gz, err := gzip.NewReader(req.Body); err == nil {
+			raw, _ := io.ReadAll(gz)
+			body = string(raw)
+		}
+		req.Body.Close()
+	}
+	r.mu.Lock()
+	r.bodies = append(r.bodies, body)
+	status := http.StatusOK
+	if len(r.bodies) == 1 {
+		status = http.StatusServiceUnavailable
+	}
+	r.mu.Unlock()
+	return &http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader("{}")), Header: http.Header{}, Request: req}, nil
+}
+
+func TestLogEventRetryResendsCompressedBody(t *testing.T) {
+	roundTripper := &failingOnceRoundTripper{}
+	n := newTransport("secret-123", &Options{API: "http://statsig.test", Transport: roundTripper, RetryBackoff: time.Millisecond})
+	events := []interface{}{Event{EventName: "retried_event", User: User{UserID: "a-user"}}}
+	if _, err := n.log_event(events, nil, n.retryOptions(maxRetries)); err != nil {
+		t.Errorf("Expected the retry to succeed, received %s", err)
+	}
+
+	roundTripper.mu.Lock()
+	defer roundTripper.mu.Unlock()
+	if len(roundTripper.bodies) != 2 {
+		t.Fatalf("Expected 2 attempts, received %d", len(roundTripper.bodies))
+	}
+	for i, body := range roundTripper.bodies {
+		var input logEventInput
+		if err := json.Unmarshal([]byte(body), &input); err != nil || len(input.Events) != 1 {
+			t.Errorf("Expected attempt %d to send the gzipped events once, received %q", i+1, body)
+		}
+	}
+}
@@ -1,5 +1,12 @@
 package statsig
 
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+)
+
 // User specific attributes for evaluating Feature Gates, Experiments, and DynamicConfigs
 //
 // NOTE: UserID is **required** - see https://docs.statsig.com/messages/serverRequiredUserID\
@@ -25,6 +32,57 @@ func (user *User) getCopyForLogging() *User {
 	return &copy
 }
 
+// Returns a copy of base with every non-empty field of override applied on top of it
+func mergeUsers(base User, override User) User {
+	merged := base
+	merged.UserID = defaultString(override.UserID, base.UserID)
+	merged.Email = defaultString(override.Email, base.Email)
+	merged.IpAddress = defaultString(override.IpAddress, base.IpAddress)
+	merged.UserAgent = defaultString(override.UserAgent, base.UserAgent)
+	merged.Country = defaultString(override.Country, base.Country)
+	merged.Locale = defaultString(override.Locale, base.Locale)
+	merged.AppVersion = defaultString(override.AppVersion, base.AppVersion)
+	merged.Custom = mergeInterfaceMaps(base.Custom, override.Custom)
+	merged.PrivateAttributes = mergeInterfaceMaps(base.PrivateAttributes, override.PrivateAttributes)
+	merged.StatsigEnvironment = mergeStringMaps(base.StatsigEnvironment, override.StatsigEnvironment)
+	merged.CustomIDs = mergeStringMaps(base.CustomIDs, override.CustomIDs)
+	return merged
+}
+
+func mergeInterfaceMaps(base map[string]interface{}, override map[string]interface{}) map[string]interface{} {
+	if len(base) == 0 {
+		return override
+	}
+	if len(override) == 0 {
+		return base
+	}
+	merged := make(map[string]interface{}, len(base)+len(override))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range override {
+		merged[k] = v
+	}
+	return merged
+}
+
+func mergeStringMaps(base map[string]string, override map[string]string) map[string]string {
+	if len(base) == 0 {
+		return override
+	}
+	if len(override) == 0 {
+		return base
+	}
+	merged := make(map[string]string, len(base)+len(override))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range override {
+		merged[k] = v
+	}
+	return merged
+}
+
 // an event to be sent to Statsig for logging and analysis
 type Event struct {
 	EventName string            `json:"eventName"`
@@ -40,6 +98,7 @@ type configBase struct {
 	RuleID            string                 `json:"rule_id"`
 	GroupName         string                 `json:"group_name"`
 	EvaluationDetails *EvaluationDetails     `json:"evaluation_details"`
+	samplingRate      *int
 }
 
 type FeatureGate struct {
@@ -48,17 +107,70 @@ type FeatureGate struct {
 	RuleID            string             `json:"rule_id"`
 	GroupName         string             `json:"group_name"`
 	EvaluationDetails *EvaluationDetails `json:"evaluation_details"`
+	publicPass        bool
+	samplingRate      *int
+}
+
+// The kind of entity a config was created as in the Statsig Console
+type ConfigType string
+
+const (
+	DynamicConfigType ConfigType = "dynamic_config"
+	ExperimentType    ConfigType = "experiment"
+	AutotuneType      ConfigType = "autotune"
+	HoldoutType       ConfigType = "holdout"
+	LayerType         ConfigType = "layer"
+	FeatureGateType   ConfigType = "feature_gate"
+	SegmentType       ConfigType = "segment"
+)
+
+// Names one exposure for Client.ManuallyLogExposures. Type is FeatureGateType, DynamicConfigType, ExperimentType
+// or LayerType. Parameter is the layer parameter to log and is only read for layers
+type ManualExposureEntry struct {
+	Type      ConfigType
+	Name      string
+	Parameter string
+}
+
+// Where an experiment sits for Client.GetExperimentLayerInfo. An experiment that is loaded but not part of any
+// layer has ExperimentExists set and InLayer unset, while an unknown experiment has neither
+type ExperimentLayerInfo struct {
+	LayerName        string
+	InLayer          bool
+	ExperimentExists bool
+}
+
+// A set of overrides keyed by gate, config and layer name, as applied by Client.WithOverrides and returned by
+// Client.GetAllOverrides. Users holds the overrides set for single users, keyed by user ID
+type Overrides struct {
+	Gates   map[string]bool
+	Configs map[string]map[string]interface{}
+	Layers  map[string]map[string]interface{}
+	Users   map[string]Overrides
 }
 
 // A json blob configured in the Statsig Console
 type DynamicConfig struct {
 	configBase
+	matchedRuleID               string
+	passPercentage              float64
+	entityType                  ConfigType
+	clearUnresolvedPlaceholders bool
 }
 
 type Layer struct {
 	configBase
 	LogExposure             *func(Layer, string) `json:"log_exposure"`
 	AllocatedExperimentName string               `json:"allocated_experiment_name"`
+	exposureLogger          layerExposureLogger
+}
+
+// Logs parameter exposures for a Layer returned by a Client, without allocating a closure per evaluation
+type layerExposureLogger struct {
+	client  *Client
+	user    User
+	res     *evalResult
+	context *evalContext
 }
 
 func NewGate(name string, value bool, ruleID string, groupName string, evaluationDetails *EvaluationDetails) *FeatureGate {
@@ -102,6 +214,78 @@ func NewLayer(name string, value map[string]interface{}, ruleID string, groupNam
 	}
 }
 
+// Returns true if the gate value came from a local override rather than an evaluation
+func (g *FeatureGate) IsOverridden() bool {
+	return g.EvaluationDetails != nil && g.EvaluationDetails.Reason == ReasonLocalOverride
+}
+
+// Returns true if the value came from a local override rather than an evaluation
+func (d *configBase) IsOverridden() bool {
+	return d.EvaluationDetails != nil && d.EvaluationDetails.Reason == ReasonLocalOverride
+}
+
+// Returns true if the gate passed on a rule whose only condition is public, i.e. a rule everyone matches,
+// rather than through targeting. Such a pass says little about the user, which analytics may want to discount
+func (g *FeatureGate) IsPublicPass() bool {
+	return g.publicPass
+}
+
+// Gets the exposure sampling rate of the rule the gate was decided by, for weighting events derived from the
+// evaluation the same way. Returns nil when the rule has no sampling rate or no rule was matched
+func (g *FeatureGate) SamplingRate() *int {
+	return copyIntPointer(g.samplingRate)
+}
+
+// Gets where the gate value came from at the time it was evaluated
+func (g *FeatureGate) Source() EvaluationSource {
+	return g.EvaluationDetails.provenance()
+}
+
+// Gets how the gate value was decided, e.g. ReasonUnrecognized for a gate that is not defined, or SourceUninitialized
+// before specs are loaded. A gate that errored before it could be evaluated reports ReasonError
+func (g *FeatureGate) GetEvaluationDetails() EvaluationDetails {
+	if g.EvaluationDetails == nil {
+		return EvaluationDetails{Reason: ReasonError}
+	}
+	return *g.EvaluationDetails
+}
+
+// Gets where the value came from at the time it was evaluated
+func (d *configBase) Source() EvaluationSource {
+	return d.EvaluationDetails.provenance()
+}
+
+// Gets the exposure sampling rate of the rule the value was decided by, for weighting events derived from the
+// evaluation the same way. Returns nil when the rule has no sampling rate or no rule was matched
+func (d *configBase) SamplingRate() *int {
+	return copyIntPointer(d.samplingRate)
+}
+
+func copyIntPointer(value *int) *int {
+	if value == nil {
+		return nil
+	}
+	copied := *value
+	return &copied
+}
+
+// Gets the ID of the rule whose conditions the user matched, whether or not the pass percentage then allocated them.
+// Returns an empty string when no rule matched and the default value was served
+func (d *DynamicConfig) MatchedRuleID() string {
+	return d.matchedRuleID
+}
+
+// Gets the pass percentage of the matched rule, from 0 to 100. Returns 0 when no rule matched
+func (d *DynamicConfig) PassPercentage() float64 {
+	return d.passPercentage
+}
+
+// Gets the kind of entity the config was created as, distinguishing experiments from plain dynamic configs.
+// Returns an empty ConfigType when the config is not in the loaded specs
+func (d *DynamicConfig) EntityType() ConfigType {
+	return d.entityType
+}
+
 // Gets the string value at the given key in the DynamicConfig
 // Returns the fallback string if the item at the given key is not found or not of type string
 func (d *configBase) GetString(key string, fallback string) string {
@@ -115,6 +299,32 @@ func (d *configBase) GetString(key string, fallback string) string {
 	return fallback
 }
 
+var placeholderPattern = regexp.MustCompile(`\$\{([^{}]+)\}`)
+
+// Gets the string value at the given key in the DynamicConfig with each ${name} placeholder replaced by the
+// user's StatsigEnvironment value for name, else their custom attribute. Unresolved placeholders are kept as
+// written unless Options.ClearUnresolvedPlaceholders is set, in which case they are removed
+// Returns the fallback string if the item at the given key is not found or not of type string
+func (d *DynamicConfig) GetStringInterpolated(key string, fallback string, user User) string {
+	value, ok := d.Value[key].(string)
+	if !ok {
+		return fallback
+	}
+	return placeholderPattern.ReplaceAllStringFunc(value, func(placeholder string) string {
+		name := placeholderPattern.FindStringSubmatch(placeholder)[1]
+		if env, ok := user.StatsigEnvironment[name]; ok {
+			return env
+		}
+		if custom, ok := user.Custom[name]; ok && custom != nil {
+			return fmt.Sprint(custom)
+		}
+		if d.clearUnresolvedPlaceholders {
+			return ""
+		}
+		return placeholder
+	})
+}
+
 // Gets the string value at the given key in the DynamicConfig
 // Returns the fallback string if the item at the given key is not found or not of type string
 func (d *Layer) GetString(key string, fallback string) string {
@@ -154,6 +364,88 @@ func (d *Layer) GetNumber(key string, fallback float64) float64 {
 	return fallback
 }
 
+// Gets the integer value at the given key in the DynamicConfig, exact for values parsed as json.Number
+// Returns the fallback int64 if the item at the given key is not found or not a whole number
+func (d *configBase) GetInt(key string, fallback int64) int64 {
+	if v, ok := d.Value[key]; ok {
+		if val, ok := toInt64(v); ok {
+			return val
+		}
+	}
+	return fallback
+}
+
+// Gets the integer value at the given key in the DynamicConfig, exact for values parsed as json.Number
+// Returns the fallback int64 if the item at the given key is not found or not a whole number
+func (d *Layer) GetInt(key string, fallback int64) int64 {
+	if v, ok := d.Value[key]; ok {
+		if val, ok := toInt64(v); ok {
+			logExposure(d, key)
+			return val
+		}
+	}
+	return fallback
+}
+
+// Gets the slice of integers at the given key in the DynamicConfig
+// Returns the fallback slice if the item at the given key is not found or contains a value that is not a whole number
+func (d *configBase) GetIntSlice(key string, fallback []int64) []int64 {
+	if v, ok := d.Value[key]; ok {
+		if val, ok := toInt64Slice(v); ok {
+			return val
+		}
+	}
+	return fallback
+}
+
+// Gets the slice of integers at the given key in the DynamicConfig
+// Returns the fallback slice if the item at the given key is not found or contains a value that is not a whole number
+func (d *Layer) GetIntSlice(key string, fallback []int64) []int64 {
+	if v, ok := d.Value[key]; ok {
+		if val, ok := toInt64Slice(v); ok {
+			logExposure(d, key)
+			return val
+		}
+	}
+	return fallback
+}
+
+func toInt64(v interface{}) (int64, bool) {
+	switch val := v.(type) {
+	case json.Number:
+		if i, err := val.Int64(); err == nil {
+			return i, true
+		}
+	case float64:
+		if val == math.Trunc(val) && val >= math.MinInt64 && val < math.MaxInt64 {
+			return int64(val), true
+		}
+	case int:
+		return int64(val), true
+	case int64:
+		return val, true
+	case int32:
+		return int64(val), true
+	}
+	return 0, false
+}
+
+func toInt64Slice(v interface{}) ([]int64, bool) {
+	values, ok := v.([]interface{})
+	if !ok {
+		return nil, false
+	}
+	result := make([]int64, 0, len(values))
+	for _, value := range values {
+		i, ok := toInt64(value)
+		if !ok {
+			return nil, false
+		}
+		result = append(result, i)
+	}
+	return result, true
+}
+
 // Gets the boolean value at the given key in the DynamicConfig
 // Returns the fallback boolean if the item at the given key is not found or not of type boolean
 func (d *configBase) GetBool(key string, fallback bool) bool {
@@ -225,11 +517,44 @@ func (d *Layer) GetMap(key string, fallback map[string]interface{}) map[string]i
 	return fallback
 }
 
+// Decodes the whole value into out, which must be a pointer, honoring the json tags of its fields.
+// Returns ErrEmptyConfig when there is no value, or the json error when the value does not fit out
+func (d *configBase) Unmarshal(out interface{}) error {
+	if len(d.Value) == 0 {
+		return fmt.Errorf("%w: %s", ErrEmptyConfig, d.Name)
+	}
+	bytes, err := json.Marshal(d.Value)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(bytes, out); err != nil {
+		return fmt.Errorf("failed to unmarshal %s: %w", d.Name, err)
+	}
+	return nil
+}
+
+// Decodes the whole value into out like DynamicConfig.Unmarshal. Every parameter of the layer is
+// decoded, so an exposure is logged for each of them
+func (d *Layer) Unmarshal(out interface{}) error {
+	if err := d.configBase.Unmarshal(out); err != nil {
+		return err
+	}
+	for parameterName := range d.Value {
+		logExposure(d, parameterName)
+	}
+	return nil
+}
+
 func logExposure(c *Layer, parameterName string) {
-	if c == nil || c.LogExposure == nil {
+	if c == nil {
 		return
 	}
-
-	l := *c.LogExposure
-	l(*c, parameterName)
+	if c.LogExposure != nil {
+		l := *c.LogExposure
+		l(*c, parameterName)
+		return
+	}
+	if l := c.exposureLogger; l.client != nil {
+		l.client.logLayerParameterExposure(l.user, *c, parameterName, l.res, l.context)
+	}
 }
@@ -2,7 +2,10 @@ package statsig
 
 import (
 	"encoding/json"
+	"errors"
+	"os"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -131,3 +134,280 @@ func TestBasic(t *testing.T) {
 		t.Errorf("Failed to get number array")
 	}
 }
+
+func TestGetInt(t *testing.T) {
+	raw := `{"int": 42, "large": 9007199254740993, "fraction": 1.5, "string": "42", "ints": [1, 2, 3], "mixed": [1, 2.5]}`
+
+	var floatValues map[string]interface{}
+	_ = json.Unmarshal([]byte(raw), &floatValues)
+	decoder := json.NewDecoder(strings.NewReader(raw))
+	decoder.UseNumber()
+	var numberValues map[string]interface{}
+	_ = decoder.Decode(&numberValues)
+
+	floatConfig := NewConfig("float_config", floatValues, "rule", "", nil)
+	numberConfig := NewConfig("number_config", numberValues, "rule", "", nil)
+
+	for _, config := range []*DynamicConfig{floatConfig, numberConfig} {
+		if v := config.GetInt("int", 0); v != 42 {
+			t.Errorf("%s: Expected 42, received %d", config.Name, v)
+		}
+		if v := config.GetInt("fraction", -1); v != -1 {
+			t.Errorf("%s: Expected fallback for a non-integer, received %d", config.Name, v)
+		}
+		if v := config.GetInt("string", -1); v != -1 {
+			t.Errorf("%s: Expected fallback for a string, received %d", config.Name, v)
+		}
+		if v := config.GetInt("missing", -1); v != -1 {
+			t.Errorf("%s: Expected fallback for a missing key, received %d", config.Name, v)
+		}
+		if v := config.GetIntSlice("ints", nil); !reflect.DeepEqual(v, []int64{1, 2, 3}) {
+			t.Errorf("%s: Expected [1 2 3], received %v", config.Name, v)
+		}
+		if v := config.GetIntSlice("mixed", []int64{}); len(v) != 0 {
+			t.Errorf("%s: Expected fallback for a slice with a non-integer, received %v", config.Name, v)
+		}
+	}
+
+	if v := numberConfig.GetInt("large", 0); v != 9007199254740993 {
+		t.Errorf("Expected large json.Number integer to be exact, received %d", v)
+	}
+	if v := floatConfig.GetInt("large", 0); v != 9007199254740992 {
+		t.Errorf("Expected large float64 integer to be coerced, received %d", v)
+	}
+}
+
+func TestMatchedRuleAccessors(t *testing.T) {
+	specs, _ := json.Marshal(downloadConfigSpecResponse{
+		HasUpdates: true,
+		Time:       1,
+		DynamicConfigs: []configSpec{
+			{Name: "an_experiment", Type: "dynamic_config", Entity: "experiment", Enabled: true, DefaultValue: json.RawMessage(`{}`), Rules: []configRule{
+				{
+					ID:             "employees",
+					PassPercentage: 100,
+					Conditions:     []configCondition{{Type: "user_field", Field: "email", Operator: "str_contains_any", TargetValue: []interface{}{"@statsig.com"}}},
+					ReturnValue:    json.RawMessage(`{"employee":true}`),
+				},
+				{
+					ID:             "rollout",
+					PassPercentage: 25,
+					Conditions:     []configCondition{{Type: "user_field", Field: "country", Operator: "any", TargetValue: []interface{}{"US"}}},
+					ReturnValue:    json.RawMessage(`{"employee":false}`),
+				},
+			}},
+		},
+	})
+	c := newLocalModeClientForTest(t, specs, nil)
+	defer c.Shutdown()
+
+	employee := c.GetExperiment(User{UserID: "a-user", Email: "a@statsig.com"}, "an_experiment")
+	if employee.MatchedRuleID() != "employees" || employee.PassPercentage() != 100 {
+		t.Errorf("Expected the employees rule at 100%%, received %s at %v", employee.MatchedRuleID(), employee.PassPercentage())
+	}
+	rollout := c.GetExperiment(User{UserID: "a-user", Country: "US"}, "an_experiment")
+	if rollout.MatchedRuleID() != "rollout" || rollout.PassPercentage() != 25 {
+		t.Errorf("Expected the rollout rule at 25%%, received %s at %v", rollout.MatchedRuleID(), rollout.PassPercentage())
+	}
+	unmatched := c.GetExperiment(User{UserID: "a-user", Country: "CA"}, "an_experiment")
+	if unmatched.MatchedRuleID() != "" || unmatched.PassPercentage() != 0 || unmatched.RuleID != "default" {
+		t.Errorf("Expected no matched rule for the default value, received %s at %v", unmatched.MatchedRuleID(), unmatched.PassPercentage())
+	}
+}
+
+func TestIsPublicPass(t *testing.T) {
+	specs, _ := os.ReadFile("download_config_specs.json")
+	c := newLocalModeClientForTest(t, specs, nil)
+	defer c.Shutdown()
+	user := User{UserID: "123", Email: "testuser@statsig.com"}
+
+	public := c.GetGate(user, "always_on_gate")
+	if !public.Value || !public.IsPublicPass() {
+		t.Errorf("Expected always_on_gate to be a public pass, received %+v", public)
+	}
+	targeted := c.GetGate(user, "on_for_statsig_email")
+	if !targeted.Value || targeted.IsPublicPass() {
+		t.Errorf("Expected on_for_statsig_email to pass through targeting, received %+v", targeted)
+	}
+	failed := c.GetGate(User{UserID: "123"}, "on_for_statsig_email")
+	if failed.Value || failed.IsPublicPass() {
+		t.Error("Expected a failing gate not to be a public pass")
+	}
+	c.OverrideGate("always_on_gate", true)
+	if overridden := c.GetGate(user, "always_on_gate"); overridden.IsPublicPass() {
+		t.Error("Expected an overridden gate not to be a public pass")
+	}
+}
+
+func TestEntityType(t *testing.T) {
+	specs, _ := json.Marshal(downloadConfigSpecResponse{
+		HasUpdates: true,
+		Time:       1,
+		DynamicConfigs: []configSpec{
+			{Name: "an_experiment", Type: "dynamic_config", Entity: "experiment", Enabled: true, DefaultValue: json.RawMessage(`{}`)},
+			{Name: "a_config", Type: "dynamic_config", Entity: "dynamic_config", Enabled: true, DefaultValue: json.RawMessage(`{}`)},
+			{Name: "a_legacy_config", Type: "dynamic_config", Enabled: true, DefaultValue: json.RawMessage(`{}`)},
+		},
+	})
+	c := newLocalModeClientForTest(t, specs, nil)
+	defer c.Shutdown()
+	user := User{UserID: "a-user"}
+
+	expected := map[string]ConfigType{
+		"an_experiment":   ExperimentType,
+		"a_config":        DynamicConfigType,
+		"a_legacy_config": DynamicConfigType,
+		"missing_config":  "",
+	}
+	for name, entityType := range expected {
+		config := c.GetConfig(user, name)
+		if config.EntityType() != entityType {
+			t.Errorf("Expected %s to be a %q, received %q", name, entityType, config.EntityType())
+		}
+	}
+}
+
+func TestGetStringInterpolated(t *testing.T) {
+	specs, _ := json.Marshal(downloadConfigSpecResponse{
+		HasUpdates: true,
+		Time:       1,
+		DynamicConfigs: []configSpec{
+			{
+				Name:         "endpoints",
+				Type:         "dynamic_config",
+				Enabled:      true,
+				DefaultValue: json.RawMessage(`{"api": "https://${region}.example.com/${tier}/${missing}", "port": 443}`),
+			},
+		},
+	})
+	user := User{
+		UserID:             "a-user",
+		StatsigEnvironment: map[string]string{"tier": "staging"},
+		Custom:             map[string]interface{}{"region": "eu-west", "tier": "ignored"},
+	}
+
+	c := newLocalModeClientForTest(t, specs, nil)
+	config := c.GetConfig(user, "endpoints")
+	c.Shutdown()
+
+	expected := "https://eu-west.example.com/staging/${missing}"
+	if value := config.GetStringInterpolated("api", "", user); value != expected {
+		t.Errorf("Expected %s, received %s", expected, value)
+	}
+	if value := config.GetStringInterpolated("port", "fallback", user); value != "fallback" {
+		t.Errorf("Expected the fallback for a non string value, received %s", value)
+	}
+
+	c = newLocalModeClientForTest(t, specs, &Options{
+		ClearUnresolvedPlaceholders: true,
+	})
+	defer c.Shutdown()
+	config = c.GetConfig(user, "endpoints")
+	expected = "https://eu-west.example.com/staging/"
+	if value := config.GetStringInterpolated("api", "", user); value != expected {
+		t.Errorf("Expected unresolved placeholders to be removed, received %s", value)
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+	type banner struct {
+		Text  string  `json:"text"`
+		Width float64 `json:"width"`
+	}
+	type checkout struct {
+		Title   string   `json:"title"`
+		Retries int      `json:"max_retries"`
+		Tags    []string `json:"tags"`
+		Banners []banner `json:"banners"`
+		Limits  struct {
+			Daily int `json:"daily"`
+		} `json:"limits"`
+	}
+	value := map[string]interface{}{
+		"title":       "Checkout",
+		"max_retries": float64(3),
+		"tags":        []interface{}{"a", "b"},
+		"banners":     []interface{}{map[string]interface{}{"text": "Sale", "width": 1.5}},
+		"limits":      map[string]interface{}{"daily": float64(10)},
+	}
+
+	config := NewConfig("checkout", value, "rule_id", "", nil)
+	var decoded checkout
+	if err := config.Unmarshal(&decoded); err != nil {
+		t.Fatalf("Expected the config to unmarshal, received %s", err)
+	}
+	if decoded.Title != "Checkout" || decoded.Retries != 3 || !reflect.DeepEqual(decoded.Tags, []string{"a", "b"}) ||
+		!reflect.DeepEqual(decoded.Banners, []banner{{Text: "Sale", Width: 1.5}}) || decoded.Limits.Daily != 10 {
+		t.Errorf("Expected nested values to be decoded by their json tags, received %+v", decoded)
+	}
+
+	var mismatched struct {
+		Title int `json:"title"`
+	}
+	if err := config.Unmarshal(&mismatched); err == nil {
+		t.Error("Expected an error when the value types do not match")
+	}
+	empty := NewConfig("empty", nil, "default", "", nil)
+	if err := empty.Unmarshal(&decoded); !errors.Is(err, ErrEmptyConfig) {
+		t.Errorf("Expected ErrEmptyConfig for an empty config, received %v", err)
+	}
+
+	exposed := make(map[string]bool)
+	logExposure := func(layer Layer, parameter string) { exposed[parameter] = true }
+	layer := NewLayer("checkout_layer", value, "rule_id", "", &logExposure, "")
+	decoded = checkout{}
+	if err := layer.Unmarshal(&decoded); err != nil || decoded.Title != "Checkout" {
+		t.Errorf("Expected the layer to unmarshal, received %+v %v", decoded, err)
+	}
+	if len(exposed) != len(value) {
+		t.Errorf("Expected an exposure for every layer parameter, received %v", exposed)
+	}
+}
+
+func TestSamplingRate(t *testing.T) {
+	rate := 101
+	sampledRule := func(id string, samplingRate *int) configRule {
+		rule := publicRule(id)
+		rule.ReturnValue = json.RawMessage(`{"a": 1}`)
+		rule.SamplingRate = samplingRate
+		return rule
+	}
+	specs, _ := json.Marshal(downloadConfigSpecResponse{
+		HasUpdates: true,
+		Time:       1,
+		FeatureGates: []configSpec{
+			{Name: "sampled_gate", Type: "feature_gate", Enabled: true, Rules: []configRule{sampledRule("gate_rule", &rate)}},
+		},
+		DynamicConfigs: []configSpec{
+			{Name: "sampled_config", Type: "dynamic_config", Enabled: true, DefaultValue: json.RawMessage(`{}`), Rules: []configRule{sampledRule("config_rule", &rate)}},
+			{Name: "unsampled_config", Type: "dynamic_config", Enabled: true, DefaultValue: json.RawMessage(`{}`), Rules: []configRule{sampledRule("plain_rule", nil)}},
+		},
+		LayerConfigs: []configSpec{
+			{Name: "sampled_layer", Type: "dynamic_config", Enabled: true, DefaultValue: json.RawMessage(`{}`), Rules: []configRule{sampledRule("layer_rule", &rate)}},
+		},
+	})
+	c := newLocalModeClientForTest(t, specs, nil)
+	defer c.Shutdown()
+	user := User{UserID: "a-user"}
+
+	gate := c.GetGate(user, "sampled_gate")
+	if received := gate.SamplingRate(); received == nil || *received != rate {
+		t.Errorf("Expected the gate sampling rate to be %d, received %v", rate, received)
+	}
+	config := c.GetConfig(user, "sampled_config")
+	if received := config.SamplingRate(); received == nil || *received != rate {
+		t.Errorf("Expected the config sampling rate to be %d, received %v", rate, received)
+	}
+	layer := c.GetLayer(user, "sampled_layer")
+	if received := layer.SamplingRate(); received == nil || *received != rate {
+		t.Errorf("Expected the layer sampling rate to be %d, received %v", rate, received)
+	}
+	unsampled := c.GetConfig(user, "unsampled_config")
+	if received := unsampled.SamplingRate(); received != nil {
+		t.Errorf("Expected no sampling rate for a rule without one, received %d", *received)
+	}
+	missing := c.GetGate(user, "missing_gate")
+	if received := missing.SamplingRate(); received != nil {
+		t.Errorf("Expected no sampling rate for an unrecognized gate, received %d", *received)
+	}
+}
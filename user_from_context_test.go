@@ -0,0 +1,58 @@
+package statsig
+
+import (
+	"context"
+	"testing"
+)
+
+type userIDContextKey struct{}
+
+func TestUserFromContext(t *testing.T) {
+	specs := `{
+		"has_updates": true,
+		"time": 1,
+		"feature_gates": [{
+			"name": "ctx_gate",
+			"type": "feature_gate",
+			"salt": "ctx_gate",
+			"enabled": true,
+			"defaultValue": false,
+			"idType": "userID",
+			"rules": [{
+				"name": "ctx_rule",
+				"id": "ctx_rule",
+				"salt": "ctx_rule",
+				"passPercentage": 100,
+				"idType": "userID",
+				"conditions": [{"type": "user_field", "operator": "any", "field": "userID", "targetValue": ["ctx-user"]}]
+			}]
+		}],
+		"dynamic_configs": [],
+		"layer_configs": []
+	}`
+	c := newLocalModeClientForTest(t, []byte(specs), &Options{
+		UserFromContext: func(ctx context.Context) User {
+			userID, _ := ctx.Value(userIDContextKey{}).(string)
+			return User{UserID: userID, Email: "ctx@statsig.com"}
+		},
+	})
+	defer c.Shutdown()
+	ctx := context.WithValue(context.Background(), userIDContextKey{}, "ctx-user")
+
+	if !c.CheckGateWithContext(ctx, User{}, "ctx_gate") {
+		t.Error("Expected gate to pass for the user ID derived from context")
+	}
+
+	if c.CheckGateWithContext(ctx, User{UserID: "explicit-user"}, "ctx_gate") {
+		t.Error("Expected explicitly passed user ID to take precedence over context")
+	}
+
+	if c.CheckGateWithContext(context.Background(), User{}, "ctx_gate") {
+		t.Error("Expected gate to fail when context carries no user ID")
+	}
+
+	gate := c.GetGateWithContext(ctx, User{}, "ctx_gate")
+	if !gate.Value || gate.RuleID != "ctx_rule" {
+		t.Errorf("Expected GetGateWithContext to pass with rule ctx_rule, received %+v", gate)
+	}
+}
@@ -1,9 +1,11 @@
 package statsig
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"testing"
+	"time"
 )
 
 func TestUserPersistentStorage(t *testing.T) {
@@ -219,3 +221,121 @@ func TestInvalidUserPersistentStorage(t *testing.T) {
 		t.Errorf("Expected not to panic")
 	}
 }
+
+func TestUserPersistedValuesCache(t *testing.T) {
+	persistentStorage := &userPersistentStorageExample{store: make(map[string]UserPersistedValues)}
+	bytes, _ := os.ReadFile("download_config_specs_sticky_experiments.json")
+	c := newLocalModeClientForTest(t, bytes, &Options{
+		UserPersistentStorage:       persistentStorage,
+		UserPersistedValuesCacheTTL: time.Minute,
+	})
+	defer c.Shutdown()
+	user := User{UserID: "vj"}
+	expName := "the_allocated_experiment"
+
+	persistedValues := c.GetUserPersistedValues(user, "userID")
+	c.GetUserPersistedValues(user, "userID")
+	if persistentStorage.loadCalled != 1 {
+		t.Errorf("Expected a second load within the TTL to be served from memory, Load called %d times", persistentStorage.loadCalled)
+	}
+
+	c.GetExperimentWithOptions(user, expName, &GetExperimentOptions{PersistedValues: persistedValues})
+	if persistentStorage.saveCalled != 1 {
+		t.Fatalf("Expected the sticky assignment to be saved, Save called %d times", persistentStorage.saveCalled)
+	}
+	persistedValues = c.GetUserPersistedValues(user, "userID")
+	if persistentStorage.loadCalled != 2 {
+		t.Errorf("Expected a save to invalidate the cached values, Load called %d times", persistentStorage.loadCalled)
+	}
+	if _, ok := persistedValues[expName]; !ok {
+		t.Errorf("Expected the saved sticky values for %s after invalidation", expName)
+	}
+
+	delete(persistedValues, expName)
+	if _, ok := c.GetUserPersistedValues(user, "userID")[expName]; !ok {
+		t.Errorf("Expected changes to returned values not to alter the cached values for %s", expName)
+	}
+}
+
+func TestPersistedValuesWithoutStorage(t *testing.T) {
+	bytes, _ := os.ReadFile("download_config_specs_sticky_experiments.json")
+	c := newLocalModeClientForTest(t, bytes, nil)
+	defer c.Shutdown()
+	// Sits behind the missing storage so any save or delete the evaluator still attempts is counted
+	storage := &userPersistentStorageExample{store: make(map[string]UserPersistedValues)}
+	c.evaluator.persistentStorageUtils.storage = storage
+	userInControl := User{UserID: "vj"}
+	expName := "the_allocated_experiment"
+
+	persistedValues := UserPersistedValues{
+		expName: StickyValues{Value: true, RuleID: "2B3nzQ8DTDCxlSf0YOaTan", GroupName: "Test", Time: 1},
+	}
+	exp := c.GetExperimentWithOptions(userInControl, expName, &GetExperimentOptions{PersistedValues: persistedValues})
+	if exp.GroupName != "Test" {
+		t.Errorf("Expected the persisted Test group to be honored without storage. Received: %s", exp.GroupName)
+	}
+	if exp.EvaluationDetails == nil || exp.EvaluationDetails.Reason != ReasonPersisted {
+		t.Errorf("Expected reason %s, received %+v", ReasonPersisted, exp.EvaluationDetails)
+	}
+
+	emptyValues := UserPersistedValues{}
+	exp = c.GetExperimentWithOptions(userInControl, expName, &GetExperimentOptions{PersistedValues: emptyValues})
+	if exp.GroupName != "Control" {
+		t.Errorf("Expected a fresh evaluation without persisted values. Received: %s", exp.GroupName)
+	}
+	if storage.saveCalled != 0 || storage.deleteCalled != 0 || len(emptyValues) != 0 {
+		t.Errorf("Expected no save or delete without storage, received %d saves and %d deletes", storage.saveCalled, storage.deleteCalled)
+	}
+}
+
+func TestPersistentStorageSkippedOnCanceledContext(t *testing.T) {
+	bytes, _ := os.ReadFile("download_config_specs_sticky_experiments.json")
+	storage := &blockingPersistentStorage{release: make(chan struct{})}
+	defer close(storage.release)
+	c := newLocalModeClientForTest(t, bytes, &Options{
+		UserPersistentStorage: storage,
+	})
+	defer c.Shutdown()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	user := User{UserID: "vj"}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if values := c.GetUserPersistedValuesWithContext(ctx, user, "userID"); len(values) != 0 {
+			t.Errorf("Expected no persisted values once the context is done, received %v", values)
+		}
+		c.GetExperimentWithContext(ctx, user, "the_allocated_experiment")
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Expected a canceled context not to wait on persistent storage")
+	}
+	if calls := getCounter(&storage.calls); calls != 0 {
+		t.Errorf("Expected persistent storage not to be called once the context is done, received %d calls", calls)
+	}
+}
+
+// Blocks every call until release is closed
+type blockingPersistentStorage struct {
+	release chan struct{}
+	calls   int32
+}
+
+func (s *blockingPersistentStorage) Load(key string) (UserPersistedValues, bool) {
+	incrementCounter(&s.calls)
+	<-s.release
+	return nil, false
+}
+
+func (s *blockingPersistentStorage) Save(key string, configName string, value StickyValues) {
+	incrementCounter(&s.calls)
+	<-s.release
+}
+
+func (s *blockingPersistentStorage) Delete(key string, configName string) {
+	incrementCounter(&s.calls)
+	<-s.release
+}
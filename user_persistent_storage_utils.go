@@ -1,25 +1,51 @@
 package statsig
 
 import (
+	"context"
 	"fmt"
+	"sync"
+	"time"
 )
 
+const maxCachedPersistedValues = 10000
+
 type userPersistentStorageUtils struct {
-	storage IUserPersistentStorage
+	storage  IUserPersistentStorage
+	readOnly bool
+	cacheTTL time.Duration
+	cache    map[string]cachedPersistedValues
+	mu       sync.Mutex
+}
+
+type cachedPersistedValues struct {
+	values    UserPersistedValues
+	expiresAt time.Time
 }
 
 func newUserPersistentStorageUtils(options *Options) *userPersistentStorageUtils {
 	return &userPersistentStorageUtils{
-		storage: options.UserPersistentStorage,
+		storage:  options.UserPersistentStorage,
+		readOnly: options.UserPersistentStorage == nil || options.ReadOnly,
+		cacheTTL: options.UserPersistedValuesCacheTTL,
+		cache:    make(map[string]cachedPersistedValues),
 	}
 }
 
-func (p *userPersistentStorageUtils) load(user User, idType string) UserPersistedValues {
-	if p.storage == nil {
+// Without a storage backend, or on a ReadOnly client, persisted values are still honored but never saved or deleted
+func (p *userPersistentStorageUtils) isReadOnly() bool {
+	return p.readOnly
+}
+
+// Returns nil without touching storage once ctx is done
+func (p *userPersistentStorageUtils) load(ctx context.Context, user User, idType string) UserPersistedValues {
+	if p.storage == nil || (ctx != nil && ctx.Err() != nil) {
 		return nil
 	}
 
 	key := getStorageKey(user, idType)
+	if values, ok := p.getCached(key); ok {
+		return values
+	}
 
 	logError := func(err error) {
 		Logger().LogError(fmt.Sprintf("Failed to load key (%s) from UserPersistentStorage (%s)\n", key, err.Error()))
@@ -33,10 +59,75 @@ func (p *userPersistentStorageUtils) load(user User, idType string) UserPersiste
 
 	storedValues, exists := p.storage.Load(key)
 	if !exists {
+		storedValues = nil
+	}
+	p.setCached(key, storedValues)
+	return storedValues
+}
+
+// Serves repeated loads of a key within Options.UserPersistedValuesCacheTTL from memory, misses included
+func (p *userPersistentStorageUtils) getCached(key string) (UserPersistedValues, bool) {
+	if p.cacheTTL <= 0 {
+		return nil, false
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	entry, ok := p.cache[key]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil, false
+	}
+	return copyPersistedValues(entry.values), true
+}
+
+func (p *userPersistentStorageUtils) setCached(key string, values UserPersistedValues) {
+	if p.cacheTTL <= 0 {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := time.Now()
+	if len(p.cache) >= maxCachedPersistedValues {
+		for k, entry := range p.cache {
+			if now.After(entry.expiresAt) {
+				delete(p.cache, k)
+			}
+		}
+		if len(p.cache) >= maxCachedPersistedValues {
+			p.cache = make(map[string]cachedPersistedValues)
+		}
+	}
+	p.cache[key] = cachedPersistedValues{values: copyPersistedValues(values), expiresAt: now.Add(p.cacheTTL)}
+}
+
+// The cache keeps its own copy and hands out copies, so callers changing the returned values can't alter later loads
+func copyPersistedValues(values UserPersistedValues) UserPersistedValues {
+	if values == nil {
 		return nil
 	}
+	copied := make(UserPersistedValues, len(values))
+	for name, sticky := range values {
+		if sticky.JsonValue != nil {
+			jsonValue := make(map[string]interface{}, len(sticky.JsonValue))
+			for k, v := range sticky.JsonValue {
+				jsonValue[k] = v
+			}
+			sticky.JsonValue = jsonValue
+		}
+		sticky.SecondaryExposures = append([]SecondaryExposure(nil), sticky.SecondaryExposures...)
+		sticky.UndelegatedSecondaryExposures = append([]SecondaryExposure(nil), sticky.UndelegatedSecondaryExposures...)
+		sticky.ExplicitParameters = append([]string(nil), sticky.ExplicitParameters...)
+		copied[name] = sticky
+	}
+	return copied
+}
 
-	return storedValues
+func (p *userPersistentStorageUtils) invalidateCached(key string) {
+	if p.cacheTTL <= 0 {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.cache, key)
 }
 
 func (p *userPersistentStorageUtils) save(user User, idType string, configName string, evaluation *evalResult) {
@@ -45,6 +136,7 @@ func (p *userPersistentStorageUtils) save(user User, idType string, configName s
 	}
 
 	key := getStorageKey(user, idType)
+	defer p.invalidateCached(key)
 
 	logError := func(err error) {
 		Logger().LogError(fmt.Sprintf("Failed to save key (%s) to UserPersistentStorage (%s)\n", key, err.Error()))
@@ -65,6 +157,7 @@ func (p *userPersistentStorageUtils) delete(user User, idType string, configName
 	}
 
 	key := getStorageKey(user, idType)
+	defer p.invalidateCached(key)
 
 	logError := func(err error) {
 		Logger().LogError(fmt.Sprintf("Failed to save key (%s) to UserPersistentStorage (%s)\n", key, err.Error()))
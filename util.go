@@ -6,7 +6,10 @@ import (
 	"encoding/binary"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"sort"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 )
@@ -45,6 +48,11 @@ func getHashBase64StringEncoding(configName string) string {
 	return base64.StdEncoding.EncodeToString(hash)
 }
 
+// Key of an ID in the ID lists generated by the server: the first 8 characters of its base64 encoded sha256
+func getIDListKey(id string) string {
+	return getHashBase64StringEncoding(id)[:8]
+}
+
 func safeGetFirst(slice []string) string {
 	if len(slice) > 0 {
 		return slice[0]
@@ -52,6 +60,14 @@ func safeGetFirst(slice []string) string {
 	return ""
 }
 
+func toStringSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, value := range values {
+		set[value] = true
+	}
+	return set
+}
+
 func safeParseJSONint64(val interface{}) int64 {
 	if num, ok := val.(json.Number); ok {
 		i64, _ := strconv.ParseInt(string(num), 10, 64)
@@ -126,3 +142,28 @@ func hashName(hashAlgorithm string, name string) string {
 		return name
 	}
 }
+
+// Hashes a gate, config or layer name the way client initialize responses do with "none", "djb2" or "sha256".
+// Any other algorithm returns the name unchanged. Lets client side bootstrapping be tested for parity
+func HashName(algorithm string, name string) string {
+	return hashName(algorithm, name)
+}
+
+// Checks that hashing each name with the given algorithm ("none", "djb2" or "sha256") produces the expected hash,
+// e.g. hashes taken from a client SDK that cannot find gates in a bootstrap payload
+func VerifyHashCompatibility(algorithm string, cases map[string]string) error {
+	if algorithm != "none" && algorithm != "djb2" && algorithm != "sha256" {
+		return fmt.Errorf("unsupported hash algorithm %q", algorithm)
+	}
+	mismatches := make([]string, 0)
+	for name, expected := range cases {
+		if actual := hashName(algorithm, name); actual != expected {
+			mismatches = append(mismatches, fmt.Sprintf("%s: expected %s, got %s", name, expected, actual))
+		}
+	}
+	if len(mismatches) > 0 {
+		sort.Strings(mismatches)
+		return fmt.Errorf("%s hash mismatch for %d name(s): %s", algorithm, len(mismatches), strings.Join(mismatches, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package statsig
+
+import (
+	"fmt"
+	"strings"
+)
+
+func getRuleName(rule configRule) string {
+	if rule.Name != "" {
+		return rule.Name
+	}
+	return rule.ID
+}
+
+func describeConditionFailure(cond configCondition, value interface{}) string {
+	switch {
+	case strings.EqualFold(cond.Type, "pass_gate"):
+		return fmt.Sprintf("gate '%v' did not pass", cond.TargetValue)
+	case strings.EqualFold(cond.Type, "fail_gate"):
+		return fmt.Sprintf("gate '%v' passed", cond.TargetValue)
+	}
+	field := cond.Field
+	if field == "" {
+		field = cond.Type
+	}
+	return fmt.Sprintf("%s=%s %s %s", field, formatConditionValue(value), describeFailedOperator(cond.Operator), formatConditionValue(cond.TargetValue))
+}
+
+func describeFailedOperator(op string) string {
+	switch strings.ToLower(op) {
+	case "any", "any_case_sensitive":
+		return "not in"
+	case "none", "none_case_sensitive":
+		return "in"
+	case "eq":
+		return "!="
+	case "neq":
+		return "=="
+	case "gt", "version_gt":
+		return "not >"
+	case "gte", "version_gte":
+		return "not >="
+	case "lt", "version_lt":
+		return "not <"
+	case "lte", "version_lte":
+		return "not <="
+	case "in_segment_list":
+		return "not in segment"
+	case "not_in_segment_list":
+		return "in segment"
+	case "is_null":
+		return "is not"
+	case "is_not_null":
+		return "is"
+	}
+	return "failed " + op
+}
+
+func formatConditionValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "'" + v + "'"
+	case []interface{}:
+		values := make([]string, len(v))
+		for i, item := range v {
+			values[i] = fmt.Sprintf("%v", item)
+		}
+		return "[" + strings.Join(values, ", ") + "]"
+	}
+	return fmt.Sprintf("%v", value)
+}
@@ -0,0 +1,88 @@
+package statsig
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestWhyNot(t *testing.T) {
+	specs, _ := json.Marshal(downloadConfigSpecResponse{
+		HasUpdates: true,
+		Time:       1,
+		FeatureGates: []configSpec{
+			{Name: "us_employees", Type: "feature_gate", Enabled: true, Rules: []configRule{{
+				Name:           "employees",
+				ID:             "employees_rule",
+				PassPercentage: 100,
+				Conditions: []configCondition{
+					{Type: "user_field", Field: "country", Operator: "any", TargetValue: []interface{}{"US"}},
+				},
+			}}},
+			{Name: "disabled_gate", Type: "feature_gate", Enabled: false},
+		},
+	})
+
+	exposures := 0
+	c := newLocalModeClientForTest(t, specs, &Options{
+		EvaluationCallbacks: EvaluationCallbacks{
+			GateEvaluationCallback: func(name string, result bool, exposure *ExposureEvent) {
+				exposures++
+			},
+		},
+	})
+	defer c.Shutdown()
+
+	reasons := c.WhyNot(User{UserID: "a-user", Country: "CA"}, "us_employees")
+	expected := "rule 'employees' failed: country='CA' not in [US]"
+	if len(reasons) != 1 || reasons[0] != expected {
+		t.Errorf("Expected reasons [%s], received %v", expected, reasons)
+	}
+
+	if reasons := c.WhyNot(User{UserID: "a-user", Country: "US"}, "us_employees"); len(reasons) != 0 {
+		t.Errorf("Expected no reasons for a passing user, received %v", reasons)
+	}
+	if reasons := c.WhyNot(User{UserID: "a-user"}, "disabled_gate"); len(reasons) != 1 || reasons[0] != "gate disabled" {
+		t.Errorf("Expected the disabled gate to be reported, received %v", reasons)
+	}
+	c.logger.mu.Lock()
+	buffered := len(c.logger.events)
+	c.logger.mu.Unlock()
+	if exposures != 0 || buffered != 0 {
+		t.Error("Expected WhyNot not to log exposures")
+	}
+}
+
+func TestCompareUsers(t *testing.T) {
+	specs, _ := json.Marshal(downloadConfigSpecResponse{
+		HasUpdates: true,
+		Time:       1,
+		FeatureGates: []configSpec{
+			{Name: "eu_launch", Type: "feature_gate", Enabled: true, Rules: []configRule{{
+				ID:             "eu_rule",
+				PassPercentage: 100,
+				Conditions: []configCondition{
+					{Type: "user_field", Field: "country", Operator: "any", TargetValue: []interface{}{"DE", "FR"}},
+				},
+			}}},
+		},
+	})
+	c := newLocalModeClientForTest(t, specs, nil)
+	defer c.Shutdown()
+
+	user := User{UserID: "a-user", Country: "US"}
+	shadow := user
+	shadow.Country = "DE"
+	realValue, shadowValue := c.CompareUsers(user, shadow, "eu_launch")
+	if realValue || !shadowValue {
+		t.Errorf("Expected the gate to fail for US and pass for DE, received %v and %v", realValue, shadowValue)
+	}
+	if a, b := c.CompareUsers(shadow, shadow, "eu_launch"); !a || !b {
+		t.Error("Expected matching users to get the same value")
+	}
+	c.logger.mu.Lock()
+	buffered := len(c.logger.events)
+	c.logger.mu.Unlock()
+	if buffered != 0 {
+		t.Error("Expected CompareUsers not to log exposures")
+	}
+}